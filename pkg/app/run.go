@@ -729,7 +729,7 @@ func checkForUpdates(av github.AssetVersioner, commandName string) func(io.Write
 // collects data related to a Wasm binary.
 func commandCollectsData(command string) bool {
 	switch command {
-	case "compute build", "compute hash-files", "compute publish", "compute serve":
+	case "compute build", "compute debug", "compute hash-files", "compute publish", "compute serve", "compute test":
 		return true
 	}
 	return false
@@ -757,7 +757,7 @@ func commandRequiresToken(command argparser.Command) bool {
 			return text.IsFastlyID(initCmd.CloneFrom)
 		}
 		return false
-	case "compute build", "compute hash-files", "compute install-tools", "compute metadata", "compute pack", "compute serve", "compute validate":
+	case "compute build", "compute debug", "compute hash-files", "compute install-tools", "compute metadata", "compute pack", "compute serve", "compute test", "compute validate", "log-tail replay":
 		return false
 	}
 	commandName = strings.Split(commandName, " ")[0]
@@ -61,6 +61,7 @@ import (
 	"github.com/fastly/cli/pkg/commands/authtoken"
 	"github.com/fastly/cli/pkg/commands/compute"
 	"github.com/fastly/cli/pkg/commands/compute/computeacl"
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
 	"github.com/fastly/cli/pkg/commands/config"
 	"github.com/fastly/cli/pkg/commands/configstore"
 	"github.com/fastly/cli/pkg/commands/configstoreentry"
@@ -277,6 +278,8 @@ func Define( // nolint:revive // function-length
 	computeACLEntriesList := computeacl.NewListEntriesCommand(computeACLCmdRoot.CmdClause, data)
 	computeBuild := compute.NewBuildCommand(computeCmdRoot.CmdClause, data)
 	computeDeploy := compute.NewDeployCommand(computeCmdRoot.CmdClause, data)
+	computeDeploymentsCmdRoot := deployments.NewRootCommand(computeCmdRoot.CmdClause, data)
+	computeDeploymentsList := deployments.NewListCommand(computeDeploymentsCmdRoot.CmdClause, data)
 	computeHashFiles := compute.NewHashFilesCommand(computeCmdRoot.CmdClause, data, computeBuild)
 	computeInit := compute.NewInitCommand(computeCmdRoot.CmdClause, data)
 	computeInstallTools := compute.NewInstallCommand(computeCmdRoot.CmdClause, data)
@@ -284,10 +287,13 @@ func Define( // nolint:revive // function-length
 	computePack := compute.NewPackCommand(computeCmdRoot.CmdClause, data)
 	computePublish := compute.NewPublishCommand(computeCmdRoot.CmdClause, data, computeBuild, computeDeploy)
 	computeServe := compute.NewServeCommand(computeCmdRoot.CmdClause, data, computeBuild)
+	computeDebug := compute.NewDebugCommand(computeCmdRoot.CmdClause, data, computeServe)
+	computeTest := compute.NewTestCommand(computeCmdRoot.CmdClause, data, computeServe)
 	computeUpdate := compute.NewUpdateCommand(computeCmdRoot.CmdClause, data)
 	computeValidate := compute.NewValidateCommand(computeCmdRoot.CmdClause, data)
 	configCmdRoot := config.NewRootCommand(app, data)
 	configstoreCmdRoot := configstore.NewRootCommand(app, data)
+	configstoreCopy := configstore.NewCopyCommand(configstoreCmdRoot.CmdClause, data)
 	configstoreCreate := configstore.NewCreateCommand(configstoreCmdRoot.CmdClause, data)
 	configstoreDelete := configstore.NewDeleteCommand(configstoreCmdRoot.CmdClause, data)
 	configstoreDescribe := configstore.NewDescribeCommand(configstoreCmdRoot.CmdClause, data)
@@ -298,6 +304,7 @@ func Define( // nolint:revive // function-length
 	configstoreentryCreate := configstoreentry.NewCreateCommand(configstoreentryCmdRoot.CmdClause, data)
 	configstoreentryDelete := configstoreentry.NewDeleteCommand(configstoreentryCmdRoot.CmdClause, data)
 	configstoreentryDescribe := configstoreentry.NewDescribeCommand(configstoreentryCmdRoot.CmdClause, data)
+	configstoreentryImport := configstoreentry.NewImportCommand(configstoreentryCmdRoot.CmdClause, data)
 	configstoreentryList := configstoreentry.NewListCommand(configstoreentryCmdRoot.CmdClause, data)
 	configstoreentryUpdate := configstoreentry.NewUpdateCommand(configstoreentryCmdRoot.CmdClause, data)
 	dashboardCmdRoot := dashboard.NewRootCommand(app, data)
@@ -374,9 +381,12 @@ func Define( // nolint:revive // function-length
 	integrationWebhookRotateSigningKey := integrationWebhook.NewRotateSigningKeyCommand(integrationWebhookRoot.CmdClause, data)
 	ipCmdRoot := ip.NewRootCommand(app, data)
 	kvstoreCmdRoot := kvstore.NewRootCommand(app, data)
+	kvstoreCopy := kvstore.NewCopyCommand(kvstoreCmdRoot.CmdClause, data)
 	kvstoreCreate := kvstore.NewCreateCommand(kvstoreCmdRoot.CmdClause, data)
 	kvstoreDelete := kvstore.NewDeleteCommand(kvstoreCmdRoot.CmdClause, data)
 	kvstoreDescribe := kvstore.NewDescribeCommand(kvstoreCmdRoot.CmdClause, data)
+	kvstoreExport := kvstore.NewExportCommand(kvstoreCmdRoot.CmdClause, data)
+	kvstoreImport := kvstore.NewImportCommand(kvstoreCmdRoot.CmdClause, data)
 	kvstoreList := kvstore.NewListCommand(kvstoreCmdRoot.CmdClause, data)
 	kvstoreentryCmdRoot := kvstoreentry.NewRootCommand(app, data)
 	kvstoreentryCreate := kvstoreentry.NewCreateCommand(kvstoreentryCmdRoot.CmdClause, data)
@@ -384,7 +394,9 @@ func Define( // nolint:revive // function-length
 	kvstoreentryGet := kvstoreentry.NewGetCommand(kvstoreentryCmdRoot.CmdClause, data)
 	kvstoreentryDescribe := kvstoreentry.NewDescribeCommand(kvstoreentryCmdRoot.CmdClause, data)
 	kvstoreentryList := kvstoreentry.NewListCommand(kvstoreentryCmdRoot.CmdClause, data)
+	kvstoreentrySync := kvstoreentry.NewSyncCommand(kvstoreentryCmdRoot.CmdClause, data)
 	logtailCmdRoot := logtail.NewRootCommand(app, data)
+	logtailReplay := logtail.NewReplayCommand(logtailCmdRoot, data)
 	ngwafRoot := ngwaf.NewRootCommand(app, data)
 	ngwafWorkspaceRoot := workspace.NewRootCommand(ngwafRoot.CmdClause, data)
 	ngwafWorkspaceCreate := workspace.NewCreateCommand(ngwafWorkspaceRoot.CmdClause, data)
@@ -569,6 +581,7 @@ func Define( // nolint:revive // function-length
 		}
 	}
 	secretstoreCmdRoot := secretstore.NewRootCommand(app, data)
+	secretstoreCopy := secretstore.NewCopyCommand(secretstoreCmdRoot.CmdClause, data)
 	secretstoreCreate := secretstore.NewCreateCommand(secretstoreCmdRoot.CmdClause, data)
 	secretstoreDescribe := secretstore.NewDescribeCommand(secretstoreCmdRoot.CmdClause, data)
 	secretstoreDelete := secretstore.NewDeleteCommand(secretstoreCmdRoot.CmdClause, data)
@@ -582,6 +595,10 @@ func Define( // nolint:revive // function-length
 	serviceCreate := service.NewCreateCommand(serviceCmdRoot.CmdClause, data)
 	serviceDelete := service.NewDeleteCommand(serviceCmdRoot.CmdClause, data)
 	serviceDescribe := service.NewDescribeCommand(serviceCmdRoot.CmdClause, data)
+	serviceExport := service.NewExportCommand(serviceCmdRoot.CmdClause, data)
+	serviceImport := service.NewImportCommand(serviceCmdRoot.CmdClause, data)
+	servicePlan := service.NewPlanCommand(serviceCmdRoot.CmdClause, data)
+	serviceApply := service.NewApplyCommand(serviceCmdRoot.CmdClause, data)
 	serviceList := service.NewListCommand(serviceCmdRoot.CmdClause, data)
 	serviceSearch := service.NewSearchCommand(serviceCmdRoot.CmdClause, data)
 	serviceUpdate := service.NewUpdateCommand(serviceCmdRoot.CmdClause, data)
@@ -805,6 +822,7 @@ func Define( // nolint:revive // function-length
 	serviceVersionActivate := serviceversion.NewActivateCommand(serviceVersionCmdRoot.CmdClause, data)
 	serviceVersionClone := serviceversion.NewCloneCommand(serviceVersionCmdRoot.CmdClause, data)
 	serviceVersionDeactivate := serviceversion.NewDeactivateCommand(serviceVersionCmdRoot.CmdClause, data)
+	serviceVersionDiff := serviceversion.NewDiffCommand(serviceVersionCmdRoot.CmdClause, data)
 	serviceVersionList := serviceversion.NewListCommand(serviceVersionCmdRoot.CmdClause, data)
 	serviceVersionLock := serviceversion.NewLockCommand(serviceVersionCmdRoot.CmdClause, data)
 	serviceVersionStage := serviceversion.NewStageCommand(serviceVersionCmdRoot.CmdClause, data)
@@ -1218,7 +1236,10 @@ func Define( // nolint:revive // function-length
 		computeACLLookup,
 		computeACLEntriesList,
 		computeBuild,
+		computeDebug,
 		computeDeploy,
+		computeDeploymentsCmdRoot,
+		computeDeploymentsList,
 		computeHashFiles,
 		computeInit,
 		computeInstallTools,
@@ -1226,10 +1247,12 @@ func Define( // nolint:revive // function-length
 		computePack,
 		computePublish,
 		computeServe,
+		computeTest,
 		computeUpdate,
 		computeValidate,
 		configCmdRoot,
 		configstoreCmdRoot,
+		configstoreCopy,
 		configstoreCreate,
 		configstoreDelete,
 		configstoreDescribe,
@@ -1240,6 +1263,7 @@ func Define( // nolint:revive // function-length
 		configstoreentryCreate,
 		configstoreentryDelete,
 		configstoreentryDescribe,
+		configstoreentryImport,
 		configstoreentryList,
 		configstoreentryUpdate,
 		dashboardCmdRoot,
@@ -1315,16 +1339,21 @@ func Define( // nolint:revive // function-length
 		integrationWebhookGetSigningKey,
 		integrationWebhookRotateSigningKey,
 		ipCmdRoot,
+		kvstoreCopy,
 		kvstoreCreate,
 		kvstoreDelete,
 		kvstoreDescribe,
+		kvstoreExport,
+		kvstoreImport,
 		kvstoreList,
 		kvstoreentryCreate,
 		kvstoreentryDelete,
 		kvstoreentryGet,
 		kvstoreentryDescribe,
 		kvstoreentryList,
+		kvstoreentrySync,
 		logtailCmdRoot,
+		logtailReplay,
 		serviceloggingDebugCmd,
 		serviceloggingAzureblobCmdRoot,
 		serviceloggingAzureblobCreate,
@@ -1660,6 +1689,7 @@ func Define( // nolint:revive // function-length
 	}...)
 	cmds = append(cmds, profileCommands...)
 	cmds = append(cmds, []argparser.Command{
+		secretstoreCopy,
 		secretstoreCreate,
 		secretstoreDescribe,
 		secretstoreDelete,
@@ -1672,6 +1702,10 @@ func Define( // nolint:revive // function-length
 		serviceCreate,
 		serviceDelete,
 		serviceDescribe,
+		serviceExport,
+		serviceImport,
+		servicePlan,
+		serviceApply,
 		serviceList,
 		serviceSearch,
 		serviceUpdate,
@@ -1770,6 +1804,7 @@ func Define( // nolint:revive // function-length
 		serviceVersionClone,
 		serviceVersionCmdRoot,
 		serviceVersionDeactivate,
+		serviceVersionDiff,
 		serviceVersionList,
 		serviceVersionLock,
 		serviceVersionStage,
@@ -0,0 +1,112 @@
+package compute
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mholt/archives"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/manifest"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/wasm"
+)
+
+// budgetBreakdownEntries is the number of files and sections listed in the
+// size breakdown.
+const budgetBreakdownEntries = 10
+
+// packageEntry is a file in a package archive.
+type packageEntry struct {
+	name string
+	size int64
+}
+
+// checkBudget checks the package against the size budget in the manifest and
+// the platform package size limit. When a limit is exceeded, it prints a
+// breakdown of the largest files in the package and sections of the Wasm
+// binary, and returns an error.
+func checkBudget(out io.Writer, budget manifest.Budget, pkgPath string) error {
+	fi, err := os.Stat(pkgPath)
+	if err != nil {
+		return fmt.Errorf("error reading package size: %w", err)
+	}
+	pkgSize := fi.Size()
+	if budget.MaxPackageSize == 0 && budget.MaxWasmSize == 0 && pkgSize <= MaxPackageSize {
+		return nil
+	}
+
+	var (
+		entries []packageEntry
+		wasmBin []byte
+	)
+	err = packageFiles(pkgPath, func(f archives.FileInfo) error {
+		entries = append(entries, packageEntry{name: f.NameInArchive, size: f.Size()})
+		if filepath.Base(f.NameInArchive) != "main.wasm" {
+			return nil
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", f.NameInArchive, err)
+		}
+		defer rc.Close()
+		wasmBin, err = io.ReadAll(rc)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var exceeded []string
+	if pkgSize > MaxPackageSize {
+		exceeded = append(exceeded, fmt.Sprintf("the package is %s, which exceeds the platform limit (%s)", formatSize(int(pkgSize)), formatSize(int(MaxPackageSize))))
+	}
+	if limit := int64(budget.MaxPackageSize); limit > 0 && pkgSize > limit {
+		exceeded = append(exceeded, fmt.Sprintf("the package is %s, which exceeds max_package_size (%s)", formatSize(int(pkgSize)), formatSize(int(limit))))
+	}
+	if limit := int64(budget.MaxWasmSize); limit > 0 && int64(len(wasmBin)) > limit {
+		exceeded = append(exceeded, fmt.Sprintf("main.wasm is %s, which exceeds max_wasm_size (%s)", formatSize(len(wasmBin)), formatSize(int(limit))))
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	writeSizeBreakdown(out, pkgPath, pkgSize, entries, wasmBin)
+
+	return fsterr.RemediationError{
+		Inner:       fmt.Errorf("the package exceeds the size budget: %s", strings.Join(exceeded, "; ")),
+		Remediation: "Reduce the size of the largest files and Wasm sections listed above (e.g. by building in release mode, stripping debug information or removing unused dependencies), or raise the limits in the [scripts.budget] section of the fastly.toml manifest.",
+	}
+}
+
+// writeSizeBreakdown lists the largest files in the package and the largest
+// sections of the Wasm binary.
+func writeSizeBreakdown(out io.Writer, pkgPath string, pkgSize int64, entries []packageEntry, wasmBin []byte) {
+	text.Break(out)
+	wasmReportHeading(out, "Package %s (%s compressed):", pkgPath, formatSize(int(pkgSize)))
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].size > entries[j].size
+	})
+	t := text.NewTable(out)
+	for _, e := range entries[:min(budgetBreakdownEntries, len(entries))] {
+		t.AddLine("  "+e.name, formatSize(int(e.size)))
+	}
+	t.Print()
+	text.Break(out)
+
+	if wasmBin == nil {
+		return
+	}
+	b, err := wasm.Parse(wasmBin)
+	if err != nil {
+		text.Warning(out, "Unable to parse main.wasm: %s", err)
+		return
+	}
+	wasmReportHeading(out, "Wasm sections (%s):", formatSize(b.Size))
+	writeSectionSizes(out, b, budgetBreakdownEntries)
+	text.Break(out)
+}
@@ -0,0 +1,62 @@
+package compute
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/manifest"
+	"github.com/fastly/cli/pkg/wasm"
+)
+
+func TestCheckBudget(t *testing.T) {
+	// A module with a 2000 byte custom section, so the Wasm binary is larger
+	// than the (compressed) package.
+	custom := append([]byte{0x00, 0xd0, 0x0f, 5}, "debug"...)
+	custom = append(custom, bytes.Repeat([]byte{0}, 2000-6)...)
+	module := append(append([]byte{}, wasm.Magic...), 1, 0, 0, 0)
+	module = append(module, custom...)
+
+	src := filepath.Join(t.TempDir(), "test")
+	if err := os.MkdirAll(filepath.Join(src, "bin"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "bin", "main.wasm"), module, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "fastly.toml"), []byte("name = \"test\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := filepath.Join(t.TempDir(), "test.tar.gz")
+	if err := createTarGz(src, pkgPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := checkBudget(&out, manifest.Budget{}, pkgPath); err != nil {
+		t.Errorf("want no error without a budget, got: %v", err)
+	}
+	if err := checkBudget(&out, manifest.Budget{MaxWasmSize: 4000, MaxPackageSize: 4000}, pkgPath); err != nil {
+		t.Errorf("want no error within the budget, got: %v", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("want no output within the budget, got:\n%s", out.String())
+	}
+
+	err := checkBudget(&out, manifest.Budget{MaxWasmSize: 1000}, pkgPath)
+	if err == nil || !strings.Contains(err.Error(), "main.wasm is 2.0 KB, which exceeds max_wasm_size (1.0 KB)") {
+		t.Fatalf("want an error for the Wasm size, got: %v", err)
+	}
+	for _, want := range []string{"bin/main.wasm", "2.0 KB", "fastly.toml", `custom "debug"`, "99.5%"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want the breakdown to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	err = checkBudget(&out, manifest.Budget{MaxPackageSize: 10}, pkgPath)
+	if err == nil || !strings.Contains(err.Error(), "exceeds max_package_size (10 B)") {
+		t.Errorf("want an error for the package size, got: %v", err)
+	}
+}
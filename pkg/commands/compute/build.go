@@ -48,12 +48,15 @@ var ErrWasmtoolsNotFound = fsterr.RemediationError{
 
 // Flags represents the flags defined for the command.
 type Flags struct {
-	Dir         string
-	Env         string
-	IncludeSrc  bool
-	Lang        string
-	PackageName string
-	Timeout     int
+	Dir          string
+	Env          string
+	IncludeSrc   bool
+	Lang         string
+	NoCache      bool
+	PackageName  string
+	Reproducible bool
+	SignKey      string
+	Timeout      int
 }
 
 // BuildCommand produces a deployable artifact from files on the local disk.
@@ -68,6 +71,8 @@ type BuildCommand struct {
 	MetadataFilterEnvVars string
 	MetadataShow          bool
 	SkipChangeDir         bool // set by parent composite commands (e.g. serve, publish)
+
+	workspace workspaceOpts
 }
 
 // NewBuildCommand returns a usable command registered under the parent.
@@ -85,14 +90,24 @@ func NewBuildCommand(parent argparser.Registerer, g *global.Data) *BuildCommand
 	c.CmdClause.Flag("metadata-disable", "Disable Wasm binary metadata annotations").BoolVar(&c.MetadataDisable)
 	c.CmdClause.Flag("metadata-filter-envvars", "Redact specified environment variables from [scripts.env_vars] using comma-separated list").StringVar(&c.MetadataFilterEnvVars)
 	c.CmdClause.Flag("metadata-show", "Inspect the Wasm binary metadata").BoolVar(&c.MetadataShow)
+	c.CmdClause.Flag("no-cache", "Run the build even if nothing has changed since a package was cached by a previous build").BoolVar(&c.Flags.NoCache)
 	c.CmdClause.Flag("package-name", "Package name").StringVar(&c.Flags.PackageName)
+	c.CmdClause.Flag("reproducible", fmt.Sprintf("Create a byte-identical package for the same files, by normalising file order, modes, ownership and timestamps (set by %s)", SourceDateEpoch)).BoolVar(&c.Flags.Reproducible)
+	c.CmdClause.Flag("sign", "Path to an Ed25519 private key (PEM) used to sign the package and write a provenance attestation next to it").StringVar(&c.Flags.SignKey)
 	c.CmdClause.Flag("timeout", "Timeout, in seconds, for the build compilation step").IntVar(&c.Flags.Timeout)
+	registerWorkspaceFlags(c.CmdClause, &c.workspace)
 
 	return &c
 }
 
 // Exec implements the command interface.
 func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	if c.workspace.enabled {
+		return runWorkspace(c.Globals.Args, "build", c.workspace, out)
+	}
+
+	started := time.Now()
+
 	// We'll restore this at the end to print a final successful build output.
 	originalOut := out
 	if c.Globals.Flags.Quiet {
@@ -198,6 +213,36 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return err
 	}
 
+	dest := filepath.Join("pkg", fmt.Sprintf("%s.tar.gz", pkgName))
+
+	// The build cache is an optimisation, so problems using it never block the
+	// build (they're only reported in verbose mode).
+	var cacheKey string
+	if !c.Flags.NoCache {
+		cacheKey, err = c.buildCacheKey(language, pkgName)
+		if err != nil {
+			if c.Globals.Verbose() {
+				text.Warning(out, "Unable to use the build cache: %s", err)
+			}
+			cacheKey = ""
+		}
+	}
+	if cacheKey != "" {
+		cached, err := restoreBuildCache(c.buildCacheDir(), cacheKey, dest)
+		if err != nil && c.Globals.Verbose() {
+			text.Warning(out, "Unable to restore the package from the build cache: %s", err)
+		}
+		if cached {
+			if err := c.sign(dest, language, manifestFilename, started, out); err != nil {
+				return err
+			}
+			out = originalOut
+			text.Success(out, "\nBuilt package (%s)", dest)
+			text.Info(out, "Nothing has changed since the last build, so the package was restored from the build cache (use --no-cache to rebuild it).")
+			return nil
+		}
+	}
+
 	if err := language.Build(); err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]any{
 			"Language": language.Name,
@@ -237,7 +282,6 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		text.Info(out, "There was an error downloading the wasm-tools (used for binary annotations) but we don't let that block you building your project. For reference here is the error (in case you want to let us know about it): %s\n\n", wasmtoolsErr.Error())
 	}
 
-	dest := filepath.Join("pkg", fmt.Sprintf("%s.tar.gz", pkgName))
 	err = spinner.Process("Creating package archive", func(_ *text.SpinnerWrapper) error {
 		// IMPORTANT: The minimum package requirement is `fastly.toml` and `main.wasm`.
 		//
@@ -298,7 +342,11 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		if err != nil {
 			return err
 		}
-		err = CreatePackageArchive(files, dest)
+		if c.Flags.Reproducible {
+			err = CreateReproduciblePackageArchive(files, dest)
+		} else {
+			err = CreatePackageArchive(files, dest)
+		}
 		if err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]any{
 				"Files":       files,
@@ -312,11 +360,44 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return err
 	}
 
+	if err := checkBudget(out, c.Globals.Manifest.File.Scripts.Budget, dest); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Package path": dest,
+		})
+		return err
+	}
+
+	if cacheKey != "" {
+		if err := storeBuildCache(c.buildCacheDir(), cacheKey, dest); err != nil && c.Globals.Verbose() {
+			text.Warning(out, "Unable to add the package to the build cache: %s", err)
+		}
+	}
+
+	if err := c.sign(dest, language, manifestFilename, started, out); err != nil {
+		return err
+	}
+
 	out = originalOut
 	text.Success(out, "\nBuilt package (%s)", dest)
 	return nil
 }
 
+// sign signs the package and writes its provenance when --sign is set.
+func (c *BuildCommand) sign(dest string, language *Language, manifestFilename string, started time.Time, out io.Writer) error {
+	if c.Flags.SignKey == "" {
+		return nil
+	}
+	if err := c.signPackage(dest, language, manifestFilename, started); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Package path": dest,
+			"Key":          c.Flags.SignKey,
+		})
+		return err
+	}
+	text.Info(out, "Signed the package (%s%s) and wrote its provenance (%s)", dest, signatureSuffix, provenancePath(dest))
+	return nil
+}
+
 // AnnotateWasmBinaryShort annotates the Wasm binary with only the CLI version.
 func (c *BuildCommand) AnnotateWasmBinaryShort(wasmtools string, args []string) error {
 	return c.Globals.ExecuteWasmTools(wasmtools, args, c.Globals)
@@ -339,12 +420,14 @@ func (c *BuildCommand) AnnotateWasmBinaryLong(wasmtools string, args []string, l
 
 	dc := DataCollection{}
 
-	if metadata.BuildInfo == "enable" {
+	// The build and machine data differ between builds of the same files, so
+	// they're left out of reproducible builds.
+	if metadata.BuildInfo == "enable" && !c.Flags.Reproducible {
 		dc.BuildInfo = DataCollectionBuildInfo{
 			MemoryHeapAlloc: bucketMB(bytesToMB(ms.HeapAlloc)) + "MB",
 		}
 	}
-	if metadata.MachineInfo == "enable" {
+	if metadata.MachineInfo == "enable" && !c.Flags.Reproducible {
 		dc.MachineInfo = DataCollectionMachineInfo{
 			Arch:      runtime.GOARCH,
 			CPUs:      runtime.NumCPU(),
@@ -800,7 +883,22 @@ func language(toolchain, manifestFilename string, c *BuildCommand, in io.Reader,
 			Toolchain: NewOther(c, in, manifestFilename, out, spinner),
 		})
 	default:
-		return nil, fmt.Errorf("unsupported language %s", toolchain)
+		plugin, err := lookupLanguagePlugin(toolchain, c.Globals.Manifest.File.Languages)
+		if errors.Is(err, errLanguagePluginNotFound) {
+			return nil, fsterr.RemediationError{
+				Inner:       fmt.Errorf("unsupported language %s", toolchain),
+				Remediation: fmt.Sprintf("Use one of the built-in languages (%s), define the language in a [languages.%s] table of the %s manifest, or install a %s%s language plugin on your PATH.", strings.Join(Languages, ", "), toolchain, manifestFilename, LanguagePluginPrefix, toolchain),
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		language = NewLanguage(&LanguageOptions{
+			Name:            toolchain,
+			SourceDirectory: plugin.SourceDirectory,
+			Plugin:          plugin,
+			Toolchain:       NewPlugin(c, in, manifestFilename, out, spinner, toolchain, plugin),
+		})
 	}
 
 	return language, nil
@@ -833,6 +931,19 @@ func binDir(c *BuildCommand) error {
 // temporary directory to ensure only the specified files are included and not
 // any in the directory which may be ignored.
 func CreatePackageArchive(files []string, destination string) error {
+	return createPackageArchive(files, destination, createTarGz)
+}
+
+// CreateReproduciblePackageArchive packages build artifacts as a Fastly
+// package, like CreatePackageArchive, but the archive is byte-identical for
+// the same files regardless of when or where it's created.
+func CreateReproduciblePackageArchive(files []string, destination string) error {
+	return createPackageArchive(files, destination, createReproducibleTarGz)
+}
+
+// createPackageArchive copies the files to a temporary directory and archives
+// it with archiveDir.
+func createPackageArchive(files []string, destination string, archiveDir func(sourceDir, destFile string) error) error {
 	// Create temporary directory to copy files into.
 	p := make([]byte, 8)
 	n, err := rand.Read(p)
@@ -865,7 +976,7 @@ func CreatePackageArchive(files []string, destination string) error {
 		}
 	}
 
-	return createTarGz(dir, destination)
+	return archiveDir(dir, destination)
 }
 
 // createTarGz creates a .tar.gz archive from a directory.
@@ -0,0 +1,265 @@
+package compute
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fastly/cli/pkg/filesystem"
+	"github.com/fastly/cli/pkg/manifest"
+	"github.com/fastly/cli/pkg/revision"
+)
+
+// buildCacheSkipDirs are directories that aren't part of the source tree.
+// They hold version control data, installed dependencies (which are covered by
+// the hash of the lockfile) or build output: the toolchains write the Wasm
+// binary and intermediate files (e.g. a JavaScript bundle) to bin, which would
+// change the hash on every build.
+var buildCacheSkipDirs = []string{".git", "bin", "node_modules"}
+
+// buildCacheLanguageSkipDirs are directories that a language toolchain writes
+// its build output to, which would change the hash on every build.
+var buildCacheLanguageSkipDirs = map[string][]string{
+	"rust": {"target"},
+}
+
+// buildCacheArtifacts are patterns matching the files written by `compute
+// build` outside of bin, relative to the project directory: the package and,
+// with --sign, its provenance (which records when it was built) and their
+// signatures.
+var buildCacheArtifacts = []string{"pkg/*.tar.gz", "pkg/*.provenance.json", "pkg/*" + signatureSuffix}
+
+// buildCacheEnvPrefixes are the prefixes of environment variables that
+// configure the language toolchains, and so affect the build output.
+var buildCacheEnvPrefixes = []string{"CARGO_", "FASTLY_", "GO", "NODE_", "NPM_CONFIG_", "PYTHON", "RUST", "TINYGO"}
+
+// buildCacheToolchainVersions are the commands reporting the versions of the
+// toolchain for each language.
+var buildCacheToolchainVersions = map[string][][]string{
+	"cpp":        {{"clang++", "--version"}},
+	"go":         {{"go", "version"}, {"tinygo", "version"}},
+	"javascript": {{"node", "--version"}, {"npm", "--version"}},
+	"python":     {{"python3", "--version"}},
+	"rust":       {{"cargo", "--version"}, {"rustc", "--version"}},
+}
+
+// buildCacheKey is everything that affects the output of `compute build`.
+type buildCacheKey struct {
+	CLIVersion string            `json:"cli_version"`
+	Env        map[string]string `json:"env"`
+	// Files are the names of the files in the source tree, as the hash of the
+	// sources only covers their contents.
+	Files                 []string `json:"files"`
+	IncludeSource         bool     `json:"include_source"`
+	Language              string   `json:"language"`
+	MetadataDisable       bool     `json:"metadata_disable"`
+	MetadataFilterEnvVars string   `json:"metadata_filter_env_vars"`
+	PackageName           string   `json:"package_name"`
+	// Plugin is the description of a language plugin, as its commands affect
+	// the build output.
+	Plugin       *manifest.LanguagePlugin `json:"plugin,omitempty"`
+	Reproducible bool                     `json:"reproducible"`
+	Scripts      struct {
+		Build     string   `json:"build"`
+		EnvVars   []string `json:"env_vars"`
+		PostBuild string   `json:"post_build"`
+	} `json:"scripts"`
+	Sources    string            `json:"sources"`
+	Toolchains map[string]string `json:"toolchains"`
+}
+
+// buildCacheDir returns the directory where build artifacts are cached.
+func (c *BuildCommand) buildCacheDir() string {
+	if c.Globals.Env.BuildCacheDir != "" {
+		return c.Globals.Env.BuildCacheDir
+	}
+	return filepath.Join(filepath.Dir(c.Globals.ConfigPath), "build-cache")
+}
+
+// buildCacheKey hashes the inputs to the build. It must be called from the
+// project directory.
+func (c *BuildCommand) buildCacheKey(language *Language, pkgName string) (string, error) {
+	files, err := sourceFiles(".", buildCacheLanguageSkipDirs[language.Name])
+	if err != nil {
+		return "", fmt.Errorf("error listing the source tree: %w", err)
+	}
+	sources, err := hashFileContents(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Clean(name))
+	})
+	if err != nil {
+		return "", fmt.Errorf("error hashing the source tree: %w", err)
+	}
+
+	k := buildCacheKey{
+		CLIVersion:            revision.AppVersion,
+		Env:                   make(map[string]string),
+		Files:                 files,
+		IncludeSource:         c.Flags.IncludeSrc,
+		Language:              language.Name,
+		MetadataDisable:       c.MetadataDisable,
+		MetadataFilterEnvVars: c.MetadataFilterEnvVars,
+		PackageName:           pkgName,
+		Plugin:                language.Plugin,
+		Reproducible:          c.Flags.Reproducible,
+		Sources:               sources,
+		Toolchains:            toolchainVersions(language),
+	}
+	scripts := c.Globals.Manifest.File.Scripts
+	k.Scripts.Build, k.Scripts.EnvVars, k.Scripts.PostBuild = scripts.Build, scripts.EnvVars, scripts.PostBuild
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		for _, prefix := range buildCacheEnvPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				k.Env[name] = value
+				break
+			}
+		}
+	}
+
+	data, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha512.Sum512_256(data)), nil
+}
+
+// toolchainVersions returns the version of each tool in the toolchain for a
+// language. A tool that isn't installed is recorded as "unavailable" (so that
+// installing it invalidates the build cache).
+func toolchainVersions(language *Language) map[string]string {
+	versions := make(map[string]string)
+	if language.Plugin != nil && language.Plugin.Toolchain.Version != "" {
+		name, args := Shell{}.Build(language.Plugin.Toolchain.Version)
+		// gosec flagged this:
+		// G204 (CWE-78): Subprocess launched with function call as argument or cmd arguments
+		// Disabling as the command is defined by the language plugin.
+		// #nosec
+		output, err := exec.Command(name, args...).CombinedOutput()
+		if err != nil {
+			output = []byte("unavailable")
+		}
+		versions[language.Name] = strings.TrimSpace(string(output))
+	}
+	for _, args := range buildCacheToolchainVersions[language.Name] {
+		// gosec flagged this:
+		// G204 (CWE-78): Subprocess launched with variable
+		// Disabling as the commands are fixed.
+		// #nosec
+		output, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			output = []byte("unavailable")
+		}
+		versions[args[0]] = strings.TrimSpace(string(output))
+	}
+	return versions
+}
+
+// sourceFiles returns the files in dir, relative to dir and in sorted order.
+// Files matched by the .fastlyignore file, build artifacts and the contents of
+// buildCacheSkipDirs and skipDirs are excluded.
+func sourceFiles(dir string, skipDirs []string) ([]string, error) {
+	ignored, err := GetIgnoredFiles(filepath.Join(dir, IgnoreFilePath))
+	if err != nil {
+		return nil, err
+	}
+	skipDirs = append(slices.Clone(buildCacheSkipDirs), skipDirs...)
+
+	var files []string
+	err = filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if slices.Contains(skipDirs, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || ignored[name] {
+			return nil
+		}
+		for _, pattern := range buildCacheArtifacts {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return nil
+			}
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// hashFile writes the contents of a file to the hash.
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close() // #nosec G307
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// restoreBuildCache copies the cached Wasm binary and package archive into the
+// project. It reports false when there's nothing cached for the key.
+func restoreBuildCache(cacheDir, key, pkgDest string) (bool, error) {
+	entry := filepath.Join(cacheDir, key)
+	wasm, pkg := filepath.Join(entry, "main.wasm"), filepath.Join(entry, "package.tar.gz")
+	if !filesystem.FileExists(wasm) || !filesystem.FileExists(pkg) {
+		return false, nil
+	}
+	if err := filesystem.CopyFile(wasm, binWasmPath); err != nil {
+		return false, err
+	}
+	if err := filesystem.CopyFile(pkg, pkgDest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// storeBuildCache adds the Wasm binary and package archive to the cache.
+//
+// The entry is written to a temporary directory and renamed into place so that
+// concurrent builds never see a partial entry.
+func storeBuildCache(cacheDir, key, pkgDest string) error {
+	if err := os.MkdirAll(cacheDir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(cacheDir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := filesystem.CopyFile(binWasmPath, filepath.Join(tmp, "main.wasm")); err != nil {
+		return err
+	}
+	if err := filesystem.CopyFile(pkgDest, filepath.Join(tmp, "package.tar.gz")); err != nil {
+		return err
+	}
+
+	entry := filepath.Join(cacheDir, key)
+	if err := os.Rename(tmp, entry); err != nil && !filesystem.FileExists(filepath.Join(entry, "package.tar.gz")) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package compute
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := func(skipDirs ...string) ([]string, string) {
+		t.Helper()
+		files, err := sourceFiles(dir, skipDirs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := hashFileContents(files, func(name string) (io.ReadCloser, error) {
+			return os.Open(filepath.Join(dir, name))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files, h
+	}
+
+	write("fastly.toml", "name = \"test\"\n")
+	write("src/main.rs", "fn main() {}\n")
+	write("pkg/handler/handler.go", "package handler\n")
+	write("target/debug/main", "binary")
+	write("node_modules/dep/index.js", "module.exports = {}\n")
+	files, initial := hash()
+	want := []string{"fastly.toml", "pkg/handler/handler.go", "src/main.rs", "target/debug/main"}
+	if !slices.Equal(files, want) {
+		t.Errorf("want files %v, got %v", want, files)
+	}
+
+	// Build artifacts don't affect the hash.
+	write("bin/main.wasm", "wasm")
+	write("bin/index.js", "bundle")
+	write("pkg/test.tar.gz", "package")
+	if got, h := hash(); !slices.Equal(got, files) || h != initial {
+		t.Errorf("want build artifacts to be excluded, got files %v", got)
+	}
+
+	// A language's build directory can be skipped.
+	if got, _ := hash("target"); slices.Contains(got, "target/debug/main") {
+		t.Errorf("want target to be skipped, got files %v", got)
+	}
+
+	// Sources in the directories holding the artifacts do.
+	write("pkg/handler/handler.go", "package handler // changed\n")
+	if _, h := hash(); h == initial {
+		t.Error("want the hash to change when a file changes")
+	}
+}
+
+func TestBuildCacheKeySigned(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	privPath, _ := writeKeyPair(t, t.TempDir(), "key")
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	for name, content := range map[string]string{
+		"fastly.toml":     "name = \"test\"\n",
+		"src/main.rs":     "fn main() {}\n",
+		"pkg/test.tar.gz": "package",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := func() string {
+		t.Helper()
+		files, err := sourceFiles(".", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := hashFileContents(files, func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	var c BuildCommand
+	c.Flags.SignKey = privPath
+	language := &Language{Name: "test"}
+	initial := hash()
+	for range 2 {
+		// Each signed build writes a provenance file with new timestamps.
+		if err := c.signPackage(filepath.Join("pkg", "test.tar.gz"), language, "fastly.toml", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if got := hash(); got != initial {
+			t.Fatal("want the signature and provenance files to be excluded from the hash")
+		}
+	}
+}
+
+func TestBuildCache(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, cacheDir := t.TempDir(), t.TempDir()
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	dest := filepath.Join("pkg", "test.tar.gz")
+	if cached, err := restoreBuildCache(cacheDir, "abc", dest); err != nil || cached {
+		t.Fatalf("want nothing cached, got cached=%t err=%v", cached, err)
+	}
+
+	for _, d := range []string{"bin", "pkg"} {
+		if err := os.Mkdir(d, 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(binWasmPath, []byte("wasm"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest, []byte("package"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := storeBuildCache(cacheDir, "abc", dest); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(project, "bin")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(project, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	if cached, err := restoreBuildCache(cacheDir, "abc", dest); err != nil || !cached {
+		t.Fatalf("want the package restored, got cached=%t err=%v", cached, err)
+	}
+	for path, want := range map[string]string{binWasmPath: "wasm", dest: "package"} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("want %s to contain %q, got %q", path, want, got)
+		}
+	}
+}
@@ -51,9 +51,18 @@ func TestFlagDivergencePublish(t *testing.T) {
 		}
 	}
 
+	// Some flags on `compute deploy` are unique to it.
+	ignoreDeployFlags := []string{
+		"rollback",
+		"to",
+	}
+
 	iter = deployFlags.MapRange()
 	for iter.Next() {
-		expect[iter.Key().String()] = 1
+		flag := iter.Key().String()
+		if !ignoreFlag(ignoreDeployFlags, flag) {
+			expect[flag] = 1
+		}
 	}
 
 	iter = publishFlags.MapRange()
@@ -86,8 +95,12 @@ func TestFlagDivergenceServe(t *testing.T) {
 	)
 
 	// Some flags on `compute build` are unique to it.
-	// NOTE: There are no flags to ignore but I'm keeping the logic for future.
-	ignoreBuildFlags := []string{}
+	ignoreBuildFlags := []string{
+		"reproducible",
+		"sign",
+		"workspace",
+		"workspace-concurrency",
+	}
 
 	iter := buildFlags.MapRange()
 	for iter.Next() {
@@ -150,8 +163,12 @@ func TestFlagDivergenceHashFiles(t *testing.T) {
 	)
 
 	// Some flags on `compute build` are unique to it.
-	// NOTE: There are no flags to ignore but I'm keeping the logic for future.
-	ignoreBuildFlags := []string{}
+	ignoreBuildFlags := []string{
+		"reproducible",
+		"sign",
+		"workspace",
+		"workspace-concurrency",
+	}
 
 	iter := buildFlags.MapRange()
 	for iter.Next() {
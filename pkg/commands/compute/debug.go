@@ -0,0 +1,249 @@
+package compute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+const (
+	// debugConfigurationName is the name of the VS Code launch configuration.
+	debugConfigurationName = "Fastly Compute: debug"
+	// debugDefaultPort is the default port of the Debug Adapter Protocol server.
+	debugDefaultPort = 4711
+	// launchJSONPath is the path of the VS Code launch configuration file,
+	// relative to the project directory.
+	launchJSONPath = ".vscode/launch.json"
+)
+
+// debugBuildEnv are the environment variables set for the build, so that the
+// Wasm binary keeps the debug information that maps it to the source code.
+// They configure the Cargo release profile used by the Rust build script.
+var debugBuildEnv = map[string]string{
+	"CARGO_PROFILE_RELEASE_DEBUG": "true",
+	"CARGO_PROFILE_RELEASE_STRIP": "none",
+}
+
+// DebugCommand builds a Compute package with debug information and runs it
+// locally with Viceroy acting as a Debug Adapter Protocol (DAP) server, which
+// an editor attaches to.
+type DebugCommand struct {
+	argparser.Base
+	serve *ServeCommand
+
+	// Serve fields
+	addr                string
+	dir                 argparser.OptionalString
+	env                 argparser.OptionalString
+	file                argparser.OptionalString
+	skipBuild           bool
+	viceroyBinExtraArgs string
+	viceroyBinPath      string
+
+	// Debug fields
+	port            int
+	writeLaunchJSON bool
+}
+
+// NewDebugCommand returns a usable command registered under the parent.
+func NewDebugCommand(parent argparser.Registerer, g *global.Data, serve *ServeCommand) *DebugCommand {
+	var c DebugCommand
+	c.Globals = g
+	c.serve = serve
+	c.CmdClause = parent.Command("debug", "Build a Compute package with debug information and run it locally, with a Debug Adapter Protocol server for your editor to attach to")
+
+	c.CmdClause.Flag("addr", "The IPv4 address and port to listen on").Default("127.0.0.1:7676").StringVar(&c.addr)
+	c.CmdClause.Flag("dir", "Project directory to build (default: current directory)").Short('C').Action(c.dir.Set).StringVar(&c.dir.Value)
+	c.CmdClause.Flag("env", "The manifest environment config to use (e.g. 'stage' will attempt to read 'fastly.stage.toml')").Action(c.env.Set).StringVar(&c.env.Value)
+	c.CmdClause.Flag("file", "The Wasm file to run (causes build process to be skipped)").Action(c.file.Set).StringVar(&c.file.Value)
+	c.CmdClause.Flag("port", "The port of the Debug Adapter Protocol server").Default(fmt.Sprint(debugDefaultPort)).IntVar(&c.port)
+	c.CmdClause.Flag("skip-build", "Skip the build step").BoolVar(&c.skipBuild)
+	c.CmdClause.Flag("viceroy-args", "Additional arguments to pass to the Viceroy binary, separated by space").StringVar(&c.viceroyBinExtraArgs)
+	c.CmdClause.Flag("viceroy-path", "The path to a user installed version of the Viceroy binary").StringVar(&c.viceroyBinPath)
+	c.CmdClause.Flag("write-launch-json", fmt.Sprintf("Add the launch configuration to %s in the project directory", launchJSONPath)).BoolVar(&c.writeLaunchJSON)
+
+	return &c
+}
+
+// Exec implements the command interface.
+func (c *DebugCommand) Exec(in io.Reader, out io.Writer) error {
+	if c.port <= 0 || c.port > 65535 {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("invalid --port %d", c.port),
+			Remediation: "Set --port to a port number between 1 and 65535.",
+		}
+	}
+	// Fail before building if the debug server won't be able to listen.
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", c.port))
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("the debug port %d isn't available: %w", c.port, err),
+			Remediation: "Stop the process using the port, or choose another one with --port.",
+		}
+	}
+	_ = l.Close()
+
+	if !c.skipBuild && !c.file.WasSet {
+		for k, v := range debugBuildEnv {
+			if _, ok := os.LookupEnv(k); !ok {
+				if err := os.Setenv(k, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Reset the fields on the ServeCommand based on DebugCommand values.
+	c.serve.addr = c.addr
+	c.serve.debug = true
+	c.serve.debugPort = c.port
+	c.serve.dir = c.dir
+	c.serve.env = c.env
+	c.serve.file = c.file
+	c.serve.skipBuild = c.skipBuild
+	c.serve.ViceroyBinExtraArgs = c.viceroyBinExtraArgs
+	c.serve.ViceroyBinPath = c.viceroyBinPath
+
+	// The launch configuration is printed once the package is built (from the
+	// project directory), just before Viceroy starts.
+	c.serve.beforeRun = func(out io.Writer) error {
+		language := c.Globals.Manifest.File.Language
+		if language == "javascript" && !c.file.WasSet && !hasSourceMaps("bin") {
+			text.Warning(out, "No source maps were found in ./bin, so breakpoints can only be set in the generated JavaScript. Configure your bundler to write source maps (e.g. `esbuild --sourcemap`) next to the bundle it writes to ./bin.\n\n")
+		}
+
+		cfg := newLaunchConfiguration(language, c.port)
+		if c.writeLaunchJSON {
+			if err := writeLaunchConfiguration(launchJSONPath, cfg); err != nil {
+				c.Globals.ErrLog.Add(err)
+				return err
+			}
+			text.Success(out, "Added the %q launch configuration to %s\n", cfg.Name, launchJSONPath)
+		} else {
+			data, err := json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				return err
+			}
+			text.Info(out, "Add this configuration to %s (or use --write-launch-json), then start debugging in VS Code:\n\n", launchJSONPath)
+			text.Output(out, "%s\n", data)
+		}
+		text.Info(out, "Debug Adapter Protocol server listening on 127.0.0.1:%d", c.port)
+		return nil
+	}
+
+	return c.serve.Exec(in, out)
+}
+
+// launchConfiguration is a VS Code launch configuration that attaches to the
+// Debug Adapter Protocol server run by Viceroy.
+//
+// Reference: https://code.visualstudio.com/docs/editor/debugging#_launch-configurations
+type launchConfiguration struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Request     string `json:"request"`
+	DebugServer int    `json:"debugServer"`
+	Program     string `json:"program"`
+	Cwd         string `json:"cwd"`
+	// SourceLanguages and SourceMap are used for languages compiled with DWARF
+	// debug information.
+	SourceLanguages []string          `json:"sourceLanguages,omitempty"`
+	SourceMap       map[string]string `json:"sourceMap,omitempty"`
+	// SourceMaps and OutFiles are used for JavaScript, where breakpoints are
+	// mapped through the source maps of the bundle.
+	SourceMaps bool     `json:"sourceMaps,omitempty"`
+	OutFiles   []string `json:"outFiles,omitempty"`
+}
+
+// newLaunchConfiguration returns the launch configuration for a language.
+func newLaunchConfiguration(language string, port int) launchConfiguration {
+	cfg := launchConfiguration{
+		Name:        debugConfigurationName,
+		Type:        "lldb",
+		Request:     "attach",
+		DebugServer: port,
+		Program:     "${workspaceFolder}/" + strings.TrimPrefix(binWasmPath, "./"),
+		Cwd:         "${workspaceFolder}",
+	}
+	switch language {
+	case "javascript":
+		cfg.Type = "node"
+		cfg.SourceMaps = true
+		cfg.OutFiles = []string{"${workspaceFolder}/bin/**/*.js"}
+	case "rust", "cpp", "go":
+		cfg.SourceLanguages = []string{language}
+		cfg.SourceMap = map[string]string{".": "${workspaceFolder}"}
+	}
+	return cfg
+}
+
+// writeLaunchConfiguration adds a configuration to a VS Code launch.json file,
+// replacing any configuration with the same name.
+func writeLaunchConfiguration(path string, cfg launchConfiguration) error {
+	launch := map[string]any{
+		"version":        "0.2.0",
+		"configurations": []any{},
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("error reading %s: %w", path, err)
+	default:
+		if err := json.Unmarshal(data, &launch); err != nil {
+			return fsterr.RemediationError{
+				Inner:       fmt.Errorf("error parsing %s: %w", path, err),
+				Remediation: fmt.Sprintf("The file may contain comments, which can't be preserved. Run the command without --write-launch-json and add the printed configuration to %s yourself.", path),
+			}
+		}
+	}
+
+	configurations, _ := launch["configurations"].([]any)
+	var kept []any
+	for _, c := range configurations {
+		if m, ok := c.(map[string]any); ok && m["name"] == cfg.Name {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	launch["configurations"] = append(kept, cfg)
+
+	data, err = json.MarshalIndent(launch, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// hasSourceMaps reports whether there are any source maps in a directory.
+func hasSourceMaps(dir string) bool {
+	var found bool
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found {
+			return fs.SkipAll
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".map") {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,96 @@
+package compute
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteLaunchConfiguration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".vscode", "launch.json")
+
+	read := func() map[string]any {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var launch map[string]any
+		if err := json.Unmarshal(data, &launch); err != nil {
+			t.Fatal(err)
+		}
+		return launch
+	}
+
+	if err := writeLaunchConfiguration(path, newLaunchConfiguration("rust", 4711)); err != nil {
+		t.Fatal(err)
+	}
+	launch := read()
+	configurations := launch["configurations"].([]any)
+	if launch["version"] != "0.2.0" || len(configurations) != 1 {
+		t.Fatalf("want a new launch.json with one configuration, have %v", launch)
+	}
+	cfg := configurations[0].(map[string]any)
+	if cfg["type"] != "lldb" || cfg["debugServer"] != float64(4711) {
+		t.Errorf("want an lldb configuration attaching to port 4711, have %v", cfg)
+	}
+
+	// Other configurations are kept, and ours is replaced.
+	launch["configurations"] = append(configurations, map[string]any{"name": "Run tests", "type": "node"})
+	data, err := json.Marshal(launch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLaunchConfiguration(path, newLaunchConfiguration("javascript", 9229)); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range read()["configurations"].([]any) {
+		cfg := c.(map[string]any)
+		names = append(names, cfg["name"].(string))
+		if cfg["name"] == debugConfigurationName && (cfg["sourceMaps"] != true || cfg["debugServer"] != float64(9229)) {
+			t.Errorf("want the configuration to be replaced, have %v", cfg)
+		}
+	}
+	if !slices.Equal(names, []string{"Run tests", debugConfigurationName}) {
+		t.Errorf("want the other configurations to be kept, have %v", names)
+	}
+
+	// Files that aren't plain JSON (e.g. with comments) aren't overwritten.
+	if err := os.WriteFile(path, []byte("// comment\n{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLaunchConfiguration(path, newLaunchConfiguration("go", 4711)); err == nil {
+		t.Error("want an error for a launch.json with comments")
+	}
+}
+
+func TestViceroyArgsDebug(t *testing.T) {
+	args := viceroyArgs(localOpts{addr: "127.0.0.1:7676", debug: true, debugPort: 4711, manifestPath: "fastly.toml", wasmBinPath: "bin/main.wasm"})
+	if !slices.Contains(args, "--debug") || !slices.Contains(args, "--debug-port=4711") {
+		t.Errorf("want the debug flags, have %v", args)
+	}
+
+	args = viceroyArgs(localOpts{addr: "127.0.0.1:7676", manifestPath: "fastly.toml", wasmBinPath: "bin/main.wasm"})
+	if slices.Contains(args, "--debug") {
+		t.Errorf("want no debug flags, have %v", args)
+	}
+}
+
+func TestHasSourceMaps(t *testing.T) {
+	dir := t.TempDir()
+	if hasSourceMaps(dir) || hasSourceMaps(filepath.Join(dir, "missing")) {
+		t.Error("want no source maps")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.js.map"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !hasSourceMaps(dir) {
+		t.Error("want a source map")
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
 	"github.com/fastly/cli/pkg/commands/compute/setup"
 	"github.com/fastly/cli/pkg/debug"
 	fsterr "github.com/fastly/cli/pkg/errors"
@@ -52,13 +53,19 @@ type DeployCommand struct {
 	Env                string
 	NoDefaultDomain    argparser.OptionalBool
 	PackagePath        string
+	ProjectLedger      bool
+	Rollback           bool
+	RollbackTo         int
 	ServiceName        argparser.OptionalServiceNameID
 	ServiceVersion     argparser.OptionalServiceVersion
 	StatusCheckCode    int
 	StatusCheckOff     bool
 	StatusCheckPath    string
 	StatusCheckTimeout int
+	VerifyKey          string
 	SkipChangeDir      bool // set by parent composite commands (e.g. serve, publish)
+
+	workspace workspaceOpts
 }
 
 // NewDeployCommand returns a usable command registered under the parent.
@@ -93,15 +100,24 @@ func NewDeployCommand(parent argparser.Registerer, g *global.Data) *DeployComman
 	c.CmdClause.Flag("env", "The manifest environment config to use (e.g. 'stage' will attempt to read 'fastly.stage.toml')").StringVar(&c.Env)
 	c.CmdClause.Flag("no-default-domain", "Skip default domain creation").Action(c.NoDefaultDomain.Set).BoolVar(&c.NoDefaultDomain.Value)
 	c.CmdClause.Flag("package", "Path to a package tar.gz").Short('p').StringVar(&c.PackagePath)
+	c.CmdClause.Flag("project-ledger", fmt.Sprintf("Also record the deployment in %s next to the manifest (always done once that file exists)", deployments.ProjectFilename)).BoolVar(&c.ProjectLedger)
+	c.CmdClause.Flag("rollback", "Reactivate the previously deployed version instead of deploying a package").BoolVar(&c.Rollback)
 	c.CmdClause.Flag("status-check-code", "Set the expected status response for the service availability check").IntVar(&c.StatusCheckCode)
 	c.CmdClause.Flag("status-check-off", "Disable the service availability check").BoolVar(&c.StatusCheckOff)
 	c.CmdClause.Flag("status-check-path", "Specify the URL path for the service availability check").Default("/").StringVar(&c.StatusCheckPath)
 	c.CmdClause.Flag("status-check-timeout", "Set a timeout (in seconds) for the service availability check").Default("120").IntVar(&c.StatusCheckTimeout)
+	c.CmdClause.Flag("to", "The version to reactivate with --rollback (default: the previous deployment)").IntVar(&c.RollbackTo)
+	c.CmdClause.Flag("verify", "Path to an Ed25519 public key (PEM) used to check the package signature (created by `compute build --sign`) before uploading").StringVar(&c.VerifyKey)
+	registerWorkspaceFlags(c.CmdClause, &c.workspace)
 	return &c
 }
 
 // Exec implements the command interface.
 func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	if c.workspace.enabled {
+		return runWorkspace(c.Globals.Args, "deploy", c.workspace, out)
+	}
+
 	manifestFilename := EnvironmentManifest(c.Env)
 	if c.Env != "" {
 		if c.Globals.Verbose() {
@@ -136,6 +152,16 @@ func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return err
 	}
 
+	if c.Rollback {
+		return c.RollbackService(spinner, out)
+	}
+	if c.RollbackTo != 0 {
+		return fsterr.RemediationError{
+			Inner:       errors.New("--to can only be used with --rollback"),
+			Remediation: "Run `fastly compute deploy --rollback --to <version>`.",
+		}
+	}
+
 	err = spinner.Process(fmt.Sprintf("Verifying %s", manifestFilename), func(_ *text.SpinnerWrapper) error {
 		// The check for c.SkipChangeDir here is because we might need to attempt
 		// another read of the manifest file. To explain: if we're skipping the
@@ -298,6 +324,7 @@ func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	if err = c.ProcessService(serviceID, serviceVersionNumber, spinner); err != nil {
 		return err
 	}
+	c.RecordDeployment(serviceID, serviceVersionNumber, out)
 
 	serviceURL, err := c.GetServiceURL(serviceID, serviceVersionNumber)
 	if err != nil {
@@ -394,6 +421,26 @@ func (c *DeployCommand) Setup(out io.Writer) (serviceID string, err error) {
 		return serviceID, err
 	}
 
+	if c.VerifyKey != "" {
+		err = verifyPackage(c.VerifyKey, c.PackagePath)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]any{
+				"Package path": c.PackagePath,
+				"Key":          c.VerifyKey,
+			})
+			return serviceID, err
+		}
+		text.Info(out, "Verified the signature of %s", c.PackagePath)
+	}
+
+	err = checkBudget(out, c.Globals.Manifest.File.Scripts.Budget, c.PackagePath)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Package path": c.PackagePath,
+		})
+		return serviceID, err
+	}
+
 	return serviceID, err
 }
 
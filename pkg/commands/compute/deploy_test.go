@@ -15,6 +15,7 @@ import (
 
 	"github.com/fastly/cli/pkg/app"
 	"github.com/fastly/cli/pkg/commands/compute"
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
 	"github.com/fastly/cli/pkg/errors"
 	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/manifest"
@@ -2250,6 +2251,100 @@ func TestDeploy_ActivateBeacon(t *testing.T) {
 	testutil.AssertEqual(t, "fastly-notification-relay.edgecompute.app", beaconReq.URL.Hostname())
 }
 
+func TestDeploy_Rollback(t *testing.T) {
+	hashes := map[int]string{2: "aaaaaaaaaaaaaaaa", 3: "bbbbbbbbbbbbbbbb"}
+	withLedger := func(t *testing.T, _ *testutil.CLIScenario, opts *global.Data) {
+		opts.ConfigPath = filepath.Join(t.TempDir(), "config.toml")
+		at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		for _, v := range []int{2, 3} {
+			e := deployments.Entry{ServiceID: "123", Version: v, PackageHash: hashes[v], DeployedAt: at.Add(time.Duration(v) * time.Hour)}
+			if err := deployments.Append(deployments.LocalPath(opts.ConfigPath, "123"), e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	getServiceDetails := func(_ context.Context, i *fastly.GetServiceDetailsInput) (*fastly.ServiceDetail, error) {
+		return &fastly.ServiceDetail{
+			ServiceID:     fastly.ToPointer(i.ServiceID),
+			ActiveVersion: &fastly.Version{Number: fastly.ToPointer(3), Active: fastly.ToPointer(true)},
+		}, nil
+	}
+	getPackage := func(_ context.Context, i *fastly.GetPackageInput) (*fastly.Package, error) {
+		return &fastly.Package{
+			ServiceID:      fastly.ToPointer(i.ServiceID),
+			ServiceVersion: fastly.ToPointer(i.ServiceVersion),
+			Metadata: &fastly.PackageMetadata{
+				FilesHash: fastly.ToPointer(hashes[i.ServiceVersion]),
+			},
+		}, nil
+	}
+	activateVersion := func(_ context.Context, i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+		return &fastly.Version{ServiceID: fastly.ToPointer(i.ServiceID), Number: fastly.ToPointer(i.ServiceVersion)}, nil
+	}
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Name:      "validate --to requires --rollback",
+			Args:      "--to 2",
+			WantError: "--to can only be used with --rollback",
+		},
+		{
+			Name: "validate empty ledger",
+			Args: "--rollback --service-id 123",
+			API: &mock.API{
+				GetPackageFn:        getPackage,
+				GetServiceDetailsFn: getServiceDetails,
+			},
+			Setup: func(t *testing.T, _ *testutil.CLIScenario, opts *global.Data) {
+				opts.ConfigPath = filepath.Join(t.TempDir(), "config.toml")
+			},
+			WantError: "no previous deployment to roll back to",
+		},
+		{
+			Name: "validate rollback to the previous deployment",
+			Args: "--rollback --service-id 123",
+			API: &mock.API{
+				ActivateVersionFn:   activateVersion,
+				GetPackageFn:        getPackage,
+				GetServiceDetailsFn: getServiceDetails,
+			},
+			Setup:      withLedger,
+			WantOutput: "Rolled back service 123 from version 3 to version 2",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, opts *global.Data, _ *threadsafe.Buffer) {
+				entries, err := deployments.Load("123", deployments.LocalPath(opts.ConfigPath, "123"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				last := entries[len(entries)-1]
+				testutil.AssertEqual(t, 2, last.Version)
+				testutil.AssertEqual(t, 3, last.RollbackFrom)
+			},
+		},
+		{
+			Name: "validate the package must match the ledger",
+			Args: "--rollback --service-id 123",
+			API: &mock.API{
+				GetPackageFn:        getPackageIdentical,
+				GetServiceDetailsFn: getServiceDetails,
+			},
+			Setup:     withLedger,
+			WantError: "the package of service version 2 doesn't match the one recorded in the deployment ledger",
+		},
+		{
+			Name: "validate --to the active version",
+			Args: "--rollback --to 3 --service-id 123",
+			API: &mock.API{
+				GetPackageFn:        getPackage,
+				GetServiceDetailsFn: getServiceDetails,
+			},
+			Setup:     withLedger,
+			WantError: "service version 3 is already active",
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{compute.CommandName, "deploy"}, scenarios)
+}
+
 func createServiceOK(_ context.Context, i *fastly.CreateServiceInput) (*fastly.Service, error) {
 	return &fastly.Service{
 		ServiceID: fastly.ToPointer("12345"),
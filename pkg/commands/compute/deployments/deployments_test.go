@@ -0,0 +1,77 @@
+package deployments_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	root "github.com/fastly/cli/pkg/commands/compute"
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/testutil"
+)
+
+func TestDeploymentsList(t *testing.T) {
+	withLedger := func(t *testing.T, _ *testutil.CLIScenario, opts *global.Data) {
+		opts.ConfigPath = filepath.Join(t.TempDir(), "config.toml")
+		at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		for _, e := range []deployments.Entry{
+			{ServiceID: "123", Version: 4, PackageHash: "0123456789abcdef", GitSHA: "fedcba9876543210", Comment: "new feature", DeployedAt: at},
+			{ServiceID: "123", Version: 3, PackageHash: "aaaaaaaaaaaaaaaa", DeployedAt: at.Add(time.Hour), RollbackFrom: 4},
+		} {
+			if err := deployments.Append(deployments.LocalPath(opts.ConfigPath, "123"), e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// A project ledger kept next to an environment manifest in another
+	// directory.
+	projectDir := t.TempDir()
+	manifestPath := filepath.Join(projectDir, "fastly.stage.toml")
+	if err := os.WriteFile(manifestPath, []byte("manifest_version = 3\nname = \"stage\"\nservice_id = \"456\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stageEntry := deployments.Entry{ServiceID: "456", Version: 7, PackageHash: "bbbbbbbbbbbbbbbb", Comment: "stage", DeployedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := deployments.Append(deployments.ProjectPath(manifestPath), stageEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Name: "validate empty ledger",
+			Args: "--service-id 123",
+			Setup: func(t *testing.T, _ *testutil.CLIScenario, opts *global.Data) {
+				opts.ConfigPath = filepath.Join(t.TempDir(), "config.toml")
+			},
+			WantOutput: "No deployments of service 123 have been recorded",
+		},
+		{
+			Name:  "validate deployments are listed oldest first",
+			Args:  "--service-id 123",
+			Setup: withLedger,
+			WantOutputs: []string{
+				"VERSION  DEPLOYED AT           PACKAGE HASH  GIT SHA  COMMENT",
+				"4        2026-01-02T03:04:05Z  0123456789ab  fedcba9  new feature",
+				"3        2026-01-02T04:04:05Z  aaaaaaaaaaaa           (rollback from version 4)",
+			},
+		},
+		{
+			Name: "validate --dir and --env locate the project ledger",
+			Args: "--dir " + projectDir + " --env stage",
+			Setup: func(t *testing.T, _ *testutil.CLIScenario, opts *global.Data) {
+				opts.ConfigPath = filepath.Join(t.TempDir(), "config.toml")
+			},
+			WantOutput: "7        2026-01-02T03:04:05Z  bbbbbbbbbbbb           stage",
+		},
+		{
+			Name:       "validate --json",
+			Args:       "--service-id 123 --json",
+			Setup:      withLedger,
+			WantOutput: `"rollback_from": 4`,
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, deployments.CommandName, "list"}, scenarios)
+}
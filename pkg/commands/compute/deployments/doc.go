@@ -0,0 +1,3 @@
+// Package deployments contains the deployment ledger written by `compute
+// deploy`, and commands to inspect it.
+package deployments
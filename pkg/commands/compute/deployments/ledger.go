@@ -0,0 +1,180 @@
+package deployments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+)
+
+// ProjectFilename is the name of the ledger kept next to the fastly.toml
+// manifest, so that it can be committed along with the project.
+const ProjectFilename = "fastly.deployments.json"
+
+// Entry records a package deployed to a service version.
+type Entry struct {
+	// ServiceID is the service the package was deployed to.
+	ServiceID string `json:"service_id"`
+	// Version is the service version that was activated.
+	Version int `json:"version"`
+	// PackageHash is the SHA512 digest of the package contents (the same
+	// value reported by `compute hash-files`).
+	PackageHash string `json:"package_hash"`
+	// Comment is the --comment given to `compute deploy`.
+	Comment string `json:"comment,omitempty"`
+	// GitSHA is the commit checked out in the project when it was deployed.
+	GitSHA string `json:"git_sha,omitempty"`
+	// DeployedAt is when the version was activated.
+	DeployedAt time.Time `json:"deployed_at"`
+	// RollbackFrom is set when the version was reactivated by `compute deploy
+	// --rollback`, and is the version that was rolled back.
+	RollbackFrom int `json:"rollback_from,omitempty"`
+}
+
+// Ledger is the on-disk format of a deployment ledger.
+type Ledger struct {
+	Deployments []Entry `json:"deployments"`
+}
+
+// LocalPath returns the path of the ledger kept alongside the CLI's
+// application configuration for a service.
+func LocalPath(configPath, serviceID string) string {
+	return filepath.Join(filepath.Dir(configPath), "deployments", serviceID+".json")
+}
+
+// ProjectPath returns the path of the ledger kept next to a manifest.
+func ProjectPath(manifestPath string) string {
+	return filepath.Join(filepath.Dir(manifestPath), ProjectFilename)
+}
+
+// Read reads a ledger. A ledger that doesn't exist yet is empty.
+func Read(path string) (Ledger, error) {
+	var l Ledger
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return l, nil
+		}
+		return l, fmt.Errorf("error reading deployment ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return l, fsterr.RemediationError{
+			Inner:       fmt.Errorf("error parsing deployment ledger %s: %w", path, err),
+			Remediation: "Fix or remove the file. The ledger is only used to look up previous deployments.",
+		}
+	}
+	return l, nil
+}
+
+// Append adds an entry to a ledger, creating it if necessary.
+func Append(path string, e Entry) error {
+	l, err := Read(path)
+	if err != nil {
+		return err
+	}
+	l.Deployments = append(l.Deployments, e)
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding deployment ledger: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error creating deployment ledger directory: %w", err)
+	}
+	// G306 (CWE-276): Expect WriteFile permissions to be 0600 or less
+	// Disabling as the ledger holds nothing sensitive and may be committed.
+	// #nosec
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing deployment ledger: %w", err)
+	}
+	return nil
+}
+
+// Load returns the deployments of a service recorded in any of the given
+// ledgers, oldest first. Deployments recorded in more than one ledger are only
+// returned once.
+func Load(serviceID string, paths ...string) ([]Entry, error) {
+	var entries []Entry
+	seen := make(map[Entry]bool)
+	for _, path := range paths {
+		l, err := Read(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range l.Deployments {
+			if e.ServiceID != serviceID || seen[e] {
+				continue
+			}
+			seen[e] = true
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeployedAt.Before(entries[j].DeployedAt)
+	})
+	return entries, nil
+}
+
+// RollbackTarget picks the deployment to reactivate when rolling back from the
+// active version.
+//
+// With to set, it's the latest deployment of that version. Otherwise it's the
+// latest deployment of a version other than the active one, with a different
+// package, that wasn't itself rolled back.
+func RollbackTarget(entries []Entry, active int, activeHash string, to int) (Entry, error) {
+	if to != 0 {
+		if to == active {
+			return Entry{}, fmt.Errorf("service version %d is already active", to)
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Version == to {
+				return entries[i], nil
+			}
+		}
+		return Entry{}, fsterr.RemediationError{
+			Inner:       fmt.Errorf("service version %d isn't in the deployment ledger", to),
+			Remediation: "Run `fastly compute deployments list` to see the versions that can be rolled back to.",
+		}
+	}
+
+	rolledBack := make(map[int]bool)
+	for _, e := range entries {
+		if e.RollbackFrom != 0 {
+			rolledBack[e.RollbackFrom] = true
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Version == active || rolledBack[e.Version] || (activeHash != "" && e.PackageHash == activeHash) {
+			continue
+		}
+		return e, nil
+	}
+	return Entry{}, fsterr.RemediationError{
+		Inner:       errors.New("no previous deployment to roll back to"),
+		Remediation: "Only versions deployed with `fastly compute deploy` are recorded in the deployment ledger. Use `fastly service version activate` to activate any other version.",
+	}
+}
+
+// GitSHA returns the commit checked out in dir, or an empty string if dir
+// isn't in a git repository.
+func GitSHA(dir string) string {
+	// gosec flagged this:
+	// G204 (CWE-78): Subprocess launched with variable
+	// Disabling as the arguments are fixed.
+	// #nosec
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
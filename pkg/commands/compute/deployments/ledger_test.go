@@ -0,0 +1,93 @@
+package deployments
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local.json")
+	project := filepath.Join(dir, "project.json")
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	v1 := Entry{ServiceID: "123", Version: 1, PackageHash: "aaa", DeployedAt: at}
+	v2 := Entry{ServiceID: "123", Version: 2, PackageHash: "bbb", DeployedAt: at.Add(time.Hour)}
+	other := Entry{ServiceID: "456", Version: 7, PackageHash: "ccc", DeployedAt: at}
+
+	for _, e := range []Entry{v2, v1} {
+		if err := Append(local, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []Entry{v1, other} {
+		if err := Append(project, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Load("123", local, project, filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]Entry{v1, v2}, got); diff != "" {
+		t.Errorf("unexpected entries (-want +got):\n%s", diff)
+	}
+}
+
+func TestRollbackTarget(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := func(version int, hash string, hours int) Entry {
+		return Entry{ServiceID: "123", Version: version, PackageHash: hash, DeployedAt: at.Add(time.Duration(hours) * time.Hour)}
+	}
+	entries := []Entry{
+		entry(1, "aaa", 0),
+		entry(2, "bbb", 1),
+		entry(3, "bbb", 2),
+		entry(4, "ccc", 3),
+	}
+
+	for _, tc := range []struct {
+		name       string
+		entries    []Entry
+		active     int
+		activeHash string
+		to         int
+		want       int
+		wantErr    string
+	}{
+		{name: "previous deployment", entries: entries, active: 4, activeHash: "ccc", want: 3},
+		{name: "skips versions with the active package", entries: entries[:3], active: 3, activeHash: "bbb", want: 1},
+		{name: "active version not in the ledger", entries: entries, active: 9, want: 4},
+		{name: "explicit version", entries: entries, active: 4, to: 1, want: 1},
+		{name: "explicit active version", entries: entries, active: 4, to: 4, wantErr: "service version 4 is already active"},
+		{name: "explicit unknown version", entries: entries, active: 4, to: 8, wantErr: "service version 8 isn't in the deployment ledger"},
+		{
+			name:    "skips versions that were rolled back",
+			entries: append(entries[:4:4], Entry{ServiceID: "123", Version: 3, PackageHash: "bbb", DeployedAt: at.Add(4 * time.Hour), RollbackFrom: 4}, entry(5, "ddd", 5)),
+			active:  5,
+			want:    3,
+		},
+		{name: "nothing to roll back to", entries: entries[:1], active: 1, activeHash: "aaa", wantErr: "no previous deployment"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RollbackTarget(tc.entries, tc.active, tc.activeHash, tc.to)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want error %q, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Version != tc.want {
+				t.Errorf("want version %d, got %d", tc.want, got.Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package deployments
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/manifest"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// ListCommand lists the deployments recorded in the deployment ledger.
+type ListCommand struct {
+	argparser.Base
+	argparser.JSONOutput
+
+	dir         string
+	env         string
+	serviceName argparser.OptionalServiceNameID
+}
+
+// NewListCommand returns a usable command registered under the parent.
+func NewListCommand(parent argparser.Registerer, g *global.Data) *ListCommand {
+	c := ListCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("list", "List the packages deployed to a Compute service with `compute deploy`")
+
+	// Optional.
+	c.CmdClause.Flag("dir", "Project directory (default: current directory)").Short('C').StringVar(&c.dir)
+	c.CmdClause.Flag("env", "The manifest environment config to use (e.g. 'stage' will attempt to read 'fastly.stage.toml')").StringVar(&c.env)
+	c.RegisterFlagBool(c.JSONFlag())
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagServiceIDName,
+		Description: argparser.FlagServiceIDDesc,
+		Dst:         &g.Manifest.Flag.ServiceID,
+		Short:       's',
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceName.Set,
+		Name:        argparser.FlagServiceName,
+		Description: argparser.FlagServiceNameDesc,
+		Dst:         &c.serviceName.Value,
+	})
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ListCommand) Exec(_ io.Reader, out io.Writer) error {
+	if c.Globals.Verbose() && c.JSONOutput.Enabled {
+		return fsterr.ErrInvalidVerboseJSONCombo
+	}
+
+	// The project ledger is kept next to the manifest, which is resolved the
+	// same way as `compute deploy` does.
+	manifestFilename := manifest.Filename
+	if c.env != "" {
+		manifestFilename = fmt.Sprintf("fastly.%s.toml", c.env)
+	}
+	dir, err := filepath.Abs(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to construct absolute path to directory '%s': %w", c.dir, err)
+	}
+	manifestPath := filepath.Join(dir, manifestFilename)
+
+	// The manifest is read from the current directory on startup, so it needs
+	// reading again to pick up the service ID of another project or environment.
+	if c.dir != "" || c.env != "" {
+		_ = c.Globals.Manifest.File.Read(manifestPath)
+	}
+
+	serviceID, source, flag, err := argparser.ServiceID(c.serviceName, *c.Globals.Manifest, c.Globals.APIClient, c.Globals.ErrLog)
+	if err != nil {
+		return err
+	}
+	if c.Globals.Verbose() {
+		argparser.DisplayServiceID(serviceID, flag, source, out)
+	}
+
+	entries, err := Load(serviceID, LocalPath(c.Globals.ConfigPath, serviceID), ProjectPath(manifestPath))
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID": serviceID,
+		})
+		return err
+	}
+
+	if ok, err := c.WriteJSON(out, entries); ok {
+		return err
+	}
+
+	if len(entries) == 0 {
+		text.Info(out, "No deployments of service %s have been recorded", serviceID)
+		return nil
+	}
+
+	tbl := text.NewTable(out)
+	tbl.AddHeader("VERSION", "DEPLOYED AT", "PACKAGE HASH", "GIT SHA", "COMMENT")
+	for _, e := range entries {
+		comment := e.Comment
+		if e.RollbackFrom != 0 {
+			comment = strings.TrimSpace(fmt.Sprintf("(rollback from version %d) %s", e.RollbackFrom, comment))
+		}
+		tbl.AddLine(e.Version, e.DeployedAt.UTC().Format(time.RFC3339), abbreviate(e.PackageHash, 12), abbreviate(e.GitSHA, 7), comment)
+	}
+	tbl.Print()
+	return nil
+}
+
+// abbreviate shortens a hash for display.
+func abbreviate(hash string, n int) string {
+	if len(hash) > n {
+		return hash[:n]
+	}
+	return hash
+}
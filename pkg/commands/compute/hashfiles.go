@@ -8,7 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 
 	"github.com/kennygrant/sanitize"
 	"github.com/mholt/archives"
@@ -39,6 +39,7 @@ type HashFilesCommand struct {
 	metadataDisable       argparser.OptionalBool
 	metadataFilterEnvVars argparser.OptionalString
 	metadataShow          argparser.OptionalBool
+	noCache               argparser.OptionalBool
 	packageName           argparser.OptionalString
 	timeout               argparser.OptionalInt
 
@@ -60,6 +61,7 @@ func NewHashFilesCommand(parent argparser.Registerer, g *global.Data, build *Bui
 	c.CmdClause.Flag("metadata-disable", "Disable Wasm binary metadata annotations").Action(c.metadataDisable.Set).BoolVar(&c.metadataDisable.Value)
 	c.CmdClause.Flag("metadata-filter-envvars", "Redact specified environment variables from [scripts.env_vars] using comma-separated list").Action(c.metadataFilterEnvVars.Set).StringVar(&c.metadataFilterEnvVars.Value)
 	c.CmdClause.Flag("metadata-show", "Inspect the Wasm binary metadata").Action(c.metadataShow.Set).BoolVar(&c.metadataShow.Value)
+	c.CmdClause.Flag("no-cache", "Run the build even if nothing has changed since a package was cached by a previous build").Action(c.noCache.Set).BoolVar(&c.noCache.Value)
 	c.CmdClause.Flag("package", "Path to a package tar.gz").Short('p').StringVar(&c.Package)
 	c.CmdClause.Flag("package-name", "Package name").Action(c.packageName.Set).StringVar(&c.packageName.Value)
 	c.CmdClause.Flag("skip-build", "Skip the build step").BoolVar(&c.SkipBuild)
@@ -171,6 +173,9 @@ func (c *HashFilesCommand) Build(in io.Reader, out io.Writer) error {
 	if c.metadataShow.WasSet {
 		c.buildCmd.MetadataShow = c.metadataShow.Value
 	}
+	if c.noCache.WasSet {
+		c.buildCmd.Flags.NoCache = c.noCache.Value
+	}
 	return c.buildCmd.Exec(in, output)
 }
 
@@ -198,11 +203,25 @@ func getFilesHash(pkgPath string) (string, error) {
 	for k := range contents {
 		keys = append(keys, k)
 	}
-	sort.Strings(keys)
+	return hashFileContents(keys, func(name string) (io.ReadCloser, error) {
+		return io.NopCloser(contents[name]), nil
+	})
+}
+
+// hashFileContents returns a hash of the contents of the named files in sorted
+// filename order.
+func hashFileContents(names []string, open func(name string) (io.ReadCloser, error)) (string, error) {
+	names = slices.Sorted(slices.Values(names))
 
 	h := sha512.New()
-	for _, entry := range keys {
-		if _, err := io.Copy(h, contents[entry]); err != nil {
+	for _, name := range names {
+		rc, err := open(name)
+		if err != nil {
+			return "", fmt.Errorf("error opening %s: %w", name, err)
+		}
+		_, err = io.Copy(h, rc)
+		_ = rc.Close()
+		if err != nil {
 			return "", fmt.Errorf("failed to generate hash from package files: %w", err)
 		}
 	}
@@ -56,7 +56,8 @@ type InitCommand struct {
 	tag      string
 }
 
-// Languages is a list of supported language options.
+// Languages is a list of the built-in language options. Other languages are
+// supported by language plugins (see NewPlugin).
 var Languages = []string{"rust", "javascript", "go", "cpp", "python", "other"}
 
 // NewInitCommand returns a usable command registered under the parent.
@@ -69,7 +70,7 @@ func NewInitCommand(parent argparser.Registerer, g *global.Data) *InitCommand {
 	c.CmdClause.Flag("branch", "Git branch name to clone from package template repository").Hidden().StringVar(&c.branch)
 	c.CmdClause.Flag("directory", "Destination to write the new package, defaulting to the current directory").Short('p').StringVar(&c.dir)
 	c.CmdClause.Flag("from", "one of: (a) a starter kit identifier (starter-kit/<lang>/<name>); (b) a local project directory, a Git repository URL, or a URL referencing a .zip/.tar.gz file containing a package template; or (c) the service ID of a service created from a starter kit").Short('f').StringVar(&c.CloneFrom)
-	c.CmdClause.Flag("language", "Language of the package").Short('l').HintOptions(Languages...).EnumVar(&c.language, Languages...)
+	c.CmdClause.Flag("language", fmt.Sprintf("Language of the package (one of %s, or a language plugin)", strings.Join(Languages, ", "))).Short('l').HintOptions(Languages...).StringVar(&c.language)
 	c.CmdClause.Flag("tag", "Git tag name to clone from package template repository").Hidden().StringVar(&c.tag)
 
 	return &c
@@ -178,7 +179,7 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		}
 	}
 
-	languages := NewLanguages()
+	languages := append(NewLanguages(), pluginLanguages(mf.Languages)...)
 
 	var language *Language
 
@@ -189,7 +190,6 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		}
 	}
 
-	// NOTE: The --language flag is an EnumVar, meaning it's already validated.
 	if c.language != "" || mf.Language != "" {
 		l := c.language
 		if c.language == "" {
@@ -200,6 +200,18 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 				language = recognisedLanguage
 			}
 		}
+		if language == nil && c.language != "" {
+			return fsterr.RemediationError{
+				Inner:       fmt.Errorf("unsupported language %s", c.language),
+				Remediation: fmt.Sprintf("Use one of the built-in languages (%s), or install a %s%s language plugin on your PATH.", strings.Join(Languages, ", "), LanguagePluginPrefix, c.language),
+			}
+		}
+	}
+
+	// Language plugins have no starter kits on the starter kit service, but can
+	// provide a package template of their own.
+	if c.CloneFrom == "" && !mf.Exists() && language != nil && language.Plugin != nil {
+		c.CloneFrom = language.Plugin.StarterKit
 	}
 
 	var from, branch, tag string
@@ -208,7 +220,7 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	// fastly.toml manifest, or the language they selected was "other" (meaning
 	// they're bringing their own project code), then we'll prompt the user to
 	// select a starter kit project.
-	triggerStarterKitPrompt := c.CloneFrom == "" && !mf.Exists() && language.Name != "other"
+	triggerStarterKitPrompt := c.CloneFrom == "" && !mf.Exists() && language.Name != "other" && language.Plugin == nil
 	if triggerStarterKitPrompt {
 		client := starterkit.New(starterkit.DefaultEndpoint, c.Globals.HTTPClient, c.Globals.Flags.Debug)
 		if err := language.FetchStarterKits(client); err != nil {
@@ -415,6 +427,10 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return err
 	}
 
+	if language == nil {
+		// The package template may define a language plugin.
+		languages = append(languages, pluginLanguages(mf.Languages)...)
+	}
 	language, err = c.InitializeLanguage(spinner, language, languages, mf.Language, wd)
 	if err != nil {
 		c.Globals.ErrLog.Add(err)
@@ -1380,9 +1396,10 @@ func (c *InitCommand) UpdateManifest(m manifest.File, spinner text.Spinner, name
 	err := spinner.Process("Reading fastly.toml", func(_ *text.SpinnerWrapper) error {
 		if err := m.Read(mp); err != nil {
 			if language != nil {
-				if language.Name == "other" {
+				if language.Name == "other" || language.Plugin != nil {
 					// We create a fastly.toml manifest on behalf of the user if they're
-					// bringing their own pre-compiled Wasm binary to be packaged.
+					// bringing their own pre-compiled Wasm binary to be packaged, or
+					// using a language plugin without a package template.
 					m.ManifestVersion = manifest.ManifestLatestVersion
 					m.Name = name
 					m.Description = desc
@@ -0,0 +1,182 @@
+package compute
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mholt/archives"
+
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/wasm"
+)
+
+// wasiPreview1Module is the module providing the WASI preview 1 host functions.
+const wasiPreview1Module = "wasi_snapshot_preview1"
+
+// readPackageWasm reads the Wasm binary from a package.
+func readPackageWasm(pkgPath string) ([]byte, error) {
+	var data []byte
+	err := packageFiles(pkgPath, func(f archives.FileInfo) error {
+		if filepath.Base(f.NameInArchive) != "main.wasm" {
+			return nil
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", f.NameInArchive, err)
+		}
+		defer rc.Close()
+		data, err = io.ReadAll(rc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("package must contain a main.wasm file")
+	}
+	return data, nil
+}
+
+// writeWasmReport describes the structure of a Wasm binary: its sections,
+// imports, exports and the largest functions.
+func writeWasmReport(out io.Writer, b *wasm.Binary, topFunctions int) {
+	text.Output(out, "%s %s (%s)", text.Bold("Binary:"), b.Kind, formatSize(b.Size))
+	text.Break(out)
+
+	wasmReportHeading(out, "Sections:")
+	writeSectionSizes(out, b, len(b.Sections))
+	text.Break(out)
+
+	if b.Kind == wasm.KindComponent {
+		wasmReportHeading(out, "Imports (%d):", len(b.ComponentImports))
+		for _, name := range b.ComponentImports {
+			text.Output(out, "  %s", name)
+		}
+		text.Break(out)
+		wasmReportHeading(out, "Exports (%d):", len(b.ComponentExports))
+		for _, name := range b.ComponentExports {
+			text.Output(out, "  %s", name)
+		}
+		for _, w := range b.Warnings {
+			text.Break(out)
+			text.Warning(out, "%s", w)
+		}
+	} else {
+		modules := make(map[string][]string)
+		for _, imp := range b.Imports {
+			name := imp.Name
+			if imp.Kind != wasm.ExternalFunc {
+				name = fmt.Sprintf("%s (%s)", imp.Name, imp.Kind)
+			}
+			modules[imp.Module] = append(modules[imp.Module], name)
+		}
+		wasmReportHeading(out, "Imports (%d):", len(b.Imports))
+		for _, module := range sortedKeys(modules) {
+			text.Output(out, "  %s (%d): %s", module, len(modules[module]), strings.Join(modules[module], ", "))
+		}
+		text.Break(out)
+		wasmReportHeading(out, "Exports (%d):", len(b.Exports))
+		for _, e := range b.Exports {
+			text.Output(out, "  %s (%s)", e.Name, e.Kind)
+		}
+	}
+	text.Break(out)
+
+	if len(b.Functions) == 0 || topFunctions <= 0 {
+		return
+	}
+	funcs := append([]wasm.Function(nil), b.Functions...)
+	sort.SliceStable(funcs, func(i, j int) bool {
+		return funcs[i].Size > funcs[j].Size
+	})
+	funcs = funcs[:min(topFunctions, len(funcs))]
+	wasmReportHeading(out, "Largest functions (of %d):", len(b.Functions))
+	t := text.NewTable(out)
+	for _, f := range funcs {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("func[%d]", f.Index)
+		}
+		t.AddLine("  "+formatSize(f.Size), name)
+	}
+	t.Print()
+	text.Break(out)
+}
+
+// writeSectionSizes lists the largest sections of a Wasm binary, with their
+// share of the binary size.
+func writeSectionSizes(out io.Writer, b *wasm.Binary, limit int) {
+	sections := append([]wasm.Section(nil), b.Sections...)
+	sort.SliceStable(sections, func(i, j int) bool {
+		return sections[i].Size > sections[j].Size
+	})
+	t := text.NewTable(out)
+	for _, s := range sections[:min(limit, len(sections))] {
+		t.AddLine("  "+s.String(), formatSize(s.Size), fmt.Sprintf("%.1f%%", float64(s.Size)*100/float64(b.Size)))
+	}
+	t.Print()
+}
+
+// wasmReportHeading writes a heading of the report.
+func wasmReportHeading(out io.Writer, format string, args ...any) {
+	text.Output(out, "%s", text.Bold(fmt.Sprintf(format, args...)))
+}
+
+// wasmProblems returns the reasons a Wasm binary won't instantiate on the
+// Compute platform.
+func wasmProblems(b *wasm.Binary) []string {
+	var problems []string
+	if b.Kind == wasm.KindComponent {
+		for _, name := range b.ComponentImports {
+			if !strings.HasPrefix(name, "wasi:") && !strings.HasPrefix(name, "fastly:") {
+				problems = append(problems, fmt.Sprintf("unsupported import %s: only WASI and Fastly interfaces are provided by the platform", name))
+			}
+		}
+		return problems
+	}
+
+	modules := make(map[string][]string)
+	for _, imp := range b.Imports {
+		if imp.Module == wasiPreview1Module || strings.HasPrefix(imp.Module, "fastly_") {
+			continue
+		}
+		modules[imp.Module] = append(modules[imp.Module], imp.Name)
+	}
+	for _, module := range sortedKeys(modules) {
+		names := strings.Join(modules[module], ", ")
+		switch module {
+		case "env":
+			problems = append(problems, fmt.Sprintf("unsupported imports from env (%s): these are usually symbols that weren't defined when the binary was linked", names))
+		case "wasi_unstable":
+			problems = append(problems, fmt.Sprintf("unsupported imports from wasi_unstable (%s): the binary targets a legacy WASI version rather than %s", names, wasiPreview1Module))
+		default:
+			problems = append(problems, fmt.Sprintf("unsupported imports from %s (%s): only the %s and fastly_* host functions are provided by the platform", module, names, wasiPreview1Module))
+		}
+	}
+
+	if !b.Exported("_start", wasm.ExternalFunc) {
+		problems = append(problems, "the _start function isn't exported: it's the entrypoint called for each request")
+	}
+	if !b.Exported("memory", wasm.ExternalMemory) {
+		problems = append(problems, "the memory isn't exported: it's needed by the host functions")
+	}
+	return problems
+}
+
+// formatSize formats a size in bytes.
+func formatSize(n int) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	v := float64(n)
+	i := 0
+	for v >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
@@ -0,0 +1,114 @@
+package compute
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/wasm"
+)
+
+func TestWasmProblems(t *testing.T) {
+	valid := &wasm.Binary{
+		Imports: []wasm.Import{
+			{Module: "fastly_http_req", Name: "body_downstream_get"},
+			{Module: "wasi_snapshot_preview1", Name: "fd_write"},
+		},
+		Exports: []wasm.Export{
+			{Name: "_start", Kind: wasm.ExternalFunc},
+			{Name: "memory", Kind: wasm.ExternalMemory},
+		},
+	}
+	if problems := wasmProblems(valid); len(problems) != 0 {
+		t.Errorf("want no problems, got %q", problems)
+	}
+
+	invalid := &wasm.Binary{
+		Imports: []wasm.Import{
+			{Module: "env", Name: "emscripten_memcpy_big"},
+			{Module: "__wbindgen_placeholder__", Name: "__wbindgen_describe"},
+			{Module: "wasi_snapshot_preview1", Name: "fd_write"},
+		},
+		Exports: []wasm.Export{
+			{Name: "memory", Kind: wasm.ExternalMemory},
+		},
+	}
+	problems := wasmProblems(invalid)
+	want := []string{
+		"unsupported imports from __wbindgen_placeholder__ (__wbindgen_describe)",
+		"unsupported imports from env (emscripten_memcpy_big)",
+		"the _start function isn't exported",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("want %d problems, got %q", len(want), problems)
+	}
+	for i := range want {
+		if !strings.HasPrefix(problems[i], want[i]) {
+			t.Errorf("want problem %d to start with %q, got %q", i, want[i], problems[i])
+		}
+	}
+
+	component := &wasm.Binary{
+		Kind:             wasm.KindComponent,
+		ComponentImports: []string{"wasi:http/types@0.2.0", "fastly:api/http-req", "acme:widgets/render"},
+	}
+	if problems := wasmProblems(component); len(problems) != 1 || !strings.Contains(problems[0], "acme:widgets/render") {
+		t.Errorf("want a problem for the unsupported component import, got %q", problems)
+	}
+
+	// Names that couldn't be decoded aren't reported as unsupported imports.
+	undecoded := &wasm.Binary{
+		Kind:             wasm.KindComponent,
+		ComponentImports: []string{"wasi:http/types@0.2.0"},
+		Warnings:         []string{"unable to decode all the imports: unknown name encoding 0x2"},
+	}
+	if problems := wasmProblems(undecoded); len(problems) != 0 {
+		t.Errorf("want no problems, got %q", problems)
+	}
+}
+
+func TestWriteWasmReport(t *testing.T) {
+	b := &wasm.Binary{
+		Size: 2500,
+		Sections: []wasm.Section{
+			{ID: wasm.SectionImport, Size: 100},
+			{ID: wasm.SectionCode, Size: 2000},
+		},
+		Imports: []wasm.Import{
+			{Module: "wasi_snapshot_preview1", Name: "fd_write"},
+			{Module: "fastly_http_req", Name: "send"},
+			{Module: "fastly_http_req", Name: "new"},
+		},
+		Exports: []wasm.Export{
+			{Name: "_start", Kind: wasm.ExternalFunc},
+		},
+		Functions: []wasm.Function{
+			{Index: 3, Name: "small", Size: 10},
+			{Index: 4, Size: 1500},
+			{Index: 5, Name: "medium", Size: 490},
+		},
+	}
+
+	var buf bytes.Buffer
+	writeWasmReport(&buf, b, 2)
+	out := buf.String()
+	for _, want := range []string{
+		"core module (2.5 KB)",
+		"code    2.0 KB  80.0%",
+		"fastly_http_req (2): send, new",
+		"_start (func)",
+		"Largest functions (of 3):",
+		"1.5 KB  func[4]",
+		"490 B   medium",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want the report to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "small") {
+		t.Errorf("want only the 2 largest functions, got:\n%s", out)
+	}
+	if strings.Index(out, "code") > strings.Index(out, "import ") {
+		t.Errorf("want sections ordered by size, got:\n%s", out)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/blang/semver"
 
+	"github.com/fastly/cli/pkg/manifest"
 	"github.com/fastly/cli/pkg/revision"
 	"github.com/fastly/cli/pkg/starterkit"
 )
@@ -62,6 +63,7 @@ func NewLanguage(options *LanguageOptions) *Language {
 		options.DisplayName,
 		nil,
 		options.SourceDirectory,
+		options.Plugin,
 		options.Toolchain,
 	}
 }
@@ -72,6 +74,9 @@ type Language struct {
 	DisplayName     string
 	StarterKits     []starterkit.Kit
 	SourceDirectory string
+	// Plugin describes a language that isn't built in (nil for the built-in
+	// languages).
+	Plugin *manifest.LanguagePlugin
 
 	Toolchain
 }
@@ -81,6 +86,7 @@ type LanguageOptions struct {
 	Name            string
 	DisplayName     string
 	SourceDirectory string
+	Plugin          *manifest.LanguagePlugin
 	Toolchain       Toolchain
 }
 
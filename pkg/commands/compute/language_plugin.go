@@ -0,0 +1,412 @@
+package compute
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/manifest"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// LanguagePluginPrefix is the prefix of the name of a language plugin
+// executable, which is followed by the language name (e.g. fastly-lang-zig).
+const LanguagePluginPrefix = "fastly-lang-"
+
+// defaultPluginVersionPattern extracts the first semantic version from the
+// output of a toolchain version command.
+var defaultPluginVersionPattern = regexp.MustCompile(`(?P<version>\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.-]+)?)`)
+
+// errLanguagePluginNotFound is returned when a language is neither built in
+// nor provided by a plugin.
+var errLanguagePluginNotFound = errors.New("language plugin not found")
+
+// languagePluginName matches the names of language plugins, which are part
+// of the name of the plugin executable.
+var languagePluginName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// builtinLanguage reports whether a language is built into the CLI, in which
+// case it can't be replaced by a plugin.
+func builtinLanguage(name string) bool {
+	return slices.Contains(Languages, name)
+}
+
+// lookupLanguagePlugin returns the plugin for a language: the
+// [languages.<name>] table of the manifest if there is one, otherwise the
+// description printed by a fastly-lang-<name> executable on the PATH.
+func lookupLanguagePlugin(name string, defined map[string]manifest.LanguagePlugin) (*manifest.LanguagePlugin, error) {
+	if p, ok := defined[name]; ok {
+		return &p, nil
+	}
+	if !languagePluginName.MatchString(name) {
+		return nil, errLanguagePluginNotFound
+	}
+	path, err := exec.LookPath(LanguagePluginPrefix + name)
+	if err != nil {
+		return nil, errLanguagePluginNotFound
+	}
+	return describeLanguagePlugin(path)
+}
+
+// describeLanguagePlugin runs a plugin executable with the `describe`
+// argument and decodes the JSON description it prints.
+func describeLanguagePlugin(path string) (*manifest.LanguagePlugin, error) {
+	// gosec flagged this:
+	// G204 (CWE-78): Subprocess launched with variable
+	// Disabling as the user installed the plugin on their PATH.
+	// #nosec
+	// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
+	cmd := exec.Command(path, "describe")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running the language plugin %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	var p manifest.LanguagePlugin
+	if err := json.Unmarshal(output, &p); err != nil {
+		return nil, fmt.Errorf("error parsing the description printed by the language plugin %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// findLanguagePluginExecutables returns the names of the languages provided by
+// fastly-lang-<name> executables on the PATH, in sorted order.
+func findLanguagePluginExecutables() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), LanguagePluginPrefix)
+			if !ok || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if !languagePluginName.MatchString(name) || seen[name] || builtinLanguage(name) {
+				continue
+			}
+			if _, err := exec.LookPath(filepath.Join(dir, e.Name())); err != nil {
+				continue // not executable
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginLanguages returns the languages provided by plugins: those defined in
+// the manifest, followed by the plugin executables on the PATH. Plugins that
+// can't be described are skipped.
+func pluginLanguages(defined map[string]manifest.LanguagePlugin) []*Language {
+	var languages []*Language
+	add := func(name string, p *manifest.LanguagePlugin) {
+		displayName := p.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+		languages = append(languages, NewLanguage(&LanguageOptions{
+			Name:            name,
+			DisplayName:     displayName + " (plugin)",
+			SourceDirectory: p.SourceDirectory,
+			Plugin:          p,
+		}))
+	}
+
+	for _, name := range sortedKeys(defined) {
+		if builtinLanguage(name) {
+			continue
+		}
+		p := defined[name]
+		add(name, &p)
+	}
+	for _, name := range findLanguagePluginExecutables() {
+		if _, ok := defined[name]; ok {
+			continue
+		}
+		path, err := exec.LookPath(LanguagePluginPrefix + name)
+		if err != nil {
+			continue
+		}
+		p, err := describeLanguagePlugin(path)
+		if err != nil {
+			continue
+		}
+		add(name, p)
+	}
+	return languages
+}
+
+// NewPlugin constructs a new toolchain for a language plugin.
+func NewPlugin(
+	c *BuildCommand,
+	in io.Reader,
+	manifestFilename string,
+	out io.Writer,
+	spinner text.Spinner,
+	name string,
+	plugin *manifest.LanguagePlugin,
+) *Plugin {
+	return &Plugin{
+		Shell: Shell{},
+
+		autoYes:               c.Globals.Flags.AutoYes,
+		build:                 c.Globals.Manifest.File.Scripts.Build,
+		env:                   c.Globals.Manifest.File.Scripts.EnvVars,
+		errlog:                c.Globals.ErrLog,
+		input:                 in,
+		manifestFilename:      manifestFilename,
+		metadataFilterEnvVars: c.MetadataFilterEnvVars,
+		name:                  name,
+		nonInteractive:        c.Globals.Flags.NonInteractive,
+		output:                out,
+		plugin:                plugin,
+		postBuild:             c.Globals.Manifest.File.Scripts.PostBuild,
+		spinner:               spinner,
+		timeout:               c.Flags.Timeout,
+		verbose:               c.Globals.Verbose(),
+	}
+}
+
+// Plugin implements a Toolchain for a language plugin.
+type Plugin struct {
+	Shell
+
+	// autoYes is the --auto-yes flag.
+	autoYes bool
+	// build is a shell command defined in fastly.toml using [scripts.build].
+	build string
+	// defaultBuild indicates if the default build script was used.
+	defaultBuild bool
+	// env is environment variables to be set.
+	env []string
+	// errlog is an abstraction for recording errors to disk.
+	errlog fsterr.LogInterface
+	// input is the user's terminal stdin stream
+	input io.Reader
+	// manifestFilename is the name of the manifest file.
+	manifestFilename string
+	// metadataFilterEnvVars is a comma-separated list of user defined env vars.
+	metadataFilterEnvVars string
+	// name is the language name.
+	name string
+	// nonInteractive is the --non-interactive flag.
+	nonInteractive bool
+	// output is the users terminal stdout stream
+	output io.Writer
+	// plugin describes the language.
+	plugin *manifest.LanguagePlugin
+	// postBuild is a custom script executed after the build but before the Wasm
+	// binary is added to the .tar.gz archive.
+	postBuild string
+	// spinner is a terminal progress status indicator.
+	spinner text.Spinner
+	// timeout is the build execution threshold.
+	timeout int
+	// verbose indicates if the user set --verbose
+	verbose bool
+}
+
+// DefaultBuildScript indicates if a custom build script was used.
+func (p *Plugin) DefaultBuildScript() bool {
+	return p.defaultBuild
+}
+
+// Dependencies returns all dependencies used by the project.
+func (p *Plugin) Dependencies() map[string]string {
+	deps := make(map[string]string)
+	if p.plugin.ListDependencies == "" {
+		return deps
+	}
+	// Only stdout is parsed, as the command may log to stderr.
+	output, err := p.command(p.plugin.ListDependencies).Output()
+	if err != nil {
+		return deps
+	}
+	_ = json.Unmarshal(output, &deps)
+	return deps
+}
+
+// Build compiles the user's source code into a Wasm binary.
+func (p *Plugin) Build() error {
+	if err := p.toolchainConstraint(); err != nil {
+		return err
+	}
+	if err := p.verifyDependencies(); err != nil {
+		return err
+	}
+
+	if p.build == "" {
+		if p.plugin.Build == "" {
+			return fsterr.RemediationError{
+				Inner:       fmt.Errorf("no build command for the %s language", p.name),
+				Remediation: fmt.Sprintf("Add a [scripts.build] command to %s, or a `build` command to the %s language plugin.", p.manifestFilename, p.name),
+			}
+		}
+		p.build = p.plugin.Build
+		p.defaultBuild = true
+		if !p.verbose {
+			text.Break(p.output)
+		}
+		text.Info(p.output, "No [scripts.build] found in %s.\n\n", p.manifestFilename)
+		text.Description(p.output, fmt.Sprintf("The following default build command for %s will be used", p.name), p.build)
+	}
+
+	bt := BuildToolchain{
+		autoYes:                   p.autoYes,
+		buildFn:                   p.Shell.Build,
+		buildScript:               p.build,
+		env:                       p.env,
+		errlog:                    p.errlog,
+		in:                        p.input,
+		internalPostBuildCallback: p.runPostBuildHooks,
+		manifestFilename:          p.manifestFilename,
+		metadataFilterEnvVars:     p.metadataFilterEnvVars,
+		nonInteractive:            p.nonInteractive,
+		out:                       p.output,
+		postBuild:                 p.postBuild,
+		spinner:                   p.spinner,
+		timeout:                   p.timeout,
+		verbose:                   p.verbose,
+	}
+	return bt.Build()
+}
+
+// toolchainConstraint checks the toolchain is installed, and warns the user if
+// its version doesn't satisfy the plugin's constraint.
+//
+// NOTE: As with the built-in languages, we don't stop the build when the
+// version isn't supported as their toolchain may compile successfully.
+func (p *Plugin) toolchainConstraint() error {
+	tc := p.plugin.Toolchain
+	if tc.Version == "" {
+		return nil
+	}
+
+	output, err := p.command(tc.Version).CombinedOutput()
+	if err != nil {
+		remediation := fmt.Sprintf("Install the %s toolchain and make sure `%s` succeeds.", p.name, tc.Version)
+		if tc.Install != "" {
+			remediation = tc.Install
+		}
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("the %s toolchain isn't installed: %w", p.name, err),
+			Remediation: remediation,
+		}
+	}
+	if tc.Constraint == "" {
+		return nil
+	}
+	if p.verbose {
+		text.Info(p.output, "The Fastly CLI build step requires a %s version '%s'.\n\n", p.name, tc.Constraint)
+	}
+
+	version, err := pluginToolchainVersion(string(output), tc.VersionPattern)
+	if err != nil {
+		text.Warning(p.output, "Unable to identify the %s toolchain version: %s", p.name, err)
+		return nil
+	}
+	c, err := semver.NewConstraint(tc.Constraint)
+	if err != nil {
+		return fmt.Errorf("invalid toolchain constraint %q for the %s language: %w", tc.Constraint, p.name, err)
+	}
+	if valid, errs := c.Validate(version); !valid {
+		text.Warning(p.output, "The %s version requirement was not satisfied: %s", p.name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// pluginToolchainVersion extracts the toolchain version from the output of the
+// version command.
+func pluginToolchainVersion(output, pattern string) (*semver.Version, error) {
+	re := defaultPluginVersionPattern
+	if pattern != "" {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version pattern: %w", err)
+		}
+	}
+	i := re.SubexpIndex("version")
+	if i < 0 {
+		return nil, fmt.Errorf("the version pattern %q has no capture group named `version`", re)
+	}
+	match := re.FindStringSubmatch(output)
+	if match == nil {
+		return nil, fmt.Errorf("no version found in %q", strings.TrimSpace(output))
+	}
+	return semver.NewVersion(match[i])
+}
+
+// verifyDependencies runs the plugin's dependency check.
+func (p *Plugin) verifyDependencies() error {
+	if p.plugin.VerifyDependencies == "" {
+		return nil
+	}
+	output, err := p.command(p.plugin.VerifyDependencies).CombinedOutput()
+	if err != nil {
+		remediation := strings.TrimSpace(string(output))
+		if remediation == "" {
+			remediation = fmt.Sprintf("Install the project dependencies and make sure `%s` succeeds.", p.plugin.VerifyDependencies)
+		}
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("the %s dependencies aren't installed: %w", p.name, err),
+			Remediation: remediation,
+		}
+	}
+	if p.verbose {
+		text.Info(p.output, "Verified the %s dependencies\n", p.name)
+	}
+	return nil
+}
+
+// runPostBuildHooks runs the plugin's post build commands, which must happen
+// before the Wasm binary is checked and [scripts.post_build] is run.
+func (p *Plugin) runPostBuildHooks() error {
+	for _, hook := range p.plugin.PostBuild {
+		if p.verbose {
+			text.Description(p.output, "Running the post build hook", hook)
+		}
+		output, err := p.command(hook).CombinedOutput()
+		if p.verbose && len(output) > 0 {
+			text.Output(p.output, "%s", output)
+		}
+		if err != nil {
+			return fmt.Errorf("error running the post build hook `%s`: %w: %s", hook, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// command returns a plugin command to be run with the system shell.
+func (p *Plugin) command(command string) *exec.Cmd {
+	name, args := p.Shell.Build(command)
+	// gosec flagged this:
+	// G204 (CWE-78): Subprocess launched with function call as argument or cmd arguments
+	// Disabling as we require the user to provide this command.
+	// #nosec
+	// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), p.env...)
+	return cmd
+}
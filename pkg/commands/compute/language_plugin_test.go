@@ -0,0 +1,150 @@
+package compute
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/manifest"
+)
+
+func TestLookupLanguagePlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin executables are shell scripts")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho '{\"display_name\": \"Kotlin\", \"build\": \"gradle wasm\", \"toolchain\": {\"constraint\": \">= 2.0.0\"}}'\n"
+	if err := os.WriteFile(filepath.Join(dir, LanguagePluginPrefix+"kotlin"), []byte(script), 0o700); err != nil { // #nosec G306
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, LanguagePluginPrefix+"broken"), []byte("#!/bin/sh\necho 'not json'\n"), 0o700); err != nil { // #nosec G306
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	defined := map[string]manifest.LanguagePlugin{
+		"zig": {Build: "zig build"},
+	}
+
+	p, err := lookupLanguagePlugin("zig", defined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Build != "zig build" {
+		t.Errorf("want the manifest definition, have %+v", p)
+	}
+
+	p, err = lookupLanguagePlugin("kotlin", defined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.DisplayName != "Kotlin" || p.Build != "gradle wasm" || p.Toolchain.Constraint != ">= 2.0.0" {
+		t.Errorf("want the description printed by the plugin, have %+v", p)
+	}
+
+	if _, err := lookupLanguagePlugin("broken", defined); err == nil || errors.Is(err, errLanguagePluginNotFound) {
+		t.Errorf("want an error parsing the description, have %v", err)
+	}
+	for _, name := range []string{"swift", "../kotlin", ""} {
+		if _, err := lookupLanguagePlugin(name, defined); !errors.Is(err, errLanguagePluginNotFound) {
+			t.Errorf("%q: want errLanguagePluginNotFound, have %v", name, err)
+		}
+	}
+
+	languages := pluginLanguages(defined)
+	var names []string
+	for _, l := range languages {
+		names = append(names, l.Name)
+	}
+	if len(names) != 2 || names[0] != "zig" || names[1] != "kotlin" {
+		t.Errorf("want the zig and kotlin plugin languages, have %v", names)
+	}
+}
+
+func TestPluginToolchainVersion(t *testing.T) {
+	for _, tc := range []struct {
+		output  string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{output: "0.13.0\n", want: "0.13.0"},
+		{output: "info: kotlinc-jvm 2.0.21 (JRE 21.0.5)", want: "2.0.21"},
+		{output: "zig 0.14.0-dev.2+abc", want: "0.14.0-dev.2"},
+		{output: "tool 1.2.3 (built with go 1.22.0)", pattern: `go (?P<version>\S+)\)`, want: "1.22.0"},
+		{output: "no version here", wantErr: true},
+		{output: "1.0.0", pattern: `(\d+)`, wantErr: true},
+		{output: "1.0.0", pattern: `(`, wantErr: true},
+	} {
+		v, err := pluginToolchainVersion(tc.output, tc.pattern)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%q: want an error, have %s", tc.output, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.output, err)
+			continue
+		}
+		if v.String() != tc.want {
+			t.Errorf("%q: want %s, have %s", tc.output, tc.want, v)
+		}
+	}
+}
+
+func TestPluginCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use sh syntax")
+	}
+
+	p := &Plugin{
+		env:    []string{"DEP_VERSION=1.2.3"},
+		name:   "zig",
+		output: io.Discard,
+		plugin: &manifest.LanguagePlugin{
+			ListDependencies:   `echo '{"ziglyph": "'$DEP_VERSION'"}'; echo 'resolving' >&2`,
+			PostBuild:          []string{"echo optimised > hook.txt"},
+			VerifyDependencies: "echo 'Run zig build --fetch' && exit 1",
+			Toolchain: manifest.LanguagePluginToolchain{
+				Version: "exit 1",
+				Install: "Install Zig from https://ziglang.org/download/",
+			},
+		},
+	}
+
+	deps := p.Dependencies()
+	if deps["ziglyph"] != "1.2.3" {
+		t.Errorf("want the dependencies listed by the plugin, have %v", deps)
+	}
+
+	var re fsterr.RemediationError
+	if err := p.verifyDependencies(); !errors.As(err, &re) || re.Remediation != "Run zig build --fetch" {
+		t.Errorf("want the output of the dependency check as the remediation, have %#v", err)
+	}
+	if err := p.toolchainConstraint(); !errors.As(err, &re) || re.Remediation != p.plugin.Toolchain.Install {
+		t.Errorf("want the install instructions as the remediation, have %#v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+	if err := p.runPostBuildHooks(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("hook.txt"); err != nil {
+		t.Errorf("want the post build hook to run: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package compute
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/manifest"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// mockRoute is a parsed [[local_server.backends.<name>.mock.routes]] entry.
+type mockRoute struct {
+	method  string
+	pattern string
+	status  int
+	headers map[string]string
+	body    []byte
+	latency time.Duration
+}
+
+// matches reports whether the route applies to a request.
+func (r mockRoute) matches(req *http.Request) bool {
+	if r.method != "" && !strings.EqualFold(r.method, req.Method) {
+		return false
+	}
+	ok, _ := path.Match(r.pattern, req.URL.Path)
+	return ok
+}
+
+// mockBackend serves canned responses in place of a backend.
+type mockBackend struct {
+	name    string
+	routes  []mockRoute
+	out     io.Writer
+	verbose bool
+}
+
+// newMockBackend parses the mock configuration of a backend. Body files are
+// read relative to dir (the project directory).
+func newMockBackend(name string, m *manifest.LocalBackendMock, dir string) (*mockBackend, error) {
+	b := &mockBackend{name: name}
+	for i, r := range m.Routes {
+		route := mockRoute{
+			method:  r.Method,
+			pattern: r.Path,
+			status:  r.Status,
+			headers: r.Headers,
+			body:    []byte(r.Body),
+		}
+		if route.pattern == "" {
+			return nil, mockRouteError(name, i, errors.New("missing path"))
+		}
+		if _, err := path.Match(route.pattern, "/"); err != nil {
+			return nil, mockRouteError(name, i, fmt.Errorf("invalid path pattern %q: %w", route.pattern, err))
+		}
+		if route.status == 0 {
+			route.status = http.StatusOK
+		}
+		if r.BodyFile != "" {
+			if r.Body != "" {
+				return nil, mockRouteError(name, i, errors.New("body and body_file are mutually exclusive"))
+			}
+			bodyFile := r.BodyFile
+			if !filepath.IsAbs(bodyFile) {
+				bodyFile = filepath.Join(dir, bodyFile)
+			}
+			body, err := os.ReadFile(filepath.Clean(bodyFile))
+			if err != nil {
+				return nil, mockRouteError(name, i, fmt.Errorf("error reading body_file: %w", err))
+			}
+			route.body = body
+		}
+		if r.Latency != "" {
+			d, err := time.ParseDuration(r.Latency)
+			if err != nil {
+				return nil, mockRouteError(name, i, fmt.Errorf("invalid latency %q", r.Latency))
+			}
+			route.latency = d
+		}
+		b.routes = append(b.routes, route)
+	}
+	return b, nil
+}
+
+// mockRouteError describes an invalid route.
+func mockRouteError(backend string, i int, err error) error {
+	return fsterr.RemediationError{
+		Inner:       fmt.Errorf("invalid [[local_server.backends.%s.mock.routes]] entry %d: %w", backend, i+1, err),
+		Remediation: "Each route requires a `path` pattern (e.g. \"/api/*\") and may set `method`, `status`, `headers`, `body` or `body_file`, and `latency` (e.g. \"250ms\").",
+	}
+}
+
+// ServeHTTP responds with the first route that matches the request.
+func (b *mockBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, r := range b.routes {
+		if !r.matches(req) {
+			continue
+		}
+		if b.verbose {
+			text.Info(b.out, "Mock backend %s: %s %s (%d)", b.name, req.Method, req.URL.Path, r.status)
+		}
+		if r.latency > 0 {
+			select {
+			case <-time.After(r.latency):
+			case <-req.Context().Done():
+				return
+			}
+		}
+		for k, v := range r.headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(r.status)
+		_, _ = w.Write(r.body)
+		return
+	}
+
+	text.Warning(b.out, "Mock backend %s has no route for %s %s", b.name, req.Method, req.URL.Path)
+	http.Error(w, fmt.Sprintf("no mock route matches %s %s", req.Method, req.URL.Path), http.StatusNotFound)
+}
+
+// startMockBackends starts a stub server for each backend with a mock table,
+// and returns a copy of the backends with their URLs pointing at the stubs.
+func startMockBackends(backends map[string]manifest.LocalBackend, dir string, verbose bool, out io.Writer) (map[string]manifest.LocalBackend, func(), error) {
+	var servers []*http.Server
+	stop := func() {
+		for _, srv := range servers {
+			_ = srv.Close()
+		}
+	}
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rewritten := make(map[string]manifest.LocalBackend, len(backends))
+	for _, name := range names {
+		backend := backends[name]
+		if backend.Mock == nil {
+			rewritten[name] = backend
+			continue
+		}
+
+		mb, err := newMockBackend(name, backend.Mock, dir)
+		if err != nil {
+			stop()
+			return nil, nil, err
+		}
+		mb.out, mb.verbose = out, verbose
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			stop()
+			return nil, nil, fmt.Errorf("error starting mock backend %s: %w", name, err)
+		}
+		srv := &http.Server{
+			Handler:           mb,
+			ReadHeaderTimeout: 30 * time.Second,
+		}
+		go func() {
+			_ = srv.Serve(ln)
+		}()
+		servers = append(servers, srv)
+
+		// Viceroy doesn't know about the mock table, so it's removed.
+		backend.URL = "http://" + ln.Addr().String()
+		backend.CertHost, backend.UseSNI, backend.Mock = "", false, nil
+		rewritten[name] = backend
+		text.Info(out, "Mocking backend %s on %s", name, backend.URL)
+	}
+	return rewritten, stop, nil
+}
+
+// hasMockBackends reports whether any backend is mocked.
+func hasMockBackends(backends map[string]manifest.LocalBackend) bool {
+	for _, b := range backends {
+		if b.Mock != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMockManifest writes a copy of the manifest, with the backends replaced,
+// next to the original so that relative paths in it still resolve. The caller
+// removes the file once Viceroy has stopped.
+func writeMockManifest(m manifest.File, backends map[string]manifest.LocalBackend, manifestPath string) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(manifestPath), ".fastly.mock.*.toml")
+	if err != nil {
+		return "", fmt.Errorf("error creating manifest for mock backends: %w", err)
+	}
+	tmp := f.Name()
+	_ = f.Close()
+
+	m.LocalServer.Backends = backends
+	if err := m.Write(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("error writing manifest for mock backends: %w", err)
+	}
+	return tmp, nil
+}
@@ -0,0 +1,118 @@
+package compute
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastly/cli/pkg/manifest"
+)
+
+func TestMockBackend(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.json"), []byte(`[{"id":1}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	backends, stop, err := startMockBackends(map[string]manifest.LocalBackend{
+		"origin": {
+			URL: "https://example.com",
+			Mock: &manifest.LocalBackendMock{
+				Routes: []manifest.LocalBackendMockRoute{
+					{Method: "GET", Path: "/api/users", Headers: map[string]string{"Content-Type": "application/json"}, BodyFile: "users.json"},
+					{Path: "/api/*", Status: http.StatusTeapot, Body: "fallback"},
+				},
+			},
+		},
+		"real": {URL: "https://example.org"},
+	}, dir, false, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if got := backends["real"]; got.URL != "https://example.org" {
+		t.Errorf("want unmocked backend to be unchanged, got %+v", got)
+	}
+	origin := backends["origin"]
+	if !strings.HasPrefix(origin.URL, "http://127.0.0.1:") || origin.Mock != nil {
+		t.Fatalf("want mocked backend to point at the stub server, got %+v", origin)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		body         string
+		contentType  string
+	}{
+		{method: "GET", path: "/api/users", status: http.StatusOK, body: `[{"id":1}]`, contentType: "application/json"},
+		{method: "POST", path: "/api/users", status: http.StatusTeapot, body: "fallback"},
+		{method: "GET", path: "/other", status: http.StatusNotFound, body: "no mock route matches GET /other\n"},
+	} {
+		req, err := http.NewRequest(tc.method, origin.URL+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || string(body) != tc.body {
+			t.Errorf("%s %s: want %d %q, got %d %q", tc.method, tc.path, tc.status, tc.body, resp.StatusCode, body)
+		}
+		if tc.contentType != "" && resp.Header.Get("Content-Type") != tc.contentType {
+			t.Errorf("%s %s: want Content-Type %q, got %q", tc.method, tc.path, tc.contentType, resp.Header.Get("Content-Type"))
+		}
+	}
+	if !strings.Contains(out.String(), "Mock backend origin has no route for GET /other") {
+		t.Errorf("want a warning about the unmatched request, got: %s", out.String())
+	}
+}
+
+func TestNewMockBackend_InvalidRoutes(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		route   manifest.LocalBackendMockRoute
+		wantErr string
+	}{
+		{name: "missing path", route: manifest.LocalBackendMockRoute{Body: "x"}, wantErr: "missing path"},
+		{name: "invalid pattern", route: manifest.LocalBackendMockRoute{Path: "/["}, wantErr: `invalid path pattern "/["`},
+		{name: "invalid latency", route: manifest.LocalBackendMockRoute{Path: "/", Latency: "soon"}, wantErr: `invalid latency "soon"`},
+		{name: "missing body file", route: manifest.LocalBackendMockRoute{Path: "/", BodyFile: "missing.json"}, wantErr: "error reading body_file"},
+		{name: "body and body file", route: manifest.LocalBackendMockRoute{Path: "/", Body: "x", BodyFile: "x.json"}, wantErr: "mutually exclusive"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newMockBackend("origin", &manifest.LocalBackendMock{Routes: []manifest.LocalBackendMockRoute{tc.route}}, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want error containing %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMockBackend_Latency(t *testing.T) {
+	mb, err := newMockBackend("origin", &manifest.LocalBackendMock{
+		Routes: []manifest.LocalBackendMockRoute{{Path: "/slow", Latency: "50ms"}},
+	}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mb.out = io.Discard
+
+	srv := httptest.NewServer(mb)
+	defer srv.Close()
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	if _, err := client.Get(srv.URL + "/slow"); err == nil {
+		t.Fatal("want the response to be delayed past the client timeout")
+	}
+}
@@ -0,0 +1,341 @@
+package compute
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/revision"
+)
+
+const (
+	// provenanceBuildType identifies how the package was built.
+	provenanceBuildType = "https://github.com/fastly/cli/compute-build@v1"
+	// provenanceBuilderID identifies the builder.
+	provenanceBuilderID = "https://github.com/fastly/cli"
+	// signatureSuffix is appended to the path of a file to get the path of its
+	// detached signature.
+	signatureSuffix = ".sig"
+	// keyPairRemediation explains how to create a signing key pair.
+	keyPairRemediation = "Create a key pair with `openssl genpkey -algorithm ed25519 -out key.pem` and `openssl pkey -in key.pem -pubout -out key.pub.pem`."
+)
+
+// provenanceStatement is an in-toto statement with a SLSA provenance
+// predicate, describing how a package was built.
+//
+// Reference: https://slsa.dev/spec/v1.0/provenance
+type provenanceStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []provenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     provenancePredicate `json:"predicate"`
+}
+
+type provenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenancePredicate struct {
+	BuildDefinition provenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      provenanceRunDetails      `json:"runDetails"`
+}
+
+type provenanceBuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]any         `json:"externalParameters"`
+	InternalParameters   map[string]any         `json:"internalParameters,omitempty"`
+	ResolvedDependencies []provenanceDependency `json:"resolvedDependencies,omitempty"`
+}
+
+type provenanceDependency struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenanceRunDetails struct {
+	Builder  provenanceBuilder  `json:"builder"`
+	Metadata provenanceMetadata `json:"metadata"`
+}
+
+type provenanceBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version"`
+}
+
+type provenanceMetadata struct {
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// provenancePath returns the path of the provenance file for a package.
+func provenancePath(pkgPath string) string {
+	return strings.TrimSuffix(pkgPath, ".tar.gz") + ".provenance.json"
+}
+
+// signPackage writes the provenance file for a package, and a detached
+// signature for both the package and the provenance file. It must be called
+// from the project directory.
+func (c *BuildCommand) signPackage(pkgPath string, language *Language, manifestFilename string, started time.Time) error {
+	key, err := readSigningKey(c.Flags.SignKey)
+	if err != nil {
+		return err
+	}
+
+	pkgDigest, err := sha256File(pkgPath)
+	if err != nil {
+		return err
+	}
+	manifestDigest, err := sha256File(manifestFilename)
+	if err != nil {
+		return err
+	}
+
+	statement := provenanceStatement{
+		Type: "https://in-toto.io/Statement/v1",
+		Subject: []provenanceSubject{
+			{Name: filepath.Base(pkgPath), Digest: map[string]string{"sha256": pkgDigest}},
+		},
+		PredicateType: "https://slsa.dev/provenance/v1",
+		Predicate: provenancePredicate{
+			BuildDefinition: provenanceBuildDefinition{
+				BuildType: provenanceBuildType,
+				ExternalParameters: map[string]any{
+					"include_source": c.Flags.IncludeSrc,
+					"language":       language.Name,
+					"manifest": provenanceSubject{
+						Name:   manifestFilename,
+						Digest: map[string]string{"sha256": manifestDigest},
+					},
+					"reproducible": c.Flags.Reproducible,
+				},
+				InternalParameters: map[string]any{
+					"toolchains": toolchainVersions(language),
+				},
+			},
+			RunDetails: provenanceRunDetails{
+				Builder: provenanceBuilder{
+					ID:      provenanceBuilderID,
+					Version: map[string]string{"fastly": revision.AppVersion},
+				},
+				Metadata: provenanceMetadata{
+					StartedOn:  started.UTC(),
+					FinishedOn: time.Now().UTC(),
+				},
+			},
+		},
+	}
+	if sha := deployments.GitSHA("."); sha != "" {
+		statement.Predicate.BuildDefinition.ResolvedDependencies = []provenanceDependency{
+			{URI: gitRemoteURI(), Digest: map[string]string{"gitCommit": sha}},
+		}
+	}
+
+	data, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return err
+	}
+	provPath := provenancePath(pkgPath)
+	if err := os.WriteFile(provPath, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("error writing provenance file: %w", err)
+	}
+
+	for _, path := range []string{pkgPath, provPath} {
+		if err := signFile(key, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitRemoteURI returns the URI of the origin remote of the git repository in
+// the current directory, if there is one.
+func gitRemoteURI() string {
+	// gosec flagged this:
+	// G204 (CWE-78): Subprocess launched with variable
+	// Disabling as the arguments are fixed.
+	// #nosec
+	out, err := exec.Command("git", "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return ""
+	}
+	remote := strings.TrimSpace(string(out))
+	// Don't record any credentials embedded in the URL.
+	if u, err := url.Parse(remote); err == nil && u.User != nil {
+		u.User = nil
+		remote = u.String()
+	}
+	return "git+" + remote
+}
+
+// verifyPackage checks the detached signature of a package, and of its
+// provenance file if there is one (in which case the package digest must
+// match the provenance subject).
+func verifyPackage(keyPath, pkgPath string) error {
+	key, err := readVerifyKey(keyPath)
+	if err != nil {
+		return err
+	}
+	if err := verifyFile(key, pkgPath); err != nil {
+		return err
+	}
+
+	provPath := provenancePath(pkgPath)
+	data, err := os.ReadFile(filepath.Clean(provPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading provenance file: %w", err)
+	}
+	if err := verifyFile(key, provPath); err != nil {
+		return err
+	}
+
+	var statement provenanceStatement
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return fmt.Errorf("error parsing provenance file %s: %w", provPath, err)
+	}
+	digest, err := sha256File(pkgPath)
+	if err != nil {
+		return err
+	}
+	for _, s := range statement.Subject {
+		if s.Digest["sha256"] == digest {
+			return nil
+		}
+	}
+	return fsterr.RemediationError{
+		Inner:       fmt.Errorf("the provenance file %s doesn't describe the package %s", provPath, pkgPath),
+		Remediation: "Rebuild and sign the package with `fastly compute build --sign <key>`.",
+	}
+}
+
+// signFile writes a detached Ed25519 signature of a file, base64 encoded, to
+// the file's path with a .sig suffix.
+func signFile(key ed25519.PrivateKey, path string) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	if err := os.WriteFile(path+signatureSuffix, []byte(sig+"\n"), 0o600); err != nil {
+		return fmt.Errorf("error writing signature: %w", err)
+	}
+	return nil
+}
+
+// verifyFile checks the detached signature of a file.
+func verifyFile(key ed25519.PublicKey, path string) error {
+	sigPath := path + signatureSuffix
+	encoded, err := os.ReadFile(filepath.Clean(sigPath))
+	if err != nil {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("error reading signature: %w", err),
+			Remediation: "Sign the package with `fastly compute build --sign <key>`, which writes the signature next to the package.",
+		}
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encoded)))
+	if err != nil {
+		return fmt.Errorf("error decoding signature %s: %w", sigPath, err)
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("the signature %s doesn't match %s", sigPath, path),
+			Remediation: "The file has changed since it was signed, or was signed with a different key. Rebuild and sign the package with `fastly compute build --sign <key>`.",
+		}
+	}
+	return nil
+}
+
+// readSigningKey reads an Ed25519 private key from a PEM file (PKCS #8, as
+// created by `openssl genpkey -algorithm ed25519`).
+func readSigningKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key %s: %w", path, err)
+	}
+	key, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, keyTypeError(path)
+	}
+	return key, nil
+}
+
+// readVerifyKey reads an Ed25519 public key from a PEM file (PKIX, as created
+// by `openssl pkey -pubout`).
+func readVerifyKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	k, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key %s: %w", path, err)
+	}
+	key, ok := k.(ed25519.PublicKey)
+	if !ok {
+		return nil, keyTypeError(path)
+	}
+	return key, nil
+}
+
+// readPEM returns the content of the first PEM block of the given type.
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading key: %w", err)
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fsterr.RemediationError{
+				Inner:       fmt.Errorf("no %s PEM block found in %s", blockType, path),
+				Remediation: keyPairRemediation,
+			}
+		}
+		if block.Type == blockType {
+			return block.Bytes, nil
+		}
+	}
+}
+
+func keyTypeError(path string) error {
+	return fsterr.RemediationError{
+		Inner:       fmt.Errorf("%s isn't an Ed25519 key", path),
+		Remediation: keyPairRemediation,
+	}
+}
+
+// sha256File returns the hex encoded SHA-256 digest of a file.
+func sha256File(path string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(h, path); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,223 @@
+package compute
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/global"
+)
+
+func TestCreateReproducibleTarGz(t *testing.T) {
+	t.Setenv(SourceDateEpoch, "1700000000")
+
+	src := filepath.Join(t.TempDir(), "test")
+	for name, content := range map[string]string{
+		"fastly.toml":   "name = \"test\"\n",
+		"bin/main.wasm": "wasm",
+	} {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	build := func() []byte {
+		t.Helper()
+		dest := filepath.Join(t.TempDir(), "test.tar.gz")
+		if err := createReproducibleTarGz(src, dest); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first := build()
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(src, "fastly.toml"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(src, "bin", "main.wasm"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, build()) {
+		t.Error("want identical archives when only timestamps and modes change")
+	}
+
+	t.Setenv(SourceDateEpoch, "1800000000")
+	if bytes.Equal(first, build()) {
+		t.Errorf("want the archive to change with %s", SourceDateEpoch)
+	}
+
+	t.Setenv(SourceDateEpoch, "yesterday")
+	if err := createReproducibleTarGz(src, filepath.Join(t.TempDir(), "test.tar.gz")); err == nil {
+		t.Errorf("want an error for an invalid %s", SourceDateEpoch)
+	}
+}
+
+func TestAnnotateWasmBinaryReproducible(t *testing.T) {
+	t.Setenv(SourceDateEpoch, "1700000000")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+	if err := os.Mkdir("bin", 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("fastly.toml", []byte("name = \"test\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var c BuildCommand
+	c.Flags.Reproducible = true
+	c.Globals = &global.Data{
+		Config: config.File{
+			WasmMetadata: config.WasmMetadata{
+				BuildInfo:   "enable",
+				MachineInfo: "enable",
+				PackageInfo: "disable",
+				ScriptInfo:  "disable",
+			},
+		},
+		// Write the annotations into the binary, as wasm-tools would.
+		ExecuteWasmTools: func(_ string, args []string, _ *global.Data) error {
+			return os.WriteFile(binWasmPath, []byte("wasm "+strings.Join(args, " ")), 0o600)
+		},
+	}
+
+	build := func() []byte {
+		t.Helper()
+		if err := c.AnnotateWasmBinaryLong("wasm-tools", []string{"metadata", "add", binWasmPath}, nil); err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(t.TempDir(), "test.tar.gz")
+		if err := CreateReproduciblePackageArchive([]string{"fastly.toml", binWasmPath}, dest); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first := build()
+	// Grow the heap, which would change the build_info annotation.
+	heap := make([]byte, 64<<20)
+	second := build()
+	runtime.KeepAlive(heap)
+	if !bytes.Equal(first, second) {
+		t.Error("want identical packages from two reproducible builds")
+	}
+
+	wasm, err := os.ReadFile(binWasmPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"build_info", "machine_info"} {
+		if strings.Contains(string(wasm), field) {
+			t.Errorf("want no %s annotation in a reproducible build, got %s", field, wasm)
+		}
+	}
+}
+
+func TestSignAndVerifyPackage(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := writeKeyPair(t, dir, "key")
+	_, otherPubPath := writeKeyPair(t, dir, "other")
+
+	pkgPath := filepath.Join(dir, "test.tar.gz")
+	if err := os.WriteFile(pkgPath, []byte("package"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := readSigningKey(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signFile(key, pkgPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyPackage(pubPath, pkgPath); err != nil {
+		t.Errorf("want a valid signature, got %v", err)
+	}
+	if err := verifyPackage(otherPubPath, pkgPath); err == nil {
+		t.Error("want an error for a different key")
+	}
+	if err := verifyPackage(privPath, pkgPath); err == nil {
+		t.Error("want an error for a private key")
+	}
+
+	// A provenance file that doesn't describe the package is rejected, even when
+	// it's correctly signed.
+	provPath := provenancePath(pkgPath)
+	prov := `{"subject": [{"name": "test.tar.gz", "digest": {"sha256": "0000"}}]}`
+	if err := os.WriteFile(provPath, []byte(prov), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := signFile(key, provPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyPackage(pubPath, pkgPath); err == nil {
+		t.Error("want an error for a provenance file with a different digest")
+	}
+
+	if err := os.Remove(provPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pkgPath, []byte("tampered"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyPackage(pubPath, pkgPath); err == nil {
+		t.Error("want an error for a modified package")
+	}
+}
+
+// writeKeyPair writes an Ed25519 key pair in the PEM formats created by
+// openssl, and returns the paths of the private and public keys.
+func writeKeyPair(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath := filepath.Join(dir, name+".pem")
+	pubPath := filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privPath, pubPath
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
 	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/text"
 )
@@ -23,7 +24,10 @@ type PublishCommand struct {
 	metadataDisable       argparser.OptionalBool
 	metadataFilterEnvVars argparser.OptionalString
 	metadataShow          argparser.OptionalBool
+	noCache               argparser.OptionalBool
 	packageName           argparser.OptionalString
+	reproducible          argparser.OptionalBool
+	signKey               argparser.OptionalString
 	timeout               argparser.OptionalInt
 
 	// Deploy fields
@@ -32,15 +36,18 @@ type PublishCommand struct {
 	env                argparser.OptionalString
 	noDefaultDomain    argparser.OptionalBool
 	pkg                argparser.OptionalString
+	projectLedger      bool
 	serviceName        argparser.OptionalServiceNameID
 	serviceVersion     argparser.OptionalServiceVersion
 	statusCheckCode    int
 	statusCheckOff     bool
 	statusCheckPath    string
 	statusCheckTimeout int
+	verifyKey          argparser.OptionalString
 
 	// Publish private fields
 	projectDir string
+	workspace  workspaceOpts
 }
 
 // NewPublishCommand returns a usable command registered under the parent.
@@ -61,8 +68,11 @@ func NewPublishCommand(parent argparser.Registerer, g *global.Data, build *Build
 	c.CmdClause.Flag("metadata-disable", "Disable Wasm binary metadata annotations").Action(c.metadataDisable.Set).BoolVar(&c.metadataDisable.Value)
 	c.CmdClause.Flag("metadata-filter-envvars", "Redact specified environment variables from [scripts.env_vars] using comma-separated list").Action(c.metadataFilterEnvVars.Set).StringVar(&c.metadataFilterEnvVars.Value)
 	c.CmdClause.Flag("metadata-show", "Inspect the Wasm binary metadata").Action(c.metadataShow.Set).BoolVar(&c.metadataShow.Value)
+	c.CmdClause.Flag("no-cache", "Run the build even if nothing has changed since a package was cached by a previous build").Action(c.noCache.Set).BoolVar(&c.noCache.Value)
 	c.CmdClause.Flag("package", "Path to a package tar.gz").Short('p').Action(c.pkg.Set).StringVar(&c.pkg.Value)
 	c.CmdClause.Flag("package-name", "Package name").Action(c.packageName.Set).StringVar(&c.packageName.Value)
+	c.CmdClause.Flag("reproducible", fmt.Sprintf("Create a byte-identical package for the same files, by normalising file order, modes, ownership and timestamps (set by %s)", SourceDateEpoch)).Action(c.reproducible.Set).BoolVar(&c.reproducible.Value)
+	c.CmdClause.Flag("project-ledger", fmt.Sprintf("Also record the deployment in %s next to the manifest (always done once that file exists)", deployments.ProjectFilename)).BoolVar(&c.projectLedger)
 	c.RegisterFlag(argparser.StringFlagOpts{
 		Name:        argparser.FlagServiceIDName,
 		Description: argparser.FlagServiceIDDesc,
@@ -75,6 +85,7 @@ func NewPublishCommand(parent argparser.Registerer, g *global.Data, build *Build
 		Description: argparser.FlagServiceNameDesc,
 		Dst:         &c.serviceName.Value,
 	})
+	c.CmdClause.Flag("sign", "Path to an Ed25519 private key (PEM) used to sign the package and write a provenance attestation next to it").Action(c.signKey.Set).StringVar(&c.signKey.Value)
 	c.CmdClause.Flag("status-check-code", "Set the expected status response for the service availability check to the root path").IntVar(&c.statusCheckCode)
 	c.CmdClause.Flag("status-check-off", "Disable the service availability check").BoolVar(&c.statusCheckOff)
 	c.CmdClause.Flag("status-check-path", "Specify the URL path for the service availability check").Default("/").StringVar(&c.statusCheckPath)
@@ -86,6 +97,8 @@ func NewPublishCommand(parent argparser.Registerer, g *global.Data, build *Build
 		Action:      c.serviceVersion.Set,
 	})
 	c.CmdClause.Flag("timeout", "Timeout, in seconds, for the build compilation step").Action(c.timeout.Set).IntVar(&c.timeout.Value)
+	c.CmdClause.Flag("verify", "Path to an Ed25519 public key (PEM) used to check the package signature (created by --sign) before uploading").Action(c.verifyKey.Set).StringVar(&c.verifyKey.Value)
+	registerWorkspaceFlags(c.CmdClause, &c.workspace)
 
 	return &c
 }
@@ -98,6 +111,10 @@ func NewPublishCommand(parent argparser.Registerer, g *global.Data, build *Build
 // non-deterministic ways. It's best to leave those nested commands to handle
 // the progress indicator.
 func (c *PublishCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	if c.workspace.enabled {
+		return runWorkspace(c.Globals.Args, "publish", c.workspace, out)
+	}
+
 	wd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current working directory: %w", err)
@@ -162,6 +179,15 @@ func (c *PublishCommand) Build(in io.Reader, out io.Writer) error {
 	if c.metadataShow.WasSet {
 		c.build.MetadataShow = c.metadataShow.Value
 	}
+	if c.noCache.WasSet {
+		c.build.Flags.NoCache = c.noCache.Value
+	}
+	if c.reproducible.WasSet {
+		c.build.Flags.Reproducible = c.reproducible.Value
+	}
+	if c.signKey.WasSet {
+		c.build.Flags.SignKey = c.signKey.Value
+	}
 	if c.projectDir != "" {
 		c.build.SkipChangeDir = true // we've already changed directory
 	}
@@ -192,6 +218,9 @@ func (c *PublishCommand) Deploy(in io.Reader, out io.Writer) error {
 	if c.noDefaultDomain.WasSet {
 		c.deploy.NoDefaultDomain = c.noDefaultDomain
 	}
+	if c.projectLedger {
+		c.deploy.ProjectLedger = c.projectLedger
+	}
 	if c.comment.WasSet {
 		c.deploy.Comment = c.comment
 	}
@@ -205,6 +234,9 @@ func (c *PublishCommand) Deploy(in io.Reader, out io.Writer) error {
 		c.deploy.StatusCheckTimeout = c.statusCheckTimeout
 	}
 	c.deploy.StatusCheckPath = c.statusCheckPath
+	if c.verifyKey.WasSet {
+		c.deploy.VerifyKey = c.verifyKey.Value
+	}
 	if c.projectDir != "" {
 		c.build.SkipChangeDir = true // we've already changed directory
 	}
@@ -0,0 +1,132 @@
+package compute
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SourceDateEpoch is the standard environment variable for the timestamp
+// recorded in reproducible build output.
+//
+// Reference: https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpoch = "SOURCE_DATE_EPOCH"
+
+// reproducibleModTime returns the modification time recorded for every file
+// in a reproducible archive: SOURCE_DATE_EPOCH if set, otherwise the Unix
+// epoch.
+func reproducibleModTime() (time.Time, error) {
+	v := os.Getenv(SourceDateEpoch)
+	if v == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	secs, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be a Unix timestamp", SourceDateEpoch, v)
+	}
+	return time.Unix(secs, 0).UTC(), nil
+}
+
+// createReproducibleTarGz creates a .tar.gz archive from a directory that's
+// byte-identical for the same file contents, regardless of when, where or by
+// whom it's created.
+//
+// Entries are written in sorted order with normalised modes, modification
+// times and ownership, and the gzip header doesn't record a name or time. As
+// with createTarGz, the entries are nested in a top-level directory with the
+// name of sourceDir.
+func createReproducibleTarGz(sourceDir, destFile string) (err error) {
+	modTime, err := reproducibleModTime()
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	err = filepath.WalkDir(sourceDir, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read files from disk: %w", err)
+	}
+	sort.Strings(paths)
+
+	if err := os.MkdirAll(filepath.Dir(destFile), 0o755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	out, err := os.Create(filepath.Clean(destFile))
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+	root := filepath.Dir(sourceDir)
+	for _, path := range paths {
+		if err := addReproducibleEntry(tw, root, path, modTime); err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	return gw.Close()
+}
+
+// addReproducibleEntry writes a file or directory to the archive.
+func addReproducibleEntry(tw *tar.Writer, root, path string, modTime time.Time) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+
+	hdr := &tar.Header{
+		Name:    filepath.ToSlash(rel),
+		ModTime: modTime,
+	}
+	switch {
+	case fi.IsDir():
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name += "/"
+		hdr.Mode = 0o755
+	case fi.Mode().IsRegular():
+		hdr.Typeflag = tar.TypeReg
+		hdr.Mode = 0o644
+		hdr.Size = fi.Size()
+	default:
+		return fmt.Errorf("%s: only regular files can be packaged", path)
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if hdr.Typeflag == tar.TypeDir {
+		return nil
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close() // #nosec G307
+	_, err = io.Copy(tw, f)
+	return err
+}
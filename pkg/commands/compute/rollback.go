@@ -0,0 +1,167 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/compute/deployments"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/filesystem"
+	"github.com/fastly/cli/pkg/lookup"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// RecordDeployment adds the deployed package to the deployment ledger.
+//
+// NOTE: The deployment has already happened by the time this is called, so
+// failing to record it only results in a warning.
+func (c *DeployCommand) RecordDeployment(serviceID string, serviceVersion int, out io.Writer) {
+	hash, err := getFilesHash(c.PackagePath)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		text.Warning(out, "Unable to record the deployment: %s", err)
+		return
+	}
+	c.recordEntry(deployments.Entry{
+		ServiceID:   serviceID,
+		Version:     serviceVersion,
+		PackageHash: hash,
+		Comment:     c.Comment.Value,
+		GitSHA:      deployments.GitSHA(filepath.Dir(c.manifestPath)),
+		DeployedAt:  time.Now().UTC(),
+	}, out)
+}
+
+// recordEntry appends an entry to the local ledger, and to the project ledger
+// when it's enabled.
+func (c *DeployCommand) recordEntry(e deployments.Entry, out io.Writer) {
+	paths := []string{deployments.LocalPath(c.Globals.ConfigPath, e.ServiceID)}
+	if project := deployments.ProjectPath(c.manifestPath); c.ProjectLedger || filesystem.FileExists(project) {
+		paths = append(paths, project)
+	}
+	for _, path := range paths {
+		if err := deployments.Append(path, e); err != nil {
+			c.Globals.ErrLog.Add(err)
+			text.Warning(out, "Unable to record the deployment in %s: %s", path, err)
+		}
+	}
+}
+
+// RollbackService reactivates a previously deployed version of the service,
+// chosen from the deployment ledger.
+func (c *DeployCommand) RollbackService(spinner text.Spinner, out io.Writer) error {
+	_, s := c.Globals.Token()
+	if s == lookup.SourceUndefined {
+		return fsterr.ErrNoToken()
+	}
+
+	// The manifest is read from the current directory on startup, so it needs
+	// reading again to pick up the service ID of another project or environment.
+	if c.Dir != "" || c.Env != "" {
+		_ = c.Globals.Manifest.File.Read(c.manifestPath)
+	}
+	serviceID, source, flag, err := argparser.ServiceID(c.ServiceName, *c.Globals.Manifest, c.Globals.APIClient, c.Globals.ErrLog)
+	if err != nil {
+		return err
+	}
+	if c.Globals.Verbose() {
+		argparser.DisplayServiceID(serviceID, flag, source, out)
+	}
+
+	details, err := c.Globals.APIClient.GetServiceDetails(context.TODO(), &fastly.GetServiceDetailsInput{
+		ServiceID: serviceID,
+		Filters: []fastly.ServiceDetailsFilter{
+			{Key: "versions.active", Value: true},
+		},
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID": serviceID,
+		})
+		return fmt.Errorf("error getting service details: %w", err)
+	}
+	if details.ActiveVersion == nil {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("service %s has no active version to roll back from", serviceID),
+			Remediation: "Use `fastly service version activate` to activate a version.",
+		}
+	}
+	active := fastly.ToValue(details.ActiveVersion.Number)
+
+	// The active version might not have been deployed by the CLI, in which
+	// case its package hash isn't in the ledger.
+	var activeHash string
+	if p, err := c.getPackage(serviceID, active); err == nil && p.Metadata != nil {
+		activeHash = fastly.ToValue(p.Metadata.FilesHash)
+	}
+
+	entries, err := deployments.Load(serviceID, deployments.LocalPath(c.Globals.ConfigPath, serviceID), deployments.ProjectPath(c.manifestPath))
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	target, err := deployments.RollbackTarget(entries, active, activeHash, c.RollbackTo)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": active,
+		})
+		return err
+	}
+
+	// Only reactivate the version if it still holds the package that was
+	// deployed to it.
+	p, err := c.getPackage(serviceID, target.Version)
+	if err != nil {
+		errLogService(c.Globals.ErrLog, err, serviceID, target.Version)
+		return fmt.Errorf("error getting the package of service version %d: %w", target.Version, err)
+	}
+	if p.Metadata == nil || fastly.ToValue(p.Metadata.FilesHash) != target.PackageHash {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("the package of service version %d doesn't match the one recorded in the deployment ledger", target.Version),
+			Remediation: "Pass another version with --to, or use `fastly service version activate` to activate the version anyway.",
+		}
+	}
+
+	err = spinner.Process(fmt.Sprintf("Activating service (version %d)", target.Version), func(_ *text.SpinnerWrapper) error {
+		_, err := c.Globals.APIClient.ActivateVersion(context.TODO(), &fastly.ActivateVersionInput{
+			ServiceID:      serviceID,
+			ServiceVersion: target.Version,
+		})
+		if err != nil {
+			errLogService(c.Globals.ErrLog, err, serviceID, target.Version)
+			return fmt.Errorf("error activating version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.recordEntry(deployments.Entry{
+		ServiceID:    serviceID,
+		Version:      target.Version,
+		PackageHash:  target.PackageHash,
+		Comment:      c.Comment.Value,
+		GitSHA:       target.GitSHA,
+		DeployedAt:   time.Now().UTC(),
+		RollbackFrom: active,
+	}, out)
+
+	text.Success(out, "Rolled back service %s from version %d to version %d", serviceID, active, target.Version)
+	return nil
+}
+
+// getPackage returns the package uploaded to a service version.
+func (c *DeployCommand) getPackage(serviceID string, serviceVersion int) (*fastly.Package, error) {
+	return c.Globals.APIClient.GetPackage(context.TODO(), &fastly.GetPackageInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+	})
+}
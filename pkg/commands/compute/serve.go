@@ -53,6 +53,7 @@ type ServeCommand struct {
 	metadataDisable       argparser.OptionalBool
 	metadataFilterEnvVars argparser.OptionalString
 	metadataShow          argparser.OptionalBool
+	noCache               argparser.OptionalBool
 	packageName           argparser.OptionalString
 	timeout               argparser.OptionalInt
 
@@ -64,7 +65,10 @@ type ServeCommand struct {
 
 	// Serve private fields
 	addr                 string
+	background           func(addr string) error
+	beforeRun            func(out io.Writer) error
 	debug                bool
+	debugPort            int
 	enablePushpin        bool
 	pushpinRunnerBinPath string
 	pushpinProxyPort     string
@@ -74,6 +78,8 @@ type ServeCommand struct {
 	profileGuest         bool
 	profileGuestDir      argparser.OptionalString
 	projectDir           string
+	record               string
+	replay               string
 	skipBuild            bool
 	watch                bool
 	watchDir             argparser.OptionalString
@@ -88,7 +94,7 @@ func NewServeCommand(parent argparser.Registerer, g *global.Data, build *BuildCo
 	c.CmdClause = parent.Command("serve", "Build and run a Compute package locally")
 
 	c.CmdClause.Flag("addr", "The IPv4 address and port to listen on").Default("127.0.0.1:7676").StringVar(&c.addr)
-	c.CmdClause.Flag("debug", "Run the server in Debug Adapter mode").Hidden().BoolVar(&c.debug)
+	c.CmdClause.Flag("debug", "Run the server in Debug Adapter mode (deprecated: use `compute debug`)").Hidden().BoolVar(&c.debug)
 	c.CmdClause.Flag("dir", "Project directory to build (default: current directory)").Short('C').Action(c.dir.Set).StringVar(&c.dir.Value)
 	c.CmdClause.Flag("env", "The manifest environment config to use (e.g. 'stage' will attempt to read 'fastly.stage.toml')").Action(c.env.Set).StringVar(&c.env.Value)
 	c.CmdClause.Flag("file", "The Wasm file to run (causes build process to be skipped)").Action(c.file.Set).StringVar(&c.file.Value)
@@ -97,6 +103,7 @@ func NewServeCommand(parent argparser.Registerer, g *global.Data, build *BuildCo
 	c.CmdClause.Flag("metadata-disable", "Disable Wasm binary metadata annotations").Action(c.metadataDisable.Set).BoolVar(&c.metadataDisable.Value)
 	c.CmdClause.Flag("metadata-filter-envvars", "Redact specified environment variables from [scripts.env_vars] using comma-separated list").Action(c.metadataFilterEnvVars.Set).StringVar(&c.metadataFilterEnvVars.Value)
 	c.CmdClause.Flag("metadata-show", "Inspect the Wasm binary metadata").Action(c.metadataShow.Set).BoolVar(&c.metadataShow.Value)
+	c.CmdClause.Flag("no-cache", "Run the build even if nothing has changed since a package was cached by a previous build").Action(c.noCache.Set).BoolVar(&c.noCache.Value)
 	c.CmdClause.Flag("package-name", "Package name").Action(c.packageName.Set).StringVar(&c.packageName.Value)
 	c.CmdClause.Flag("experimental-enable-pushpin", "Enable experimental Pushpin support for local testing of Fanout").BoolVar(&c.enablePushpin)
 	c.CmdClause.Flag("pushpin-path", "The path to a user installed version of the Pushpin runner binary").StringVar(&c.pushpinRunnerBinPath)
@@ -104,6 +111,8 @@ func NewServeCommand(parent argparser.Registerer, g *global.Data, build *BuildCo
 	c.CmdClause.Flag("pushpin-publish-port", "The port to run the Pushpin publish handler on. Overrides 'local_server.pushpin.publish_port' from 'fastly.toml', and if not specified there, defaults to 5561.").StringVar(&c.pushpinPublishPort)
 	c.CmdClause.Flag("profile-guest", "Profile the Wasm guest under Viceroy (requires Viceroy 0.9.1 or higher). View profiles at https://profiler.firefox.com/.").BoolVar(&c.profileGuest)
 	c.CmdClause.Flag("profile-guest-dir", "The directory where the per-request profiles are saved to. Defaults to guest-profiles.").Action(c.profileGuestDir.Set).StringVar(&c.profileGuestDir.Value)
+	c.CmdClause.Flag("record", "Record the requests and responses passing through the local server to a directory").StringVar(&c.record)
+	c.CmdClause.Flag("replay", "Replay the requests recorded in a directory against the local server and report responses that differ from the recordings").StringVar(&c.replay)
 	c.CmdClause.Flag("skip-build", "Skip the build step").BoolVar(&c.skipBuild)
 	c.CmdClause.Flag("timeout", "Timeout, in seconds, for the build compilation step").Action(c.timeout.Set).IntVar(&c.timeout.Value)
 	c.CmdClause.Flag("viceroy-args", "Additional arguments to pass to the Viceroy binary, separated by space").StringVar(&c.ViceroyBinExtraArgs)
@@ -120,6 +129,22 @@ func (c *ServeCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	if c.skipBuild && c.watch {
 		return fsterr.ErrIncompatibleServeFlags
 	}
+	if c.record != "" && c.replay != "" {
+		return fsterr.RemediationError{
+			Inner:       errors.New("--record shouldn't be used with --replay"),
+			Remediation: "Record the traffic with --record, then replay it in a separate run with --replay.",
+		}
+	}
+	if c.replay != "" && (c.watch || c.debug) {
+		return fsterr.RemediationError{
+			Inner:       errors.New("--replay shouldn't be used with --watch or --debug"),
+			Remediation: "The local server is stopped once the recorded traffic has been replayed.",
+		}
+	}
+
+	if c.debug && c.debugPort == 0 {
+		text.Warning(out, "The --debug flag is deprecated and will be removed in a future release. Use `fastly compute debug`, which also builds with debug information and prints a VS Code launch configuration.\n\n")
+	}
 
 	if runtime.GOARCH == "386" {
 		return fsterr.RemediationError{
@@ -240,25 +265,72 @@ func (c *ServeCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		text.Break(out)
 	}
 
-	var restart bool
-	for {
-		err = local(localOpts{
-			addr:             c.addr,
-			bin:              bin,
-			debug:            c.debug,
-			errLog:           c.Globals.ErrLog,
-			extraArgs:        c.ViceroyBinExtraArgs,
-			manifestPath:     manifestPath,
-			out:              out,
-			profileGuest:     c.profileGuest,
-			profileGuestDir:  c.profileGuestDir,
-			pushpinProxyPort: pushpinCtx.proxyPort,
-			restarted:        restart,
-			verbose:          c.Globals.Verbose(),
-			wasmBinPath:      wasmBinaryToRun,
-			watch:            c.watch,
-			watchDir:         c.watchDir,
+	// Viceroy reads the backends from the manifest, so mocked backends are
+	// rewritten in a copy of it.
+	viceroyManifestPath := manifestPath
+	if backends := c.Globals.Manifest.File.LocalServer.Backends; hasMockBackends(backends) {
+		backends, stop, err := startMockBackends(backends, filepath.Dir(manifestPath), c.Globals.Verbose(), out)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		defer stop()
+		viceroyManifestPath, err = writeMockManifest(c.Globals.Manifest.File, backends, manifestPath)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		defer os.Remove(viceroyManifestPath)
+	}
+
+	opts := localOpts{
+		addr:             c.addr,
+		bin:              bin,
+		debug:            c.debug,
+		debugPort:        c.debugPort,
+		errLog:           c.Globals.ErrLog,
+		extraArgs:        c.ViceroyBinExtraArgs,
+		manifestPath:     viceroyManifestPath,
+		out:              out,
+		profileGuest:     c.profileGuest,
+		profileGuestDir:  c.profileGuestDir,
+		pushpinProxyPort: pushpinCtx.proxyPort,
+		verbose:          c.Globals.Verbose(),
+		wasmBinPath:      wasmBinaryToRun,
+		watch:            c.watch,
+		watchDir:         c.watchDir,
+	}
+
+	if c.beforeRun != nil {
+		if err := c.beforeRun(out); err != nil {
+			return err
+		}
+	}
+
+	if c.replay != "" {
+		return replayLocal(opts, c.replay)
+	}
+	if c.background != nil {
+		return localBackground(opts, func() error {
+			return c.background(opts.addr)
 		})
+	}
+
+	if c.record != "" {
+		// Viceroy listens on another address, behind a proxy that records the
+		// traffic sent to --addr.
+		upstream, stop, err := startRecording(c.addr, c.record, out)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		defer stop()
+		opts.addr = upstream
+		text.Info(out, "Recording traffic sent to http://%s in %s", c.addr, c.record)
+	}
+
+	for {
+		err = local(opts)
 		if err != nil {
 			if err != fsterr.ErrViceroyRestart {
 				if err == fsterr.ErrSignalInterrupt || err == fsterr.ErrSignalKilled {
@@ -277,7 +349,7 @@ func (c *ServeCommand) Exec(in io.Reader, out io.Writer) (err error) {
 				// rebuild successfully once the user has fixed the issues.
 				fsterr.Deduce(err).Print(color.Error)
 			}
-			restart = true
+			opts.restarted = true
 		}
 	}
 }
@@ -312,6 +384,9 @@ func (c *ServeCommand) Build(in io.Reader, out io.Writer) error {
 	if c.metadataShow.WasSet {
 		c.build.MetadataShow = c.metadataShow.Value
 	}
+	if c.noCache.WasSet {
+		c.build.Flags.NoCache = c.noCache.Value
+	}
 	if c.projectDir != "" {
 		c.build.SkipChangeDir = true // we've already changed directory
 	}
@@ -823,6 +898,7 @@ type localOpts struct {
 	addr             string
 	bin              string
 	debug            bool
+	debugPort        int
 	errLog           fsterr.LogInterface
 	extraArgs        string
 	manifestPath     string
@@ -837,8 +913,8 @@ type localOpts struct {
 	watchDir         argparser.OptionalString
 }
 
-// local spawns a subprocess that runs the compiled binary.
-func local(opts localOpts) error {
+// viceroyArgs returns the arguments to run Viceroy with.
+func viceroyArgs(opts localOpts) []string {
 	// NOTE: Viceroy no longer displays errors unless in verbose mode.
 	// This can cause confusion for customers: https://github.com/fastly/cli/issues/913
 	// So regardless of CLI --verbose flag we'll always set verbose for Viceroy.
@@ -846,6 +922,9 @@ func local(opts localOpts) error {
 
 	if opts.debug {
 		args = append(args, "--debug")
+		if opts.debugPort != 0 {
+			args = append(args, fmt.Sprintf("--debug-port=%d", opts.debugPort))
+		}
 	}
 
 	if opts.profileGuest {
@@ -868,6 +947,13 @@ func local(opts localOpts) error {
 		args = append(args, extraArgs...)
 	}
 
+	return args
+}
+
+// local spawns a subprocess that runs the compiled binary.
+func local(opts localOpts) error {
+	args := viceroyArgs(opts)
+
 	if opts.verbose {
 		if opts.restarted {
 			text.Break(opts.out)
@@ -990,6 +1076,72 @@ func local(opts localOpts) error {
 	return nil
 }
 
+// localBackground starts Viceroy in the background, calls run once it's
+// accepting requests, and then stops it again.
+func localBackground(opts localOpts, run func() error) error {
+	s := &fstexec.Streaming{
+		Args:        viceroyArgs(opts),
+		Command:     opts.bin,
+		Env:         os.Environ(),
+		ForceOutput: opts.verbose,
+		Output:      opts.out,
+		Verbose:     opts.verbose,
+	}
+	// done is closed when Viceroy exits, after execErr is set, so that both
+	// waitForServer and the deferred cleanup can observe the exit.
+	var execErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		execErr = s.Exec()
+	}()
+	defer func() {
+		_ = s.Signal(os.Kill)
+		<-done
+	}()
+
+	if err := waitForServer(opts.addr, done, &execErr, 30*time.Second); err != nil {
+		opts.errLog.Add(err)
+		return err
+	}
+	return run()
+}
+
+// freeLocalAddr returns an address on the loopback interface with a port that
+// isn't in use, for running Viceroy where it doesn't need a known address.
+func freeLocalAddr() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("error finding a port for the local server: %w", err)
+	}
+	addr := ln.Addr().String()
+	return addr, ln.Close()
+}
+
+// waitForServer waits for the server to accept connections on addr. When done
+// is closed the server has exited with the error stored in execErr.
+func waitForServer(addr string, done <-chan struct{}, execErr *error, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+		select {
+		case <-done:
+			err := *execErr
+			if err == nil {
+				err = errors.New("process exited")
+			}
+			return fmt.Errorf("the local server stopped before it started listening on %s: %w", addr, err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the local server to listen on %s", addr)
+		}
+	}
+}
+
 // watchFiles watches the language source directory and restarts the viceroy
 // executable when changes are detected.
 func watchFiles(root string, gi *ignore.GitIgnore, verbose bool, s *fstexec.Streaming, out io.Writer, restart chan<- bool, failure chan<- error) {
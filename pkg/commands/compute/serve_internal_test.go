@@ -1,9 +1,16 @@
 package compute
 
 import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
 )
 
 // TestIsContentChange checks that content/existence events trigger a rebuild and
@@ -30,3 +37,31 @@ func TestIsContentChange(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalBackgroundServerExits(t *testing.T) {
+	addr, err := freeLocalAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := localOpts{
+		addr:   addr,
+		bin:    filepath.Join(t.TempDir(), "viceroy"), // doesn't exist
+		errLog: fsterr.MockLog{},
+		out:    io.Discard,
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- localBackground(opts, func() error {
+			return errors.New("run shouldn't be called")
+		})
+	}()
+	select {
+	case err := <-result:
+		if err == nil || !strings.Contains(err.Error(), "stopped before it started listening") {
+			t.Errorf("want an error as the server stopped, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for localBackground to return")
+	}
+}
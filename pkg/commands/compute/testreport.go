@@ -0,0 +1,131 @@
+package compute
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fastly/cli/pkg/text"
+)
+
+// writeTextReport writes a human readable test report.
+func writeTextReport(w io.Writer, results []testResult) {
+	var failed int
+	for _, r := range results {
+		if len(r.failures) == 0 {
+			text.Output(w, "%s %s (%s)", text.BoldGreen("PASS"), r.name, r.duration.Round(time.Millisecond))
+			continue
+		}
+		failed++
+		text.Output(w, "%s %s (%s)", text.BoldRed("FAIL"), r.name, r.duration.Round(time.Millisecond))
+		for _, f := range r.failures {
+			text.Output(w, "     %s", f)
+		}
+	}
+	text.Break(w)
+	text.Output(w, "%d passed, %d failed", len(results)-failed, failed)
+}
+
+// writeTAPReport writes a report in the Test Anything Protocol (version 13).
+func writeTAPReport(w io.Writer, results []testResult) {
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(results))
+	for i, r := range results {
+		if len(r.failures) == 0 {
+			fmt.Fprintf(w, "ok %d - %s\n", i+1, r.name)
+			continue
+		}
+		fmt.Fprintf(w, "not ok %d - %s\n", i+1, r.name)
+		fmt.Fprintln(w, "  ---")
+		fmt.Fprintf(w, "  file: %q\n", r.suite)
+		fmt.Fprintln(w, "  failures:")
+		for _, f := range r.failures {
+			fmt.Fprintf(w, "    - %q\n", f)
+		}
+		fmt.Fprintln(w, "  ...")
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes a report in the JUnit XML format, with a test suite
+// per test file.
+func writeJUnitReport(w io.Writer, results []testResult) error {
+	var report junitTestSuites
+	index := make(map[string]int)
+	var durations []time.Duration
+	for _, r := range results {
+		i, ok := index[r.suite]
+		if !ok {
+			i = len(report.Suites)
+			index[r.suite] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: r.suite})
+			durations = append(durations, 0)
+		}
+		s := &report.Suites[i]
+
+		tc := junitTestCase{
+			Name:      r.name,
+			ClassName: r.suite,
+			Time:      junitTime(r.duration),
+		}
+		if len(r.failures) > 0 {
+			tc.Failure = &junitFailure{
+				Message: r.failures[0],
+				Text:    strings.Join(r.failures, "\n"),
+			}
+			s.Failures++
+			report.Failures++
+		}
+		s.Cases = append(s.Cases, tc)
+		s.Tests++
+		report.Tests++
+		durations[i] += r.duration
+	}
+	for i, d := range durations {
+		report.Suites[i].Time = junitTime(d)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitTime formats a duration in seconds.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,403 @@
+package compute
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// Test reporters.
+const (
+	testReporterText  = "text"
+	testReporterTAP   = "tap"
+	testReporterJUnit = "junit"
+)
+
+// TestCommand builds a Compute package, runs it locally and sends it the
+// requests declared in the test files, checking the responses.
+type TestCommand struct {
+	argparser.Base
+	serve *ServeCommand
+
+	// Serve fields
+	dir                 argparser.OptionalString
+	env                 argparser.OptionalString
+	file                argparser.OptionalString
+	skipBuild           bool
+	viceroyBinExtraArgs string
+	viceroyBinPath      string
+
+	// Test fields
+	reportFile string
+	reporter   string
+	run        string
+	tests      string
+}
+
+// NewTestCommand returns a usable command registered under the parent.
+func NewTestCommand(parent argparser.Registerer, g *global.Data, serve *ServeCommand) *TestCommand {
+	var c TestCommand
+	c.Globals = g
+	c.serve = serve
+	c.CmdClause = parent.Command("test", "Build and run a Compute package locally, and check its responses to the requests declared in test files")
+
+	c.CmdClause.Flag("dir", "Project directory to build (default: current directory)").Short('C').Action(c.dir.Set).StringVar(&c.dir.Value)
+	c.CmdClause.Flag("env", "The manifest environment config to use (e.g. 'stage' will attempt to read 'fastly.stage.toml')").Action(c.env.Set).StringVar(&c.env.Value)
+	c.CmdClause.Flag("file", "The Wasm file to run (causes build process to be skipped)").Action(c.file.Set).StringVar(&c.file.Value)
+	c.CmdClause.Flag("report-file", "Write the test report to a file instead of stdout").StringVar(&c.reportFile)
+	c.CmdClause.Flag("reporter", "The format of the test report").Default(testReporterText).HintOptions(testReporterText, testReporterTAP, testReporterJUnit).EnumVar(&c.reporter, testReporterText, testReporterTAP, testReporterJUnit)
+	c.CmdClause.Flag("run", "Only run the tests whose names match the regular expression").StringVar(&c.run)
+	c.CmdClause.Flag("skip-build", "Skip the build step").BoolVar(&c.skipBuild)
+	c.CmdClause.Flag("tests", "The directory containing the test files (*.toml), relative to the project directory").Default("tests").StringVar(&c.tests)
+	c.CmdClause.Flag("viceroy-args", "Additional arguments to pass to the Viceroy binary, separated by space").StringVar(&c.viceroyBinExtraArgs)
+	c.CmdClause.Flag("viceroy-path", "The path to a user installed version of the Viceroy binary").StringVar(&c.viceroyBinPath)
+
+	return &c
+}
+
+// Exec implements the command interface.
+func (c *TestCommand) Exec(in io.Reader, out io.Writer) error {
+	testsDir := c.tests
+	if c.dir.WasSet && !filepath.IsAbs(testsDir) {
+		testsDir = filepath.Join(c.dir.Value, testsDir)
+	}
+	suites, err := loadTestSuites(testsDir, c.run)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	addr, err := freeLocalAddr()
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	// Reset the fields on the ServeCommand based on TestCommand values.
+	c.serve.addr = addr
+	c.serve.dir = c.dir
+	c.serve.env = c.env
+	c.serve.file = c.file
+	c.serve.skipBuild = c.skipBuild
+	c.serve.ViceroyBinExtraArgs = c.viceroyBinExtraArgs
+	c.serve.ViceroyBinPath = c.viceroyBinPath
+
+	var results []testResult
+	c.serve.background = func(addr string) error {
+		results = runTestSuites(addr, suites)
+		return nil
+	}
+	if err := c.serve.Exec(in, c.serveOutput(out)); err != nil {
+		return err
+	}
+
+	report := out
+	if c.reportFile != "" {
+		f, err := os.Create(c.reportFile)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return fmt.Errorf("error creating test report: %w", err)
+		}
+		defer f.Close() // #nosec G307
+		report = f
+	} else if c.reporter == testReporterText {
+		text.Break(out)
+	}
+
+	switch c.reporter {
+	case testReporterTAP:
+		writeTAPReport(report, results)
+	case testReporterJUnit:
+		err = writeJUnitReport(report, results)
+	default:
+		writeTextReport(report, results)
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error writing test report: %w", err)
+	}
+
+	var failed int
+	for _, r := range results {
+		if len(r.failures) > 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(results))
+	}
+	if c.reportFile != "" {
+		text.Success(out, "All %d tests passed", len(results))
+	}
+	return nil
+}
+
+// serveOutput returns where the build and Viceroy output is written. The TAP
+// and JUnit reports are read by other tools, so when they're written to stdout
+// everything else goes to stderr.
+func (c *TestCommand) serveOutput(out io.Writer) io.Writer {
+	if c.reportFile == "" && c.reporter != testReporterText && c.Globals.ErrOutput != nil {
+		return c.Globals.ErrOutput
+	}
+	return out
+}
+
+// testSuite is a test file.
+type testSuite struct {
+	// file is the path of the test file.
+	file  string
+	Tests []testCase `toml:"test"`
+}
+
+// testCase is a [[test]] entry of a test file.
+type testCase struct {
+	Name    string      `toml:"name"`
+	Request testRequest `toml:"request"`
+	Expect  testExpect  `toml:"expect"`
+}
+
+// testRequest is the request sent by a test.
+type testRequest struct {
+	// Method is the request method (default: GET).
+	Method string `toml:"method"`
+	// Path is the request path, including any query string.
+	Path string `toml:"path"`
+	// Host overrides the Host header.
+	Host    string            `toml:"host"`
+	Headers map[string]string `toml:"headers"`
+	Body    string            `toml:"body"`
+}
+
+// testExpect is the expected response of a test.
+type testExpect struct {
+	// Status is the expected status code (default: 200).
+	Status int `toml:"status"`
+	// Headers are header values that must match exactly.
+	Headers map[string]string `toml:"headers"`
+	// HeadersMatch are regular expressions that header values must match.
+	HeadersMatch map[string]string `toml:"headers_match"`
+	// HeadersAbsent are headers that mustn't be present.
+	HeadersAbsent []string `toml:"headers_absent"`
+	// Body is the exact expected body.
+	Body string `toml:"body"`
+	// BodyContains are strings the body must contain.
+	BodyContains []string `toml:"body_contains"`
+	// BodyMatches is a regular expression the body must match.
+	BodyMatches string `toml:"body_matches"`
+}
+
+// testResult is the outcome of a test.
+type testResult struct {
+	suite    string
+	name     string
+	failures []string
+	duration time.Duration
+}
+
+// loadTestSuites reads the test files in dir, keeping only the tests whose
+// names match the run expression (if given).
+func loadTestSuites(dir, run string) ([]testSuite, error) {
+	var filter *regexp.Regexp
+	if run != "" {
+		var err error
+		if filter, err = regexp.Compile(run); err != nil {
+			return nil, fmt.Errorf("error parsing --run: %w", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var suites []testSuite
+	var count int
+	for _, file := range files {
+		s, err := readTestSuite(file)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			tests := s.Tests[:0]
+			for _, t := range s.Tests {
+				if filter.MatchString(t.Name) {
+					tests = append(tests, t)
+				}
+			}
+			s.Tests = tests
+		}
+		if len(s.Tests) > 0 {
+			suites = append(suites, s)
+			count += len(s.Tests)
+		}
+	}
+	if count == 0 {
+		return nil, fsterr.RemediationError{
+			Inner:       fmt.Errorf("no tests found in %s", dir),
+			Remediation: "Declare tests as [[test]] entries in *.toml files in the tests directory (see --tests), each with a name, [test.request] and [test.expect] table.",
+		}
+	}
+	return suites, nil
+}
+
+// readTestSuite reads and validates a test file.
+func readTestSuite(file string) (testSuite, error) {
+	s := testSuite{file: file}
+	tree, err := toml.LoadFile(file)
+	if err != nil {
+		return s, fmt.Errorf("error reading test file %s: %w", file, err)
+	}
+	if err := tree.Unmarshal(&s); err != nil {
+		return s, fmt.Errorf("error parsing test file %s: %w", file, err)
+	}
+
+	for i := range s.Tests {
+		t := &s.Tests[i]
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("%s #%d", strings.TrimSuffix(filepath.Base(file), ".toml"), i+1)
+		}
+		if !strings.HasPrefix(t.Request.Path, "/") {
+			return s, fmt.Errorf("invalid test %q in %s: request path %q must start with /", t.Name, file, t.Request.Path)
+		}
+		for name, expr := range t.Expect.HeadersMatch {
+			if _, err := regexp.Compile(expr); err != nil {
+				return s, fmt.Errorf("invalid test %q in %s: headers_match for %s: %w", t.Name, file, name, err)
+			}
+		}
+		if _, err := regexp.Compile(t.Expect.BodyMatches); err != nil {
+			return s, fmt.Errorf("invalid test %q in %s: body_matches: %w", t.Name, file, err)
+		}
+	}
+	return s, nil
+}
+
+// runTestSuites runs the tests against the server at addr.
+func runTestSuites(addr string, suites []testSuite) []testResult {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: time.Minute,
+	}
+
+	var results []testResult
+	for _, s := range suites {
+		for _, t := range s.Tests {
+			start := time.Now()
+			failures := runTest(client, addr, t)
+			results = append(results, testResult{
+				suite:    s.file,
+				name:     t.Name,
+				failures: failures,
+				duration: time.Since(start),
+			})
+		}
+	}
+	return results
+}
+
+// runTest sends a test's request and returns the ways in which the response
+// doesn't meet the expectations.
+func runTest(client *http.Client, addr string, t testCase) []string {
+	method := t.Request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, "http://"+addr+t.Request.Path, strings.NewReader(t.Request.Body))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	for k, v := range t.Request.Headers {
+		req.Header.Set(k, v)
+	}
+	if t.Request.Host != "" {
+		req.Host = t.Request.Host
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer resp.Body.Close() // #nosec G307
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return []string{fmt.Sprintf("error reading response body: %s", err)}
+	}
+	return checkResponse(t.Expect, resp, body)
+}
+
+// checkResponse checks a response against the expectations of a test.
+func checkResponse(e testExpect, resp *http.Response, body []byte) []string {
+	var failures []string
+
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if resp.StatusCode != status {
+		failures = append(failures, fmt.Sprintf("status: expected %d, got %d", status, resp.StatusCode))
+	}
+
+	for _, name := range sortedKeys(e.Headers) {
+		if got := strings.Join(resp.Header.Values(name), ", "); got != e.Headers[name] {
+			failures = append(failures, fmt.Sprintf("header %s: expected %q, got %q", name, e.Headers[name], got))
+		}
+	}
+	for _, name := range sortedKeys(e.HeadersMatch) {
+		got := strings.Join(resp.Header.Values(name), ", ")
+		if !regexp.MustCompile(e.HeadersMatch[name]).MatchString(got) {
+			failures = append(failures, fmt.Sprintf("header %s: expected a match for %q, got %q", name, e.HeadersMatch[name], got))
+		}
+	}
+	for _, name := range e.HeadersAbsent {
+		if got := resp.Header.Values(name); len(got) > 0 {
+			failures = append(failures, fmt.Sprintf("header %s: expected no header, got %q", name, strings.Join(got, ", ")))
+		}
+	}
+
+	if e.Body != "" && string(body) != e.Body {
+		failures = append(failures, fmt.Sprintf("body: expected %q, got %q", e.Body, truncate(body)))
+	}
+	for _, s := range e.BodyContains {
+		if !bytes.Contains(body, []byte(s)) {
+			failures = append(failures, fmt.Sprintf("body: expected to contain %q, got %q", s, truncate(body)))
+		}
+	}
+	if e.BodyMatches != "" && !regexp.MustCompile(e.BodyMatches).Match(body) {
+		failures = append(failures, fmt.Sprintf("body: expected a match for %q, got %q", e.BodyMatches, truncate(body)))
+	}
+	return failures
+}
+
+// truncate shortens a body for display in a failure message.
+func truncate(body []byte) string {
+	const limit = 200
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+	return string(body)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,186 @@
+package compute
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastly/cli/pkg/global"
+)
+
+const testSuiteTOML = `
+[[test]]
+name = "homepage"
+[test.request]
+path = "/"
+[test.expect]
+headers = { content-type = "text/html" }
+body_contains = ["Welcome"]
+
+[[test]]
+name = "post echoes the body"
+[test.request]
+method = "POST"
+path = "/echo?x=1"
+host = "example.com"
+headers = { x-test = "yes" }
+body = "hello"
+[test.expect]
+status = 201
+headers_match = { x-host = "^example\\.com$" }
+headers_absent = ["x-debug"]
+body = "hello"
+
+[[test]]
+name = "missing page"
+[test.request]
+path = "/missing"
+[test.expect]
+status = 404
+body_matches = "not (found|here)"
+`
+
+func TestRunTestSuites(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<h1>Welcome</h1>")
+		case "/echo":
+			w.Header().Set("X-Host", r.Host)
+			if r.Header.Get("X-Test") != "yes" || r.URL.RawQuery != "x=1" {
+				w.Header().Set("X-Debug", "missing request details")
+			}
+			w.WriteHeader(http.StatusCreated)
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(body)
+		default:
+			http.Error(w, "page found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "site.toml"), []byte(testSuiteTOML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	suites, err := loadTestSuites(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := runTestSuites(strings.TrimPrefix(srv.URL, "http://"), suites)
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(results))
+	}
+	for _, r := range results[:2] {
+		if len(r.failures) > 0 {
+			t.Errorf("want %q to pass, got failures: %v", r.name, r.failures)
+		}
+	}
+	want := []string{`body: expected a match for "not (found|here)", got "page found\n"`}
+	if got := results[2].failures; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want failures %q, got %q", want, got)
+	}
+
+	filtered, err := loadTestSuites(dir, "^post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || len(filtered[0].Tests) != 1 || filtered[0].Tests[0].Name != "post echoes the body" {
+		t.Errorf("unexpected tests after filtering: %+v", filtered)
+	}
+}
+
+func TestLoadTestSuites_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		toml    string
+		wantErr string
+	}{
+		{name: "no tests", toml: "", wantErr: "no tests found"},
+		{name: "relative path", toml: "[[test]]\n[test.request]\npath = \"index.html\"\n", wantErr: `request path "index.html" must start with /`},
+		{name: "invalid regexp", toml: "[[test]]\n[test.request]\npath = \"/\"\n[test.expect]\nbody_matches = \"(\"\n", wantErr: "body_matches"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "site.toml"), []byte(tc.toml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadTestSuites(dir, "")
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want error containing %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTestReports(t *testing.T) {
+	results := []testResult{
+		{suite: "tests/a.toml", name: "first", duration: 1500 * time.Millisecond},
+		{suite: "tests/a.toml", name: "second", failures: []string{"status: expected 200, got 500", "body: expected \"ok\", got \"\""}, duration: 500 * time.Millisecond},
+		{suite: "tests/b.toml", name: "third", duration: 250 * time.Millisecond},
+	}
+
+	var tap bytes.Buffer
+	writeTAPReport(&tap, results)
+	wantTAP := `TAP version 13
+1..3
+ok 1 - first
+not ok 2 - second
+  ---
+  file: "tests/a.toml"
+  failures:
+    - "status: expected 200, got 500"
+    - "body: expected \"ok\", got \"\""
+  ...
+ok 3 - third
+`
+	if tap.String() != wantTAP {
+		t.Errorf("unexpected TAP report:\n%s", tap.String())
+	}
+
+	var junit bytes.Buffer
+	if err := writeJUnitReport(&junit, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuites tests="3" failures="1">`,
+		`<testsuite name="tests/a.toml" tests="2" failures="1" time="2.000">`,
+		`<testcase name="first" classname="tests/a.toml" time="1.500"></testcase>`,
+		`<failure message="status: expected 200, got 500">status: expected 200, got 500&#xA;body: expected &#34;ok&#34;, got &#34;&#34;</failure>`,
+		`<testsuite name="tests/b.toml" tests="1" failures="0" time="0.250">`,
+	} {
+		if !strings.Contains(junit.String(), want) {
+			t.Errorf("want JUnit report to contain %s, got:\n%s", want, junit.String())
+		}
+	}
+}
+
+func TestTestServeOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	for _, tc := range []struct {
+		reporter   string
+		reportFile string
+		want       io.Writer
+	}{
+		{reporter: testReporterText, want: &stdout},
+		{reporter: testReporterTAP, want: &stderr},
+		{reporter: testReporterJUnit, want: &stderr},
+		{reporter: testReporterTAP, reportFile: "report.tap", want: &stdout},
+		{reporter: testReporterJUnit, reportFile: "report.xml", want: &stdout},
+	} {
+		c := TestCommand{reporter: tc.reporter, reportFile: tc.reportFile}
+		c.Globals = &global.Data{ErrOutput: &stderr}
+		if got := c.serveOutput(&stdout); got != tc.want {
+			t.Errorf("reporter %s, report file %q: serve output went to the wrong writer", tc.reporter, tc.reportFile)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package compute
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// replayIgnoredHeaders are response headers that are expected to change
+// between runs, and so aren't compared when replaying traffic.
+var replayIgnoredHeaders = []string{"Age", "Date", "X-Served-By", "X-Timer"}
+
+// exchange is a request and response recorded by `compute serve --record`.
+// Each exchange is saved as a JSON file so recordings can be reviewed and
+// edited (e.g. to remove requests from a regression suite).
+type exchange struct {
+	Request    exchangeRequest  `json:"request"`
+	Response   exchangeResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+type exchangeRequest struct {
+	Method string      `json:"method"`
+	URI    string      `json:"uri"`
+	Host   string      `json:"host,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	exchangeBody
+}
+
+type exchangeResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	exchangeBody
+}
+
+// exchangeBody is a message body. Bodies that aren't valid UTF-8 are base64
+// encoded.
+type exchangeBody struct {
+	Body         string `json:"body,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+func newExchangeBody(b []byte) exchangeBody {
+	if utf8.Valid(b) {
+		return exchangeBody{Body: string(b)}
+	}
+	return exchangeBody{Body: base64.StdEncoding.EncodeToString(b), BodyEncoding: "base64"}
+}
+
+func (b exchangeBody) bytes() ([]byte, error) {
+	if b.BodyEncoding == "base64" {
+		return base64.StdEncoding.DecodeString(b.Body)
+	}
+	return []byte(b.Body), nil
+}
+
+// trafficRecorder saves the traffic passing through a proxy in front of
+// Viceroy to a directory.
+type trafficRecorder struct {
+	dir string
+	mu  sync.Mutex
+	// seq is the highest number of an exchange saved in dir.
+	seq int
+	out io.Writer
+}
+
+// newTrafficRecorder creates the recording directory. Recordings are added
+// after any that are already in the directory, which may have gaps where
+// recordings were deleted.
+func newTrafficRecorder(dir string, out io.Writer) (*trafficRecorder, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("error creating recording directory: %w", err)
+	}
+	files, err := exchangeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &trafficRecorder{dir: dir, out: out}
+	for _, f := range files {
+		if n, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(f), ".json")); err == nil && n > r.seq {
+			r.seq = n
+		}
+	}
+	return r, nil
+}
+
+// save writes an exchange to the next file in the recording directory. An
+// existing recording is never overwritten.
+func (r *trafficRecorder) save(e exchange) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for {
+		r.seq++
+		// G304 (CWE-22): Potential file inclusion via variable
+		// #nosec
+		f, err := os.OpenFile(filepath.Join(r.dir, fmt.Sprintf("%06d.json", r.seq)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// proxy returns a handler that forwards requests to upstream (Viceroy) and
+// records each request along with its response.
+func (r *trafficRecorder) proxy(upstream string) http.Handler {
+	p := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: upstream})
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		e := exchange{
+			Request: exchangeRequest{
+				Method:       req.Method,
+				URI:          req.URL.RequestURI(),
+				Host:         req.Host,
+				Header:       req.Header.Clone(),
+				exchangeBody: newExchangeBody(body),
+			},
+			RecordedAt: time.Now().UTC(),
+		}
+
+		cw := &captureWriter{ResponseWriter: w}
+		p.ServeHTTP(cw, req)
+
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		e.Response = exchangeResponse{
+			Status:       cw.status,
+			Header:       w.Header().Clone(),
+			exchangeBody: newExchangeBody(cw.body.Bytes()),
+		}
+		if err := r.save(e); err != nil {
+			text.Warning(r.out, "Unable to record %s %s: %s", e.Request.Method, e.Request.URI, err)
+		}
+	})
+}
+
+// captureWriter keeps a copy of the response written to a client.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports streamed responses.
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// startRecording listens on addr with a recording proxy in front of Viceroy,
+// and returns the address Viceroy should listen on instead.
+func startRecording(addr, dir string, out io.Writer) (upstream string, stop func(), err error) {
+	rec, err := newTrafficRecorder(dir, out)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, fsterr.RemediationError{
+			Inner:       fmt.Errorf("error listening on %s: %w", addr, err),
+			Remediation: "Check that another process isn't already listening on the address, or pass a different address with --addr.",
+		}
+	}
+
+	upstream, err = freeLocalAddr()
+	if err != nil {
+		_ = ln.Close()
+		return "", nil, err
+	}
+
+	srv := &http.Server{
+		Handler:           rec.proxy(upstream),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	return upstream, func() { _ = srv.Close() }, nil
+}
+
+// exchangeFiles returns the recordings in dir, in the order they were
+// recorded.
+func exchangeFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readExchange reads a recording.
+func readExchange(path string) (exchange, error) {
+	var e exchange
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return e, nil
+}
+
+// replayLocal replays the recorded traffic against the local server.
+func replayLocal(opts localOpts, dir string) error {
+	files, err := exchangeFiles(dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("no recordings found in %s", dir),
+			Remediation: "Record some traffic with `fastly compute serve --record <dir>` first.",
+		}
+	}
+	return localBackground(opts, func() error {
+		return replayTraffic(opts.addr, files, opts.out)
+	})
+}
+
+// replayTraffic sends the recorded requests to the server at addr and reports
+// the responses that differ from the recordings.
+func replayTraffic(addr string, files []string, out io.Writer) error {
+	client := &http.Client{
+		// The recorded responses are compared as they were sent to the client,
+		// so redirects aren't followed and bodies aren't decompressed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{DisableCompression: true},
+		Timeout:   time.Minute,
+	}
+
+	var failed int
+	for _, file := range files {
+		name := filepath.Base(file)
+		e, err := readExchange(file)
+		if err != nil {
+			return err
+		}
+		diffs, err := replayExchange(client, addr, e)
+		if err != nil {
+			return fmt.Errorf("error replaying %s: %w", name, err)
+		}
+		if len(diffs) == 0 {
+			text.Output(out, "%s %s %s %s", text.BoldGreen("PASS"), name, e.Request.Method, e.Request.URI)
+			continue
+		}
+		failed++
+		text.Output(out, "%s %s %s %s", text.BoldRed("FAIL"), name, e.Request.Method, e.Request.URI)
+		for _, d := range diffs {
+			text.Output(out, "     %s", d)
+		}
+	}
+
+	text.Break(out)
+	if failed > 0 {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("%d of %d replayed responses differ from the recordings", failed, len(files)),
+			Remediation: "If the changes are expected, record the traffic again with `fastly compute serve --record <dir>`.",
+		}
+	}
+	text.Success(out, "All %d replayed responses match the recordings", len(files))
+	return nil
+}
+
+// replayExchange sends a recorded request and returns the differences between
+// the response and the recorded response.
+func replayExchange(client *http.Client, addr string, e exchange) ([]string, error) {
+	body, err := e.Request.bytes()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(e.Request.Method, "http://"+addr+e.Request.URI, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = e.Request.Header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if e.Request.Host != "" {
+		req.Host = e.Request.Host
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // #nosec G307
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	want, err := e.Response.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return diffResponse(e.Response, want, resp, got), nil
+}
+
+// diffResponse compares a response with a recorded response.
+func diffResponse(recorded exchangeResponse, recordedBody []byte, resp *http.Response, body []byte) []string {
+	var diffs []string
+	if resp.StatusCode != recorded.Status {
+		diffs = append(diffs, fmt.Sprintf("status: recorded %d, got %d", recorded.Status, resp.StatusCode))
+	}
+
+	names := make(map[string]bool)
+	for name := range recorded.Header {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	for name := range resp.Header {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if !slices.Contains(replayIgnoredHeaders, name) {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		want, got := strings.Join(recorded.Header.Values(name), ", "), strings.Join(resp.Header.Values(name), ", ")
+		switch {
+		case want == got:
+		case got == "":
+			diffs = append(diffs, fmt.Sprintf("header %s: recorded %q, missing", name, want))
+		case want == "":
+			diffs = append(diffs, fmt.Sprintf("header %s: not recorded, got %q", name, got))
+		default:
+			diffs = append(diffs, fmt.Sprintf("header %s: recorded %q, got %q", name, want, got))
+		}
+	}
+
+	if !bytes.Equal(recordedBody, body) {
+		diffs = append(diffs, diffBody(recordedBody, body))
+	}
+	return diffs
+}
+
+// diffBody describes the first difference between two bodies.
+func diffBody(want, got []byte) string {
+	if !utf8.Valid(want) || !utf8.Valid(got) {
+		return fmt.Sprintf("body: recorded %d bytes, got %d bytes", len(want), len(got))
+	}
+	wantLines, gotLines := strings.Split(string(want), "\n"), strings.Split(string(got), "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("body (line %d): recorded %q, got %q", i+1, w, g)
+		}
+	}
+	return fmt.Sprintf("body: recorded %d bytes, got %d bytes", len(want), len(got))
+}
@@ -0,0 +1,144 @@
+package compute
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplayTraffic(t *testing.T) {
+	greeting := "Hello"
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Date", "changes every time")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%s %s\nhost=%s\nbody=%s\n", greeting, r.URL.Path, r.Host, body)
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	var out bytes.Buffer
+	rec, err := newTrafficRecorder(dir, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httptest.NewServer(rec.proxy(strings.TrimPrefix(upstream.URL, "http://")))
+	defer proxy.Close()
+
+	req, err := http.NewRequest(http.MethodPost, proxy.URL+"/world?q=1", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = "example.com"
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if want := "Hello /world\nhost=example.com\nbody=payload\n"; string(body) != want {
+		t.Fatalf("want proxied body %q, got %q", want, body)
+	}
+
+	files, err := exchangeFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("want 1 recording, got %d", len(files))
+	}
+	e, err := readExchange(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Request.Method != http.MethodPost || e.Request.URI != "/world?q=1" || e.Request.Host != "example.com" || e.Request.Body != "payload" {
+		t.Errorf("unexpected recorded request: %+v", e.Request)
+	}
+	if e.Response.Status != http.StatusCreated || e.Response.Body != string(body) {
+		t.Errorf("unexpected recorded response: %+v", e.Response)
+	}
+
+	addr := strings.TrimPrefix(upstream.URL, "http://")
+
+	out.Reset()
+	if err := replayTraffic(addr, files, &out); err != nil {
+		t.Fatalf("unexpected error replaying unchanged traffic: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "PASS 000001.json POST /world?q=1") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	greeting = "Goodbye"
+	out.Reset()
+	err = replayTraffic(addr, files, &out)
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 replayed responses differ") {
+		t.Fatalf("want replayed responses to differ, got: %v", err)
+	}
+	if want := `body (line 1): recorded "Hello /world", got "Goodbye /world"`; !strings.Contains(out.String(), want) {
+		t.Errorf("want output to contain %q, got: %s", want, out.String())
+	}
+}
+
+func TestTrafficRecorderAfterDeletion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"000001.json", "000003.json", "000005.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec, err := newTrafficRecorder(dir, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.save(exchange{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "000005.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{}\n" {
+		t.Error("want the existing recording to be kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "000006.json")); err != nil {
+		t.Errorf("want the recording to be saved after the existing ones: %v", err)
+	}
+}
+
+func TestDiffResponse(t *testing.T) {
+	recorded := exchangeResponse{
+		Status: http.StatusOK,
+		Header: http.Header{
+			"Age":          {"1"},
+			"Content-Type": {"text/html"},
+			"X-Removed":    {"yes"},
+		},
+	}
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header: http.Header{
+			"Age":          {"2"},
+			"Content-Type": {"text/plain"},
+			"X-Added":      {"yes"},
+		},
+	}
+	got := diffResponse(recorded, []byte("a\nb"), resp, []byte("a\nb"))
+	want := []string{
+		"status: recorded 200, got 404",
+		`header Content-Type: recorded "text/html", got "text/plain"`,
+		`header X-Added: not recorded, got "yes"`,
+		`header X-Removed: recorded "yes", missing`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/kennygrant/sanitize"
 	"github.com/mholt/archives"
@@ -15,6 +16,7 @@ import (
 	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/manifest"
 	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/wasm"
 )
 
 // NewValidateCommand returns a usable command registered under the parent.
@@ -22,7 +24,9 @@ func NewValidateCommand(parent argparser.Registerer, g *global.Data) *ValidateCo
 	var c ValidateCommand
 	c.Globals = g
 	c.CmdClause = parent.Command("validate", "Validate a Compute package")
+	c.CmdClause.Flag("deep", "Inspect the Wasm binary: report its imports, exports, section sizes and largest functions, and check it will instantiate on the platform").BoolVar(&c.deep)
 	c.CmdClause.Flag("package", "Path to a package tar.gz").Short('p').StringVar(&c.path)
+	c.CmdClause.Flag("top-functions", "The number of the largest functions to report with --deep").Default("10").IntVar(&c.topFunctions)
 	c.CmdClause.Flag("env", "The manifest environment config to validate (e.g. 'stage' will attempt to read 'fastly.stage.toml' inside the package)").StringVar(&c.env)
 	return &c
 }
@@ -66,15 +70,52 @@ func (c *ValidateCommand) Exec(_ io.Reader, out io.Writer) error {
 		}
 	}
 
+	if c.deep {
+		if err := c.inspect(p, out); err != nil {
+			return err
+		}
+	}
+
 	text.Success(out, "Validated package %s", p)
 	return nil
 }
 
+// inspect reports the structure of the package's Wasm binary, and returns an
+// error if it won't instantiate on the platform.
+func (c *ValidateCommand) inspect(pkgPath string, out io.Writer) error {
+	data, err := readPackageWasm(pkgPath)
+	if err != nil {
+		return err
+	}
+	b, err := wasm.Parse(data)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Path": c.path,
+		})
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("failed to parse main.wasm: %w", err),
+			Remediation: "Check the build targets WebAssembly (e.g. wasm32-wasip1) and rebuild the package with `fastly compute build`.",
+		}
+	}
+
+	writeWasmReport(out, b, c.topFunctions)
+
+	if problems := wasmProblems(b); len(problems) > 0 {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("main.wasm won't instantiate on the Compute platform:\n\n- %s", strings.Join(problems, "\n- ")),
+			Remediation: "Check the build targets wasm32-wasip1 and that the project depends on the Compute SDK for its language rather than libraries that need other host functions.",
+		}
+	}
+	return nil
+}
+
 // ValidateCommand validates a package archive.
 type ValidateCommand struct {
 	argparser.Base
-	env  string
-	path string
+	deep         bool
+	env          string
+	path         string
+	topFunctions int
 }
 
 // validatePackageContent is a utility function to determine whether a package
@@ -0,0 +1,340 @@
+package compute
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fastly/kingpin"
+	toml "github.com/pelletier/go-toml"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/manifest"
+	fstsync "github.com/fastly/cli/pkg/sync"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// WorkspaceFilename is the file listing the Compute packages in a monorepo.
+const WorkspaceFilename = "fastly.workspace.toml"
+
+// defaultWorkspaceConcurrency is the number of members processed at once when
+// neither the workspace file nor --workspace-concurrency set a value.
+const defaultWorkspaceConcurrency = 4
+
+// Workspace is the content of the workspace file.
+type Workspace struct {
+	// Members are the package directories, relative to the workspace file.
+	// Glob patterns (e.g. "services/*") are expanded to the directories
+	// containing a package manifest.
+	Members []string `toml:"members"`
+	// Concurrency is the maximum number of members processed at once.
+	Concurrency int `toml:"concurrency,omitempty"`
+}
+
+// workspaceOpts are the flags for running a command for every member of a
+// workspace.
+type workspaceOpts struct {
+	enabled     bool
+	concurrency int
+}
+
+// registerWorkspaceFlags adds the workspace flags to a command.
+func registerWorkspaceFlags(cmd *kingpin.CmdClause, opts *workspaceOpts) {
+	cmd.Flag("workspace", fmt.Sprintf("Run the command for every package listed in %s (found in the current directory or a parent)", WorkspaceFilename)).BoolVar(&opts.enabled)
+	cmd.Flag("workspace-concurrency", "The maximum number of workspace packages to process at once").IntVar(&opts.concurrency)
+}
+
+// FindWorkspace returns the path of the workspace file in dir or the closest
+// parent directory.
+func FindWorkspace(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, WorkspaceFilename)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fsterr.RemediationError{
+				Inner:       fmt.Errorf("no %s found in the current directory or its parents", WorkspaceFilename),
+				Remediation: fmt.Sprintf("Create a %s at the root of the repository listing the package directories, e.g. members = [\"services/*\"].", WorkspaceFilename),
+			}
+		}
+		dir = parent
+	}
+}
+
+// ReadWorkspace parses a workspace file.
+func ReadWorkspace(path string) (Workspace, error) {
+	var w Workspace
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return w, err
+	}
+	if err := toml.Unmarshal(data, &w); err != nil {
+		return w, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if len(w.Members) == 0 {
+		return w, fsterr.RemediationError{
+			Inner:       fmt.Errorf("%s has no members", path),
+			Remediation: "List the package directories in the `members` field, e.g. members = [\"services/*\"].",
+		}
+	}
+	return w, nil
+}
+
+// MemberDirs resolves the members of a workspace to package directories,
+// relative to root (the directory containing the workspace file).
+func (w Workspace) MemberDirs(root string) ([]string, error) {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, member := range w.Members {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(member)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace member %q: %w", member, err)
+		}
+		var found bool
+		for _, m := range matches {
+			if _, err := os.Stat(filepath.Join(m, manifest.Filename)); err != nil {
+				continue
+			}
+			found = true
+			rel, err := filepath.Rel(root, m)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[rel] {
+				seen[rel] = true
+				dirs = append(dirs, rel)
+			}
+		}
+		if !found {
+			return nil, fsterr.RemediationError{
+				Inner:       fmt.Errorf("workspace member %q doesn't match a directory containing a %s", member, manifest.Filename),
+				Remediation: fmt.Sprintf("Check the members listed in %s.", WorkspaceFilename),
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// workspaceResult is the outcome of running a command for a workspace member.
+type workspaceResult struct {
+	dir      string
+	err      error
+	duration time.Duration
+}
+
+// runWorkspace runs `fastly compute <command>` for every member of the
+// workspace.
+//
+// Each member is processed by a separate CLI process because the compute
+// commands change the working directory of the process to the package
+// directory. The output of each process is prefixed with the member
+// directory.
+func runWorkspace(args []string, command string, opts workspaceOpts, out io.Writer) error {
+	if flagValue(args, "dir", "C") != "" {
+		return fsterr.RemediationError{
+			Inner:       errors.New("--dir can't be used with --workspace"),
+			Remediation: fmt.Sprintf("The package directories are read from %s.", WorkspaceFilename),
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	path, err := FindWorkspace(wd)
+	if err != nil {
+		return err
+	}
+	w, err := ReadWorkspace(path)
+	if err != nil {
+		return err
+	}
+	root := filepath.Dir(path)
+	dirs, err := w.MemberDirs(root)
+	if err != nil {
+		return err
+	}
+
+	concurrency := opts.concurrency
+	if concurrency <= 0 {
+		concurrency = w.Concurrency
+	}
+	if concurrency <= 0 {
+		concurrency = defaultWorkspaceConcurrency
+	}
+
+	bin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error locating the fastly executable: %w", err)
+	}
+
+	memberArgs := workspaceMemberArgs(args)
+	// Prompts from concurrent processes can't be answered reliably.
+	if concurrency > 1 && command != "build" && !hasFlag(memberArgs, "non-interactive", "i") {
+		memberArgs = append(memberArgs, "--non-interactive")
+	}
+
+	text.Info(out, "Running `fastly compute %s` for %d workspace packages (%s)", command, len(dirs), path)
+	text.Break(out)
+
+	results := runWorkspaceMembers(dirs, concurrency, fstsync.NewWriter(out), func(dir string, w io.Writer) error {
+		// gosec flagged this:
+		// G204 (CWE-78): Subprocess launched with variable
+		// Disabling as the executable is the running CLI.
+		// #nosec
+		cmd := exec.Command(bin, append(memberArgs, "--dir", filepath.Join(root, dir))...)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		return cmd.Run()
+	})
+	return workspaceSummary(out, command, results)
+}
+
+// runWorkspaceMembers calls run for each member directory, with at most
+// concurrency calls running at once. Output written by run is prefixed with
+// the member directory.
+func runWorkspaceMembers(dirs []string, concurrency int, out io.Writer, run func(dir string, w io.Writer) error) []workspaceResult {
+	width := 0
+	for _, dir := range dirs {
+		width = max(width, len(dir))
+	}
+
+	results := make([]workspaceResult, len(dirs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, dir := range dirs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			pw := &prefixWriter{prefix: fmt.Sprintf("%-*s | ", width, filepath.ToSlash(dir)), w: out}
+			start := time.Now()
+			err := run(dir, pw)
+			pw.flush()
+			results[i] = workspaceResult{dir: dir, err: err, duration: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// workspaceSummary prints the outcome for each member and returns an error if
+// any failed.
+func workspaceSummary(out io.Writer, command string, results []workspaceResult) error {
+	text.Break(out)
+	var failed []string
+	for _, r := range results {
+		if r.err != nil {
+			failed = append(failed, r.dir)
+			text.Output(out, "%s %s (%s): %s", text.BoldRed("FAIL"), filepath.ToSlash(r.dir), r.duration.Round(time.Millisecond), r.err)
+			continue
+		}
+		text.Output(out, "%s %s (%s)", text.BoldGreen("OK"), filepath.ToSlash(r.dir), r.duration.Round(time.Millisecond))
+	}
+	text.Break(out)
+
+	if len(failed) > 0 {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("`compute %s` failed for %d of %d workspace packages: %s", command, len(failed), len(results), strings.Join(failed, ", ")),
+			Remediation: fmt.Sprintf("Check the output prefixed with the package directory, or run `fastly compute %s --dir <package>` to retry a single package.", command),
+		}
+	}
+	text.Success(out, "Ran `compute %s` for all %d workspace packages", command, len(results))
+	return nil
+}
+
+// workspaceMemberArgs returns the arguments to run the command for a single
+// member, i.e. args without the workspace flags.
+func workspaceMemberArgs(args []string) []string {
+	var filtered []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--workspace", strings.HasPrefix(arg, "--workspace-concurrency="):
+		case arg == "--workspace-concurrency":
+			i++ // skip the value
+		default:
+			filtered = append(filtered, arg)
+		}
+	}
+	return filtered
+}
+
+// hasFlag reports whether a boolean flag was passed.
+func hasFlag(args []string, long, short string) bool {
+	for _, arg := range args {
+		if arg == "--"+long || arg == "-"+short {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns the value of a flag that takes a value.
+func flagValue(args []string, long, short string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--"+long || arg == "-"+short:
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--"+long+"="):
+			return strings.TrimPrefix(arg, "--"+long+"=")
+		case strings.HasPrefix(arg, "-"+short) && len(arg) > len(short)+1 && !strings.HasPrefix(arg, "--"):
+			return strings.TrimPrefix(arg, "-"+short)
+		}
+	}
+	return ""
+}
+
+// prefixWriter prefixes each line written to it. Incomplete lines are
+// buffered, so that lines from concurrent writers sharing w aren't
+// interleaved.
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+	buf    bytes.Buffer
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+	for {
+		line, err := p.buf.ReadBytes('\n')
+		if err != nil {
+			// Put back the incomplete line.
+			rest := append([]byte(nil), line...)
+			p.buf.Reset()
+			p.buf.Write(rest)
+			return len(b), nil
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// flush writes any incomplete final line.
+func (p *prefixWriter) flush() {
+	if p.buf.Len() > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf.String())
+		p.buf.Reset()
+	}
+}
@@ -0,0 +1,127 @@
+package compute
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWorkspaceMemberDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"services/api", "services/www", "services/docs", "edge"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// services/docs isn't a package, so the glob shouldn't match it.
+	for _, dir := range []string{"services/api", "services/www", "edge"} {
+		if err := os.WriteFile(filepath.Join(root, dir, "fastly.toml"), []byte("name = \"test\"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workspace := "members = [\"services/*\", \"edge\", \"services/api\"]\nconcurrency = 2\n"
+	if err := os.WriteFile(filepath.Join(root, WorkspaceFilename), []byte(workspace), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := FindWorkspace(filepath.Join(root, "services", "docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := ReadWorkspace(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Concurrency != 2 {
+		t.Errorf("want concurrency 2, got %d", w.Concurrency)
+	}
+	dirs, err := w.MemberDirs(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"edge", filepath.Join("services", "api"), filepath.Join("services", "www")}
+	if !slices.Equal(dirs, want) {
+		t.Errorf("want member directories %q, got %q", want, dirs)
+	}
+
+	w.Members = append(w.Members, "missing")
+	if _, err := w.MemberDirs(filepath.Dir(path)); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("want an error for the missing member, got: %v", err)
+	}
+}
+
+func TestWorkspaceMemberArgs(t *testing.T) {
+	args := []string{"compute", "publish", "--workspace", "--workspace-concurrency", "8", "--verbose", "--workspace-concurrency=2", "--comment", "release"}
+	want := []string{"compute", "publish", "--verbose", "--comment", "release"}
+	if got := workspaceMemberArgs(args); !slices.Equal(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	for _, args := range [][]string{{"-C", "pkg"}, {"--dir", "pkg"}, {"--dir=pkg"}, {"-Cpkg"}} {
+		if got := flagValue(args, "dir", "C"); got != "pkg" {
+			t.Errorf("want the value of --dir in %q, got %q", args, got)
+		}
+	}
+}
+
+func TestRunWorkspaceMembers(t *testing.T) {
+	var (
+		out     strings.Builder
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+	dirs := []string{"a", "bb", "c", "d"}
+	results := runWorkspaceMembers(dirs, 2, &lockedWriter{w: &out}, func(dir string, w io.Writer) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		fmt.Fprint(w, "building\ndone")
+		if dir == "c" {
+			return errors.New("exit status 1")
+		}
+		return nil
+	})
+
+	if peak > 2 {
+		t.Errorf("want at most 2 members processed at once, got %d", peak)
+	}
+	for _, want := range []string{"a  | building\n", "a  | done\n", "bb | building\n", "c  | done\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	var summary strings.Builder
+	err := workspaceSummary(&summary, "build", results)
+	if err == nil || !strings.Contains(err.Error(), "failed for 1 of 4 workspace packages: c") {
+		t.Errorf("want an error naming the failed package, got: %v", err)
+	}
+	if !strings.Contains(summary.String(), "c (") || !strings.Contains(summary.String(), "exit status 1") {
+		t.Errorf("want the summary to report the failure, got:\n%s", summary.String())
+	}
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
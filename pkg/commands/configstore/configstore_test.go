@@ -71,6 +71,82 @@ func TestCreateStoreCommand(t *testing.T) {
 	testutil.RunCLIScenarios(t, []string{root.CommandName, "create"}, scenarios)
 }
 
+func TestCopyStoreCommand(t *testing.T) {
+	const (
+		fromID = "store-id-123"
+		toID   = "store-id-456"
+	)
+
+	listItems := func(_ context.Context, i *fastly.ListConfigStoreItemsInput) ([]*fastly.ConfigStoreItem, error) {
+		if i.StoreID != fromID {
+			return nil, fmt.Errorf("unexpected store %s", i.StoreID)
+		}
+		return []*fastly.ConfigStoreItem{
+			{StoreID: fromID, Key: "staging_origin", Value: "stage.example.com"},
+			{StoreID: fromID, Key: "staging_ttl", Value: "60"},
+			{StoreID: fromID, Key: "other", Value: "ignored"},
+		}, nil
+	}
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Args:      fmt.Sprintf("--from %s", fromID),
+			WantError: "error parsing arguments: required flag --to not provided",
+		},
+		{
+			Args:      fmt.Sprintf("--from %s --to %s", fromID, fromID),
+			API:       &mock.API{ListConfigStoreItemsFn: listItems},
+			WantError: "the source and destination stores are the same",
+		},
+		{
+			Args: fmt.Sprintf("--from %s --to %s --prefix staging_ --rename prod_{{.Name}} --dry-run", fromID, toID),
+			API:  &mock.API{ListConfigStoreItemsFn: listItems},
+			WantOutputs: []string{
+				"staging_origin -> prod_origin\nstaging_ttl -> prod_ttl\n",
+				"2 keys would be copied",
+			},
+			DontWantOutput: "other",
+		},
+		{
+			Args:      fmt.Sprintf("--from %s --to %s --rename prod", fromID, toID),
+			API:       &mock.API{ListConfigStoreItemsFn: listItems},
+			WantError: "are both renamed to 'prod'",
+		},
+		{
+			Args: fmt.Sprintf("--from %s --to %s --prefix staging_ --rename {{.Name|upper}}", fromID, toID),
+			API: &mock.API{
+				ListConfigStoreItemsFn: listItems,
+				UpdateConfigStoreItemFn: func(_ context.Context, i *fastly.UpdateConfigStoreItemInput) (*fastly.ConfigStoreItem, error) {
+					if i.StoreID != toID || !i.Upsert {
+						return nil, errors.New("expected an upsert into the destination store")
+					}
+					if (i.Key != "ORIGIN" || i.Value != "stage.example.com") && (i.Key != "TTL" || i.Value != "60") {
+						return nil, fmt.Errorf("unexpected item %s=%s", i.Key, i.Value)
+					}
+					return &fastly.ConfigStoreItem{StoreID: i.StoreID, Key: i.Key, Value: i.Value}, nil
+				},
+			},
+			WantOutput: fmt.Sprintf("Copied 2 keys from store '%s' to '%s'", fromID, toID),
+		},
+		{
+			Args: fmt.Sprintf("--from %s --to %s", fromID, toID),
+			API: &mock.API{
+				ListConfigStoreItemsFn: listItems,
+				UpdateConfigStoreItemFn: func(_ context.Context, i *fastly.UpdateConfigStoreItemInput) (*fastly.ConfigStoreItem, error) {
+					if i.Key == "other" {
+						return nil, errors.New("invalid request")
+					}
+					return &fastly.ConfigStoreItem{StoreID: i.StoreID, Key: i.Key, Value: i.Value}, nil
+				},
+			},
+			WantOutput: "Key: other\nError: invalid request",
+			WantError:  "failed to copy 1 keys",
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "copy"}, scenarios)
+}
+
 func TestDeleteStoreCommand(t *testing.T) {
 	const storeID = "test123"
 	errStoreNotFound := errors.New("store not found")
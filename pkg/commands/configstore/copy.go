@@ -0,0 +1,74 @@
+package configstore
+
+import (
+	"context"
+	"io"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/storecopy"
+)
+
+// NewCopyCommand returns a usable command registered under the parent.
+func NewCopyCommand(parent argparser.Registerer, g *global.Data) *CopyCommand {
+	c := CopyCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+
+	c.CmdClause = parent.Command("copy", "Copy the items of a config store to another config store")
+	c.opts.RegisterFlags(c.CmdClause)
+
+	return &c
+}
+
+// CopyCommand calls the Fastly API to copy the items of a config store.
+type CopyCommand struct {
+	argparser.Base
+	opts storecopy.Options
+}
+
+// Exec invokes the application logic for the command.
+func (c *CopyCommand) Exec(_ io.Reader, out io.Writer) error {
+	items, err := c.Globals.APIClient.ListConfigStoreItems(context.TODO(), &fastly.ListConfigStoreItemsInput{
+		StoreID: c.opts.From,
+	})
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	values := make(map[string]string, len(items))
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		values[item.Key] = item.Value
+		keys = append(keys, item.Key)
+	}
+
+	pairs, err := c.opts.Plan(keys)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	if c.opts.DryRun {
+		storecopy.PrintPlan(out, pairs)
+		return nil
+	}
+
+	err = storecopy.Copy(out, c.opts, pairs, func(p storecopy.Pair) error {
+		_, err := c.Globals.APIClient.UpdateConfigStoreItem(context.TODO(), &fastly.UpdateConfigStoreItemInput{
+			StoreID: c.opts.To,
+			Key:     p.To,
+			Value:   values[p.From],
+			Upsert:  true,
+		})
+		return err
+	})
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+	}
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -247,6 +248,77 @@ func TestDescribeEntryCommand(t *testing.T) {
 	testutil.RunCLIScenarios(t, []string{root.CommandName, "describe"}, scenarios)
 }
 
+func TestImportEntriesCommand(t *testing.T) {
+	const storeID = "store-id-123"
+
+	listItems := func(_ context.Context, _ *fastly.ListConfigStoreItemsInput) ([]*fastly.ConfigStoreItem, error) {
+		return []*fastly.ConfigStoreItem{
+			{StoreID: storeID, Key: "ORIGIN", Value: "origin.example.com"},
+			{StoreID: storeID, Key: "TTL", Value: "30"},
+			{StoreID: storeID, Key: "OLD", Value: "old"},
+		}, nil
+	}
+
+	var scenarios []testutil.CLIScenario
+	for _, file := range []string{"vars.env", "vars.json", "vars.yaml", "vars.csv"} {
+		scenarios = append(scenarios, testutil.CLIScenario{
+			Name: "validate --dry-run with " + file,
+			Args: fmt.Sprintf("--store-id %s --file %s --prune --dry-run", storeID, filepath.Join("testdata", file)),
+			API:  &mock.API{ListConfigStoreItemsFn: listItems},
+			WantOutputs: []string{
+				"+ GREETING\n+ PATTERN\n~ TTL\n- OLD\n",
+				"Plan: 2 to create, 1 to update, 1 to delete, 1 unchanged",
+			},
+		})
+	}
+	scenarios = append(scenarios, []testutil.CLIScenario{
+		{
+			Args:      fmt.Sprintf("--store-id %s --file %s", storeID, filepath.Join("testdata", "invalid.yaml")),
+			WantError: "the value of 'ORIGIN' must be a string, number or boolean",
+		},
+		{
+			Args:            fmt.Sprintf("--store-id %s --file vars.txt", storeID),
+			WantError:       "unable to determine the format of vars.txt",
+			WantRemediation: "--format",
+		},
+		{
+			Args: fmt.Sprintf("--store-id %s --file %s --prune --auto-yes --batch-size 1", storeID, filepath.Join("testdata", "vars.env")),
+			API: &mock.API{
+				ListConfigStoreItemsFn: listItems,
+				UpdateConfigStoreItemFn: func(_ context.Context, i *fastly.UpdateConfigStoreItemInput) (*fastly.ConfigStoreItem, error) {
+					want := map[string]string{"GREETING": "Hello,\n\"world\"", "PATTERN": "^/api/.*$", "TTL": "60"}
+					if v, ok := want[i.Key]; !ok || v != i.Value || !i.Upsert {
+						return nil, fmt.Errorf("unexpected update of %s=%q", i.Key, i.Value)
+					}
+					return &fastly.ConfigStoreItem{StoreID: i.StoreID, Key: i.Key, Value: i.Value}, nil
+				},
+				DeleteConfigStoreItemFn: func(_ context.Context, i *fastly.DeleteConfigStoreItemInput) error {
+					if i.Key != "OLD" {
+						return fmt.Errorf("unexpected delete of %s", i.Key)
+					}
+					return nil
+				},
+			},
+			WantOutput: fstfmt.Success("Imported %s into Config Store '%s' (2 created, 1 updated, 1 deleted, 1 unchanged)", filepath.Join("testdata", "vars.env"), storeID),
+		},
+		{
+			Args: fmt.Sprintf("--store-id %s --file %s", storeID, filepath.Join("testdata", "vars.json")),
+			API: &mock.API{
+				ListConfigStoreItemsFn: listItems,
+				UpdateConfigStoreItemFn: func(_ context.Context, i *fastly.UpdateConfigStoreItemInput) (*fastly.ConfigStoreItem, error) {
+					if i.Key == "TTL" {
+						return nil, errors.New("invalid request")
+					}
+					return &fastly.ConfigStoreItem{StoreID: i.StoreID, Key: i.Key, Value: i.Value}, nil
+				},
+			},
+			WantError: "failed to import keys: TTL",
+		},
+	}...)
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "import"}, scenarios)
+}
+
 func TestListEntriesCommand(t *testing.T) {
 	const storeID = "store-id-123"
 
@@ -0,0 +1,223 @@
+package configstoreentry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// importFormats are the file formats supported by `import`.
+var importFormats = []string{"csv", "dotenv", "json", "yaml"}
+
+// fileFormat returns the format of a file from its name.
+func fileFormat(path string) (string, error) {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env"):
+		return "dotenv", nil
+	case strings.HasSuffix(name, ".json"):
+		return "json", nil
+	case strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml"):
+		return "yaml", nil
+	case strings.HasSuffix(name, ".csv"):
+		return "csv", nil
+	}
+	return "", fmt.Errorf("unable to determine the format of %s from its extension", path)
+}
+
+// parseItems returns the items of a file, keyed by name.
+func parseItems(data []byte, format string) (map[string]string, error) {
+	switch format {
+	case "csv":
+		return parseCSV(data)
+	case "dotenv":
+		return parseDotenv(data)
+	case "json":
+		return parseJSON(data)
+	case "yaml":
+		return parseYAML(data)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// parseCSV parses records of two fields, a key and a value. A first record of
+// "key,value" is treated as a header.
+func parseCSV(data []byte) (map[string]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 2
+
+	items := make(map[string]string)
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "key") && strings.EqualFold(record[1], "value") {
+			continue
+		}
+		if err := addItem(items, record[0], record[1]); err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+	}
+	return items, nil
+}
+
+// parseDotenv parses KEY=VALUE lines, optionally prefixed with `export`.
+// Values can be single quoted (literal) or double quoted (with \n, \t, \" and
+// \\ escapes). Blank lines, and comments starting with #, are ignored.
+func parseDotenv(data []byte) (map[string]string, error) {
+	items := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		s = strings.TrimPrefix(s, "export ")
+
+		key, value, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		value, err := dotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := addItem(items, key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// dotenvValue returns the value of a dotenv line, without quotes or comments.
+func dotenvValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "'"):
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quoted value")
+		}
+		return s[1 : end+1], nil
+	case strings.HasPrefix(s, `"`):
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '"':
+				return b.String(), nil
+			case '\\':
+				if i+1 == len(s) {
+					return "", errors.New("unterminated double quoted value")
+				}
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				default:
+					b.WriteByte(s[i])
+				}
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+		return "", errors.New("unterminated double quoted value")
+	}
+	// Unquoted values end at an inline comment.
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// parseJSON parses an object of strings, numbers or booleans.
+func parseJSON(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]string, len(m))
+	for key, v := range m {
+		var value string
+		switch v := v.(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("the value of '%s' must be a string, number or boolean", key)
+		}
+		if err := addItem(items, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// parseYAML parses a mapping of scalar values, which are used as written (e.g.
+// 1.10 isn't changed to 1.1).
+func parseYAML(data []byte) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	items := make(map[string]string)
+	if len(doc.Content) == 0 {
+		return items, nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of keys to values", m.Line)
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if k.Kind != yaml.ScalarNode || v.Kind != yaml.ScalarNode || v.Tag == "!!null" {
+			return nil, fmt.Errorf("line %d: the value of '%s' must be a string, number or boolean", k.Line, k.Value)
+		}
+		if err := addItem(items, k.Value, v.Value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", k.Line, err)
+		}
+	}
+	return items, nil
+}
+
+// addItem adds an item, validating its key and value.
+func addItem(items map[string]string, key, value string) error {
+	switch {
+	case key == "":
+		return errors.New("empty key")
+	case len(key) > maxKeyLen:
+		return fmt.Errorf("the key '%s' exceeds %d bytes", key, maxKeyLen)
+	case len(value) > maxValueLen:
+		return fmt.Errorf("the value of '%s' exceeds %d bytes", key, maxValueLen)
+	}
+	if _, ok := items[key]; ok {
+		return fmt.Errorf("the key '%s' is defined more than once", key)
+	}
+	items[key] = value
+	return nil
+}
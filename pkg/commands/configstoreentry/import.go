@@ -0,0 +1,247 @@
+package configstoreentry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// NewImportCommand returns a usable command registered under the parent.
+func NewImportCommand(parent argparser.Registerer, g *global.Data) *ImportCommand {
+	c := ImportCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+
+	c.CmdClause = parent.Command("import", "Create or update the config store items defined in a dotenv, JSON, YAML or CSV file")
+
+	// Required.
+	c.CmdClause.Flag("file", "Path to a file of keys and values: a .env file of KEY=VALUE lines, a JSON or YAML object, or a CSV file of key,value records").Short('f').Required().StringVar(&c.filePath)
+	c.RegisterFlag(argparser.StoreIDFlag(&c.storeID)) // --store-id
+
+	// Optional.
+	c.CmdClause.Flag("batch-size", "Key batch processing size").Short('b').Action(c.batchSize.Set).IntVar(&c.batchSize.Value)
+	c.CmdClause.Flag("concurrency", "Control thread pool size").Short('c').Action(c.concurrency.Set).IntVar(&c.concurrency.Value)
+	c.CmdClause.Flag("dry-run", "Print the keys that would be created, updated and deleted without changing the store").BoolVar(&c.dryRun)
+	c.CmdClause.Flag("format", "The format of the file (determined from its extension by default)").HintOptions(importFormats...).EnumVar(&c.format, importFormats...)
+	c.RegisterFlagBool(c.JSONFlag()) // --json
+	c.CmdClause.Flag("prune", "Delete the items of the store that aren't defined in the file").BoolVar(&c.prune)
+
+	return &c
+}
+
+// ImportCommand calls the Fastly API to make the items of a config store match
+// the content of a file.
+type ImportCommand struct {
+	argparser.Base
+	argparser.JSONOutput
+
+	batchSize   argparser.OptionalInt
+	concurrency argparser.OptionalInt
+	dryRun      bool
+	filePath    string
+	format      string
+	prune       bool
+	storeID     string
+}
+
+// importPlan is the set of changes needed for the store to match the file.
+type importPlan struct {
+	StoreID   string   `json:"store_id"`
+	Create    []string `json:"create"`
+	Update    []string `json:"update"`
+	Delete    []string `json:"delete"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Exec invokes the application logic for the command.
+func (c *ImportCommand) Exec(in io.Reader, out io.Writer) error {
+	if c.Globals.Verbose() && c.JSONOutput.Enabled {
+		return fsterr.ErrInvalidVerboseJSONCombo
+	}
+
+	if (c.batchSize.WasSet && c.batchSize.Value < 1) || (c.concurrency.WasSet && c.concurrency.Value < 1) {
+		return fmt.Errorf("--batch-size and --concurrency must be greater than zero")
+	}
+
+	format := c.format
+	if format == "" {
+		var err error
+		if format, err = fileFormat(c.filePath); err != nil {
+			return fsterr.RemediationError{
+				Inner:       err,
+				Remediation: fmt.Sprintf("Set --format to one of: %s.", strings.Join(importFormats, ", ")),
+			}
+		}
+	}
+	data, err := os.ReadFile(c.filePath)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	items, err := parseItems(data, format)
+	if err != nil {
+		err = fmt.Errorf("error parsing %s: %w", c.filePath, err)
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	// NOTE: The Config Store returns ALL items (there is no pagination).
+	existing, err := c.Globals.APIClient.ListConfigStoreItems(context.TODO(), &fastly.ListConfigStoreItemsInput{
+		StoreID: c.storeID,
+	})
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	plan := importPlan{
+		StoreID: c.storeID,
+		Create:  []string{},
+		Update:  []string{},
+		Delete:  []string{},
+	}
+	current := make(map[string]string, len(existing))
+	for _, item := range existing {
+		current[item.Key] = item.Value
+		if _, ok := items[item.Key]; !ok && c.prune {
+			plan.Delete = append(plan.Delete, item.Key)
+		}
+	}
+	for key, value := range items {
+		v, ok := current[key]
+		switch {
+		case !ok:
+			plan.Create = append(plan.Create, key)
+		case v != value:
+			plan.Update = append(plan.Update, key)
+		default:
+			plan.Unchanged++
+		}
+	}
+	slices.Sort(plan.Create)
+	slices.Sort(plan.Update)
+	slices.Sort(plan.Delete)
+
+	if c.dryRun {
+		if ok, err := c.WriteJSON(out, plan); ok {
+			return err
+		}
+		for _, key := range plan.Create {
+			text.Output(out, "+ %s", key)
+		}
+		for _, key := range plan.Update {
+			text.Output(out, "~ %s", key)
+		}
+		for _, key := range plan.Delete {
+			text.Output(out, "- %s", key)
+		}
+		text.Break(out)
+		text.Output(out, "Plan: %d to create, %d to update, %d to delete, %d unchanged", len(plan.Create), len(plan.Update), len(plan.Delete), plan.Unchanged)
+		return nil
+	}
+
+	if len(plan.Delete) > 0 && !c.Globals.Flags.AutoYes && !c.Globals.Flags.NonInteractive && !c.JSONOutput.Enabled {
+		text.Warning(out, "This will delete %d items from your store!\n\n", len(plan.Delete))
+		cont, err := text.AskYesNo(out, "Are you sure you want to continue? [y/N]: ", in)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			return nil
+		}
+		text.Break(out)
+	}
+
+	if err := c.apply(items, plan); err != nil {
+		return err
+	}
+
+	if ok, err := c.WriteJSON(out, plan); ok {
+		return err
+	}
+	text.Success(out, "Imported %s into Config Store '%s' (%d created, %d updated, %d deleted, %d unchanged)", c.filePath, c.storeID, len(plan.Create), len(plan.Update), len(plan.Delete), plan.Unchanged)
+	return nil
+}
+
+// apply creates, updates and deletes the items in batches, which are processed
+// concurrently.
+func (c *ImportCommand) apply(items map[string]string, plan importPlan) error {
+	type operation struct {
+		key    string
+		delete bool
+	}
+	var ops []operation
+	for _, key := range slices.Concat(plan.Create, plan.Update) {
+		ops = append(ops, operation{key: key})
+	}
+	for _, key := range plan.Delete {
+		ops = append(ops, operation{key: key, delete: true})
+	}
+
+	batchSize := batchLimit
+	if c.batchSize.WasSet {
+		batchSize = c.batchSize.Value
+	}
+	poolSize := deleteKeysConcurrencyLimit
+	if c.concurrency.WasSet {
+		poolSize = c.concurrency.Value
+	}
+	var (
+		failedKeys []string
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+	)
+	semaphore := make(chan struct{}, poolSize)
+
+	for batch := range slices.Chunk(ops, batchSize) {
+		wg.Add(1)
+		go func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			defer wg.Done()
+
+			for _, op := range batch {
+				var err error
+				if op.delete {
+					err = c.Globals.APIClient.DeleteConfigStoreItem(context.TODO(), &fastly.DeleteConfigStoreItemInput{
+						StoreID: c.storeID,
+						Key:     op.key,
+					})
+				} else {
+					_, err = c.Globals.APIClient.UpdateConfigStoreItem(context.TODO(), &fastly.UpdateConfigStoreItemInput{
+						StoreID: c.storeID,
+						Key:     op.key,
+						Value:   items[op.key],
+						Upsert:  true,
+					})
+				}
+				if err != nil {
+					c.Globals.ErrLog.Add(fmt.Errorf("failed to import key '%s': %s", op.key, err))
+					mu.Lock()
+					failedKeys = append(failedKeys, op.key)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failedKeys) > 0 {
+		slices.Sort(failedKeys)
+		return fmt.Errorf("failed to import keys: %s", strings.Join(failedKeys, ", "))
+	}
+	return nil
+}
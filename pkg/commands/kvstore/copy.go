@@ -0,0 +1,95 @@
+package kvstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/storecopy"
+)
+
+// NewCopyCommand returns a usable command registered under the parent.
+func NewCopyCommand(parent argparser.Registerer, g *global.Data) *CopyCommand {
+	c := CopyCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+
+	c.CmdClause = parent.Command("copy", "Copy the keys (with their value and metadata) of a KV Store to another KV Store")
+	c.opts.RegisterFlags(c.CmdClause)
+
+	return &c
+}
+
+// CopyCommand calls the Fastly API to copy the keys of a kv store.
+type CopyCommand struct {
+	argparser.Base
+	opts storecopy.Options
+}
+
+// Exec invokes the application logic for the command.
+func (c *CopyCommand) Exec(_ io.Reader, out io.Writer) error {
+	p := c.Globals.APIClient.NewListKVStoreKeysPaginator(context.TODO(), &fastly.ListKVStoreKeysInput{
+		StoreID: c.opts.From,
+		Prefix:  c.opts.Prefix,
+	})
+	var keys []string
+	for p.Next() {
+		keys = append(keys, p.Keys()...)
+	}
+	if err := p.Err(); err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	pairs, err := c.opts.Plan(keys)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	if c.opts.DryRun {
+		storecopy.PrintPlan(out, pairs)
+		return nil
+	}
+
+	err = storecopy.Copy(out, c.opts, pairs, c.copyKey)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+	}
+	return err
+}
+
+// copyKey copies the value and metadata of a key.
+func (c *CopyCommand) copyKey(p storecopy.Pair) error {
+	item, err := c.Globals.APIClient.GetKVStoreItem(context.TODO(), &fastly.GetKVStoreItemInput{
+		StoreID: c.opts.From,
+		Key:     p.From,
+	})
+	if err != nil {
+		return err
+	}
+	var value []byte
+	if item.Value != nil {
+		defer func() {
+			_ = item.Value.Close()
+		}()
+		if value, err = io.ReadAll(item.Value); err != nil {
+			return fmt.Errorf("error reading the value: %w", err)
+		}
+	}
+
+	input := &fastly.InsertKVStoreKeyInput{
+		StoreID: c.opts.To,
+		Key:     p.To,
+		Value:   string(value),
+	}
+	if item.Metadata != "" {
+		input.Metadata = &item.Metadata
+	}
+	return c.Globals.APIClient.InsertKVStoreKey(context.TODO(), input)
+}
@@ -0,0 +1,211 @@
+package kvstore
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/kvstoreentry"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// item is a key in the NDJSON format written by `kv-store export` and read by
+// `kv-store import`. The value is base64 encoded.
+type item struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Metadata   string `json:"metadata,omitempty"`
+	Generation uint64 `json:"generation,omitempty"`
+}
+
+// ExportCommand calls the Fastly API to write every key of a kv store to a
+// file.
+type ExportCommand struct {
+	argparser.Base
+
+	concurrency int
+	output      string
+	storeID     string
+}
+
+// NewExportCommand returns a usable command registered under the parent.
+func NewExportCommand(parent argparser.Registerer, g *global.Data) *ExportCommand {
+	c := ExportCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("export", "Write the keys of a KV Store (with their value, metadata and generation) to a NDJSON file")
+
+	// Required.
+	c.CmdClause.Flag("output", "Path of the NDJSON file to write (compressed with gzip when the path ends with .gz)").Short('o').Required().StringVar(&c.output)
+	c.CmdClause.Flag("store-id", "Store ID").Short('s').Required().StringVar(&c.storeID)
+
+	// Optional.
+	c.CmdClause.Flag("concurrency", "The number of concurrent API requests").Default(strconv.Itoa(kvstoreentry.SyncPoolSize)).Short('r').IntVar(&c.concurrency)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ExportCommand) Exec(_ io.Reader, out io.Writer) (err error) {
+	if c.concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency value: %d", c.concurrency)
+	}
+
+	f, err := os.Create(c.output)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	// complete is set once the backup is fully written, after which later
+	// errors (e.g. from the spinner) mustn't remove it.
+	var complete bool
+	defer func() {
+		// Don't leave an incomplete backup behind.
+		if err != nil && !complete {
+			_ = f.Close()
+			_ = os.Remove(c.output)
+		}
+	}()
+
+	var (
+		gz *gzip.Writer
+		w  io.Writer = f
+	)
+	if strings.HasSuffix(c.output, ".gz") {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	spinner, err := text.NewSpinner(out)
+	if err != nil {
+		return err
+	}
+	if err = spinner.Start(); err != nil {
+		return err
+	}
+	msg := "Exporting keys"
+	spinner.Message(msg + "...")
+
+	count, err := c.export(w, func(n int) {
+		spinner.Message(msg + "..." + strconv.Itoa(n))
+	})
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = f.Close()
+		complete = err == nil
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		spinner.StopFailMessage(msg)
+		if spinErr := spinner.StopFail(); spinErr != nil {
+			return fmt.Errorf(text.SpinnerErrWrapper, spinErr, err)
+		}
+		return err
+	}
+
+	spinner.StopMessage(fmt.Sprintf("Exported keys: %d", count))
+	if err = spinner.Stop(); err != nil {
+		return err
+	}
+	text.Success(out, "\nExported %d keys from KV Store '%s' to %s", count, c.storeID, c.output)
+	return nil
+}
+
+// export writes every key of the store to w, one JSON object per line, in the
+// order they are listed. Each page of keys is fetched concurrently.
+func (c *ExportCommand) export(w io.Writer, progress func(int)) (int, error) {
+	p := c.Globals.APIClient.NewListKVStoreKeysPaginator(context.TODO(), &fastly.ListKVStoreKeysInput{
+		StoreID: c.storeID,
+	})
+	enc := json.NewEncoder(w)
+
+	var count int
+	for p.Next() {
+		keys := p.Keys()
+		items := make([]*item, len(keys))
+		errs := make([]error, len(keys))
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, c.concurrency)
+		for i, key := range keys {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				items[i], errs[i] = c.getItem(key)
+			}()
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return count, err
+		}
+		for _, it := range items {
+			// The key was deleted after it was listed.
+			if it == nil {
+				continue
+			}
+			if err := enc.Encode(it); err != nil {
+				return count, err
+			}
+			count++
+		}
+		progress(count)
+	}
+	if err := p.Err(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// getItem returns the value, metadata and generation of a key, or nil if the
+// key doesn't exist.
+func (c *ExportCommand) getItem(key string) (*item, error) {
+	o, err := c.Globals.APIClient.GetKVStoreItem(context.TODO(), &fastly.GetKVStoreItemInput{
+		StoreID: c.storeID,
+		Key:     key,
+	})
+	if err != nil {
+		var httpErr *fastly.HTTPError
+		if errors.As(err, &httpErr) && httpErr.IsNotFound() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting the key '%s': %w", key, err)
+	}
+
+	var value []byte
+	if o.Value != nil {
+		defer func() {
+			_ = o.Value.Close()
+		}()
+		value, err = io.ReadAll(o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the value of the key '%s': %w", key, err)
+		}
+	}
+
+	return &item{
+		Key:        key,
+		Value:      base64.StdEncoding.EncodeToString(value),
+		Metadata:   o.Metadata,
+		Generation: o.Generation,
+	}, nil
+}
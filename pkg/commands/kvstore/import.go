@@ -0,0 +1,229 @@
+package kvstore
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// ImportBatchSize is the number of keys sent in each request to the batch API
+// endpoint.
+const ImportBatchSize int = 1000
+
+// ImportCommand calls the Fastly API to insert the keys of a file written by
+// `kv-store export` into a new or existing kv store.
+type ImportCommand struct {
+	argparser.Base
+	argparser.JSONOutput
+
+	filePath string
+	location string
+	name     argparser.OptionalString
+	storeID  argparser.OptionalString
+}
+
+// NewImportCommand returns a usable command registered under the parent.
+func NewImportCommand(parent argparser.Registerer, g *global.Data) *ImportCommand {
+	c := ImportCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("import", "Insert the keys of a NDJSON file written by 'kv-store export' into a new or existing KV Store")
+
+	// Required.
+	c.CmdClause.Flag("file", `Path to a file containing individual JSON objects (e.g., {"key":"...","value":"base64_encoded_value","metadata":"..."}) separated by new-line delimiter, optionally compressed with gzip`).Short('f').Required().StringVar(&c.filePath)
+
+	// Optional.
+	c.RegisterFlagBool(c.JSONFlag()) // --json
+	c.CmdClause.Flag("location", "Regional location of the KV Store created with --name").Short('l').HintOptions(locations...).EnumVar(&c.location, locations...)
+	c.CmdClause.Flag("name", "Name of a KV Store to create").Short('n').Action(c.name.Set).StringVar(&c.name.Value)
+	c.CmdClause.Flag("store-id", "ID of an existing KV Store to merge the keys into (existing keys are overwritten)").Short('s').Action(c.storeID.Set).StringVar(&c.storeID.Value)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ImportCommand) Exec(_ io.Reader, out io.Writer) error {
+	if c.Globals.Verbose() && c.JSONOutput.Enabled {
+		return fsterr.ErrInvalidVerboseJSONCombo
+	}
+	if c.name.WasSet == c.storeID.WasSet {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("invalid flag combination"),
+			Remediation: "Provide either --store-id (to merge the keys into an existing store) or --name (to create a new store), not both.",
+		}
+	}
+	if c.location != "" && !c.name.WasSet {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("--location requires --name"),
+			Remediation: "Remove --location, which only applies to a new store.",
+		}
+	}
+
+	// Validate the whole file before changing anything.
+	total, err := c.readItems(func(*item) error { return nil })
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	storeID := c.storeID.Value
+	if c.name.WasSet {
+		store, err := c.Globals.APIClient.CreateKVStore(context.TODO(), &fastly.CreateKVStoreInput{
+			Name:     c.name.Value,
+			Location: c.location,
+		})
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		storeID = store.StoreID
+		if !c.JSONOutput.Enabled {
+			text.Success(out, "Created KV Store '%s' (%s)", store.Name, store.StoreID)
+		}
+	}
+
+	spinner, err := text.NewSpinner(out)
+	if err != nil {
+		return err
+	}
+	// A spinner produces output and is incompatible with JSON expected output.
+	if !c.JSONOutput.Enabled {
+		if err := spinner.Start(); err != nil {
+			return err
+		}
+	}
+	msg := "Importing keys"
+	spinner.Message(fmt.Sprintf("%s...0 of %d", msg, total))
+
+	var (
+		batch    bytes.Buffer
+		enc      = json.NewEncoder(&batch)
+		imported int
+		pending  int
+	)
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		err := c.Globals.APIClient.BatchModifyKVStoreKey(context.TODO(), &fastly.BatchModifyKVStoreKeyInput{
+			StoreID: storeID,
+			Body:    &batch,
+		})
+		if err != nil {
+			return fmt.Errorf("error importing keys %d to %d: %w", imported+1, imported+pending, err)
+		}
+		imported += pending
+		pending = 0
+		batch.Reset()
+		spinner.Message(fmt.Sprintf("%s...%d of %d", msg, imported, total))
+		return nil
+	}
+
+	_, err = c.readItems(func(it *item) error {
+		// The generation marker is specific to the store it was exported from.
+		if err := enc.Encode(item{Key: it.Key, Value: it.Value, Metadata: it.Metadata}); err != nil {
+			return err
+		}
+		pending++
+		if pending == ImportBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		if !c.JSONOutput.Enabled {
+			spinner.StopFailMessage(fmt.Sprintf("%s: %d of %d", msg, imported, total))
+			if spinErr := spinner.StopFail(); spinErr != nil {
+				return fmt.Errorf(text.SpinnerErrWrapper, spinErr, err)
+			}
+		}
+		return err
+	}
+
+	if c.JSONOutput.Enabled {
+		o := struct {
+			ID   string `json:"store_id"`
+			Keys int    `json:"keys"`
+		}{
+			storeID,
+			imported,
+		}
+		_, err := c.WriteJSON(out, o)
+		return err
+	}
+
+	spinner.StopMessage(fmt.Sprintf("Imported keys: %d", imported))
+	if err := spinner.Stop(); err != nil {
+		return err
+	}
+	text.Success(out, "\nImported %d keys into KV Store '%s'", imported, storeID)
+	return nil
+}
+
+// readItems calls fn for each item of the file and returns the number of
+// items. The file is decompressed when it starts with the gzip header.
+func (c *ImportCommand) readItems(fn func(*item) error) (int, error) {
+	f, err := os.Open(c.filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if header, err := br.Peek(2); err == nil && header[0] == 0x1f && header[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, fmt.Errorf("error reading %s: %w", c.filePath, err)
+		}
+		defer func() {
+			_ = gz.Close()
+		}()
+		r = gz
+	}
+
+	dec := json.NewDecoder(r)
+	var n int
+	for {
+		var it item
+		err := dec.Decode(&it)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("error parsing %s: %w", c.filePath, err)
+		}
+		n++
+		if it.Key == "" {
+			return n, fmt.Errorf("error parsing %s: object %d is missing a key", c.filePath, n)
+		}
+		if _, err := base64.StdEncoding.DecodeString(it.Value); err != nil {
+			return n, fmt.Errorf("error parsing %s: the value of the key '%s' isn't base64 encoded: %w", c.filePath, it.Key, err)
+		}
+		if err := fn(&it); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
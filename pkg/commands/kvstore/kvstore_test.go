@@ -2,9 +2,14 @@ package kvstore_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,9 +17,11 @@ import (
 
 	root "github.com/fastly/cli/pkg/commands/kvstore"
 	fstfmt "github.com/fastly/cli/pkg/fmt"
+	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/cli/pkg/testutil"
 	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/threadsafe"
 )
 
 func TestCreateStoreCommand(t *testing.T) {
@@ -109,6 +116,54 @@ func TestCreateStoreCommand(t *testing.T) {
 	testutil.RunCLIScenarios(t, []string{root.CommandName, "create"}, scenarios)
 }
 
+func TestCopyStoreCommand(t *testing.T) {
+	const (
+		fromID = "store-id-123"
+		toID   = "store-id-456"
+	)
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Args: fmt.Sprintf("--from %s --to %s --prefix assets/ --rename v2/{{.Name}}", fromID, toID),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, i *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					if i.StoreID != fromID || i.Prefix != "assets/" {
+						return &mockKVStoreEntriesPaginator{}
+					}
+					return &mockKVStoreEntriesPaginator{keys: []string{"assets/app.js"}}
+				},
+				GetKVStoreItemFn: func(_ context.Context, i *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{
+						Metadata: "meta",
+						Value:    io.NopCloser(strings.NewReader("value-" + i.Key)),
+					}, nil
+				},
+				InsertKVStoreKeyFn: func(_ context.Context, i *fastly.InsertKVStoreKeyInput) error {
+					if i.StoreID != toID || i.Key != "v2/app.js" || i.Value != "value-assets/app.js" || i.Metadata == nil || *i.Metadata != "meta" {
+						return fmt.Errorf("unexpected insert %+v", i)
+					}
+					return nil
+				},
+			},
+			WantOutput: fmt.Sprintf("Copied 1 keys from store '%s' to '%s'", fromID, toID),
+		},
+		{
+			Args: fmt.Sprintf("--from %s --to %s", fromID, toID),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoreEntriesPaginator{keys: []string{"foo"}}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{}, errors.New("invalid request")
+				},
+			},
+			WantError: "failed to copy 1 keys",
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "copy"}, scenarios)
+}
+
 func TestDeleteStoreCommand(t *testing.T) {
 	const storeID = "test123"
 	errStoreNotFound := errors.New("store not found")
@@ -275,6 +330,123 @@ func TestListStoresCommand(t *testing.T) {
 	testutil.RunCLIScenarios(t, []string{root.CommandName, "list"}, scenarios)
 }
 
+func TestExportImportStoreCommand(t *testing.T) {
+	const storeID = "store-id-123"
+	backup := filepath.Join(t.TempDir(), "backup.ndjson.gz")
+
+	exportScenarios := []testutil.CLIScenario{
+		{
+			Args:      fmt.Sprintf("--output %s", backup),
+			WantError: "error parsing arguments: required flag --store-id not provided",
+		},
+		{
+			Args: fmt.Sprintf("--store-id %s --output %s", storeID, backup),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoreEntriesPaginator{keys: []string{"foo", "bar"}}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{}, errors.New("invalid request")
+				},
+			},
+			WantError: "error getting the key 'foo': invalid request",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				if _, err := os.Stat(backup); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("want an incomplete backup to be removed, have %v", err)
+				}
+			},
+		},
+		{
+			Args: fmt.Sprintf("--store-id %s --output %s", storeID, backup),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoreEntriesPaginator{keys: []string{"foo", "bar"}}
+				},
+				GetKVStoreItemFn: func(_ context.Context, i *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{
+						Generation: 42,
+						Metadata:   "meta-" + i.Key,
+						Value:      io.NopCloser(strings.NewReader("value-" + i.Key)),
+					}, nil
+				},
+			},
+			WantOutput: fmt.Sprintf("Exported 2 keys from KV Store '%s' to %s", storeID, backup),
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				f, err := os.Open(backup)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer func() {
+					_ = f.Close()
+				}()
+				gz, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := io.ReadAll(gz)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := `{"key":"foo","value":"dmFsdWUtZm9v","metadata":"meta-foo","generation":42}` + "\n" +
+					`{"key":"bar","value":"dmFsdWUtYmFy","metadata":"meta-bar","generation":42}` + "\n"
+				if string(data) != want {
+					t.Errorf("want %s, have %s", want, data)
+				}
+			},
+		},
+	}
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "export"}, exportScenarios)
+
+	importScenarios := []testutil.CLIScenario{
+		{
+			Args:      fmt.Sprintf("--file %s", backup),
+			WantError: "invalid flag combination",
+		},
+		{
+			Args:      fmt.Sprintf("--file %s --store-id %s --location EU", backup, storeID),
+			WantError: "--location requires --name",
+		},
+		{
+			Args: fmt.Sprintf("--file %s --store-id %s", backup, storeID),
+			API: &mock.API{
+				BatchModifyKVStoreKeyFn: func(_ context.Context, i *fastly.BatchModifyKVStoreKeyInput) error {
+					if i.StoreID != storeID {
+						return fmt.Errorf("unexpected store %s", i.StoreID)
+					}
+					data, err := io.ReadAll(i.Body)
+					if err != nil {
+						return err
+					}
+					want := `{"key":"foo","value":"dmFsdWUtZm9v","metadata":"meta-foo"}` + "\n" +
+						`{"key":"bar","value":"dmFsdWUtYmFy","metadata":"meta-bar"}` + "\n"
+					if string(data) != want {
+						return fmt.Errorf("want %s, have %s", want, data)
+					}
+					return nil
+				},
+			},
+			WantOutput: fmt.Sprintf("Imported 2 keys into KV Store '%s'", storeID),
+		},
+		{
+			Args: fmt.Sprintf("--file %s --name restored --json", backup),
+			API: &mock.API{
+				CreateKVStoreFn: func(_ context.Context, i *fastly.CreateKVStoreInput) (*fastly.KVStore, error) {
+					return &fastly.KVStore{StoreID: "store-id-456", Name: i.Name}, nil
+				},
+				BatchModifyKVStoreKeyFn: func(_ context.Context, _ *fastly.BatchModifyKVStoreKeyInput) error {
+					return nil
+				},
+			},
+			WantOutput: fstfmt.JSON(`{"store_id": "store-id-456", "keys": 2}`),
+		},
+		{
+			Args:      fmt.Sprintf("--file %s --name restored", filepath.Join("testdata", "invalid.ndjson")),
+			WantError: "isn't base64 encoded",
+		},
+	}
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "import"}, importScenarios)
+}
+
 func fmtStore(ks *fastly.KVStore) string {
 	var b bytes.Buffer
 	text.PrintKVStore(&b, "", ks)
@@ -290,3 +462,22 @@ func fmtStores(ks *fastly.ListKVStoresResponse) string {
 	}
 	return b.String()
 }
+
+type mockKVStoreEntriesPaginator struct {
+	done bool
+	keys []string
+}
+
+func (m *mockKVStoreEntriesPaginator) Next() bool {
+	ret := !m.done
+	m.done = true
+	return ret
+}
+
+func (m *mockKVStoreEntriesPaginator) Keys() []string {
+	return m.keys
+}
+
+func (m *mockKVStoreEntriesPaginator) Err() error {
+	return nil
+}
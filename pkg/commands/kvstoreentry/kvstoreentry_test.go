@@ -599,6 +599,227 @@ func TestListCommand(t *testing.T) {
 	testutil.RunCLIScenarios(t, []string{root.CommandName, "list"}, scenarios)
 }
 
+func TestSyncCommand(t *testing.T) {
+	const (
+		storeID = "store-id-123"
+		// fooHash is the SHA-256 hash of testdata/example/foo.txt.
+		fooHash = "1706b5c18e4358041b463995efc30f8f721766fab0e018d50d85978b46df013c"
+		// valueHash is the SHA-256 hash of the values in testdata/data.json.
+		valueHash = "8ec121c93e4a0de65f26e1500cb501e383531efb2c2ca9ec1d457478d6d3627b"
+	)
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Args:      fmt.Sprintf("--store-id %s", storeID),
+			WantError: "invalid flag combination",
+		},
+		{
+			Name: "validate --dry-run with an unchanged key",
+			Args: fmt.Sprintf("--store-id %s --dir %s --dry-run", storeID, filepath.Join("testdata", "example")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"foo.txt", "stale"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Value: io.NopCloser(strings.NewReader("FOO\n")), Metadata: `{"owner":"web"}`}, nil
+				},
+			},
+			WantOutputs: []string{
+				"Plan: 0 to add, 0 to update, 0 to delete, 1 unchanged",
+				"1 keys in the store aren't present locally",
+			},
+		},
+		{
+			Name: "validate --dry-run compares the values",
+			Args: fmt.Sprintf("--store-id %s --dir %s --dir-allow-hidden --delete --dry-run", storeID, filepath.Join("testdata", "example")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"foo.txt", "stale"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Value: io.NopCloser(strings.NewReader("BAR\n"))}, nil
+				},
+			},
+			WantOutputs: []string{
+				"+ .hiddenfile",
+				"~ foo.txt",
+				"- stale",
+				"Plan: 1 to add, 1 to update, 1 to delete, 0 unchanged",
+			},
+		},
+		{
+			Name: "validate --file uploads the changed keys",
+			Args: fmt.Sprintf("--store-id %s --file %s --delete --auto-yes", storeID, filepath.Join("testdata", "data.json")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"file-example-1", "stale"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Value: io.NopCloser(strings.NewReader("VALUE"))}, nil
+				},
+				InsertKVStoreKeyFn: func(_ context.Context, i *fastly.InsertKVStoreKeyInput) error {
+					if i.Key != "file-example-2" || i.Value != "VALUE" {
+						return fmt.Errorf("unexpected upload of %s", i.Key)
+					}
+					if i.Metadata != nil {
+						return errors.New("expected no metadata")
+					}
+					return nil
+				},
+				DeleteKVStoreKeyFn: func(_ context.Context, i *fastly.DeleteKVStoreKeyInput) error {
+					if i.Key != "stale" {
+						return fmt.Errorf("unexpected delete of %s", i.Key)
+					}
+					return nil
+				},
+			},
+			WantOutputs: []string{
+				"Synced keys: 1 added, 0 updated, 1 deleted",
+				fmt.Sprintf("Synced KV Store '%s' (1 unchanged)", storeID),
+			},
+		},
+		{
+			Name: "validate --dir keeps the metadata of updated keys",
+			Args: fmt.Sprintf("--store-id %s --dir %s --auto-yes", storeID, filepath.Join("testdata", "example")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"foo.txt"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Value: io.NopCloser(strings.NewReader("BAR\n")), Metadata: `{"owner":"web"}`}, nil
+				},
+				InsertKVStoreKeyFn: func(_ context.Context, i *fastly.InsertKVStoreKeyInput) error {
+					if i.Key != "foo.txt" {
+						return fmt.Errorf("unexpected upload of %s", i.Key)
+					}
+					if i.Metadata == nil || *i.Metadata != `{"owner":"web"}` {
+						return errors.New("expected the metadata to be kept")
+					}
+					return nil
+				},
+			},
+			WantOutputs: []string{
+				"Synced keys: 0 added, 1 updated, 0 deleted",
+			},
+		},
+		{
+			Name: "validate --file syncs the metadata",
+			Args: fmt.Sprintf("--store-id %s --file %s", storeID, filepath.Join("testdata", "sync.json")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"file-example-1", "file-example-3"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Value: io.NopCloser(strings.NewReader("VALUE")), Metadata: `{"owner":"old"}`}, nil
+				},
+				InsertKVStoreKeyFn: func(_ context.Context, i *fastly.InsertKVStoreKeyInput) error {
+					want := map[string]string{
+						"file-example-1": `{"owner":"web"}`,
+						"file-example-2": `{"owner":"api"}`,
+					}[i.Key]
+					if want == "" {
+						return fmt.Errorf("unexpected upload of %s", i.Key)
+					}
+					if i.Metadata == nil || *i.Metadata != want {
+						return fmt.Errorf("expected the metadata of %s to be %s", i.Key, want)
+					}
+					return nil
+				},
+			},
+			WantOutputs: []string{
+				"Synced keys: 1 added, 1 updated, 0 deleted",
+				fmt.Sprintf("Synced KV Store '%s' (1 unchanged)", storeID),
+			},
+		},
+		{
+			Name: "validate --hash-metadata compares the recorded hash",
+			Args: fmt.Sprintf("--store-id %s --dir %s --hash-metadata --dry-run", storeID, filepath.Join("testdata", "example")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"foo.txt"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Metadata: fmt.Sprintf(`{"sha256":%q}`, fooHash)}, nil
+				},
+			},
+			WantOutput: "Plan: 0 to add, 0 to update, 0 to delete, 1 unchanged",
+		},
+		{
+			Name: "validate --hash-metadata doesn't overwrite other metadata",
+			Args: fmt.Sprintf("--store-id %s --dir %s --hash-metadata", storeID, filepath.Join("testdata", "example")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{
+						next: true,
+						keys: []string{"foo.txt"},
+					}
+				},
+				GetKVStoreItemFn: func(_ context.Context, _ *fastly.GetKVStoreItemInput) (fastly.GetKVStoreItemOutput, error) {
+					return fastly.GetKVStoreItemOutput{Metadata: fmt.Sprintf(`{"sha256":%q,"owner":"web"}`, fooHash)}, nil
+				},
+			},
+			WantError:  "failed to compare the keys with the store",
+			WantOutput: "the item has metadata that --hash-metadata would overwrite",
+		},
+		{
+			Name: "validate --hash-metadata records the hash of uploaded keys",
+			Args: fmt.Sprintf("--store-id %s --file %s --hash-metadata", storeID, filepath.Join("testdata", "data.json")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{}
+				},
+				InsertKVStoreKeyFn: func(_ context.Context, i *fastly.InsertKVStoreKeyInput) error {
+					if i.Metadata == nil || *i.Metadata != fmt.Sprintf(`{"sha256":%q}`, valueHash) {
+						return errors.New("expected the hash to be recorded in the metadata")
+					}
+					return nil
+				},
+			},
+			WantOutput: "Synced keys: 2 added, 0 updated, 0 deleted",
+		},
+		{
+			Args:      fmt.Sprintf("--store-id %s --file %s --hash-metadata", storeID, filepath.Join("testdata", "sync.json")),
+			WantError: "the key 'file-example-1' has metadata, which --hash-metadata would overwrite",
+		},
+		{
+			Args:      fmt.Sprintf("--store-id %s --file %s --prefix other-", storeID, filepath.Join("testdata", "data.json")),
+			WantError: "the key 'file-example-1' doesn't match the prefix 'other-'",
+		},
+		{
+			Args: fmt.Sprintf("--store-id %s --dir %s", storeID, filepath.Join("testdata", "example")),
+			API: &mock.API{
+				NewListKVStoreKeysPaginatorFn: func(_ context.Context, _ *fastly.ListKVStoreKeysInput) fastly.PaginatorKVStoreEntries {
+					return &mockKVStoresEntriesPaginator{}
+				},
+				InsertKVStoreKeyFn: func(_ context.Context, _ *fastly.InsertKVStoreKeyInput) error {
+					return errors.New("invalid request")
+				},
+			},
+			WantError: "failed to sync all the keys",
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "sync"}, scenarios)
+}
+
 type mockKVStoresEntriesPaginator struct {
 	next bool
 	keys []string
@@ -0,0 +1,607 @@
+package kvstoreentry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// SyncPoolSize is the default number of concurrent API requests.
+const SyncPoolSize int = 50
+
+// syncMetadata is the item metadata written with --hash-metadata, recording
+// the hash of the value so that unchanged items can be detected without
+// downloading them.
+type syncMetadata struct {
+	SHA256 string `json:"sha256"`
+}
+
+// syncEntry is a local key and the source of its value.
+type syncEntry struct {
+	key  string
+	hash string
+	// path is the file containing the value (--dir).
+	path string
+	// value is the decoded value (--file).
+	value []byte
+	// metadata is the metadata of the item (--file). When it isn't set, the
+	// metadata of an existing item is kept.
+	metadata *string
+	// remoteMetadata is the metadata of the item in the store.
+	remoteMetadata string
+	// remove indicates the key is deleted from the store.
+	remove bool
+}
+
+// syncPlan is the set of changes needed for the store to match the local
+// content.
+type syncPlan struct {
+	Add       []string `json:"add"`
+	Update    []string `json:"update"`
+	Delete    []string `json:"delete"`
+	Unchanged int      `json:"unchanged"`
+	// Extraneous is the number of remote keys that aren't present locally,
+	// which are only deleted with --delete.
+	Extraneous int `json:"extraneous"`
+}
+
+// SyncCommand calls the Fastly API to make the keys of a kv store match the
+// content of a local directory or NDJSON file.
+type SyncCommand struct {
+	argparser.Base
+	argparser.JSONOutput
+
+	concurrency    int
+	delete         bool
+	dirAllowHidden bool
+	dirPath        string
+	dryRun         bool
+	filePath       string
+	hashMetadata   bool
+	prefix         string
+	storeID        string
+}
+
+// NewSyncCommand returns a usable command registered under the parent.
+func NewSyncCommand(parent argparser.Registerer, g *global.Data) *SyncCommand {
+	c := SyncCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("sync", "Upload the keys of a local directory or NDJSON file that differ from those in the store (the values of existing keys are downloaded to compare them, unless --hash-metadata is used)")
+
+	// Required.
+	c.CmdClause.Flag("store-id", "Store ID").Short('s').Required().StringVar(&c.storeID)
+
+	// Optional.
+	c.CmdClause.Flag("concurrency", "The number of concurrent API requests").Default(strconv.Itoa(SyncPoolSize)).Short('r').IntVar(&c.concurrency)
+	c.CmdClause.Flag("delete", "Delete keys from the store (matching --prefix) that aren't present locally").BoolVar(&c.delete)
+	c.CmdClause.Flag("dir", "Path to a directory of files where the path of the file (relative to the directory, prefixed with --prefix) is the key and the file contents is the value").StringVar(&c.dirPath)
+	c.CmdClause.Flag("dir-allow-hidden", "Allow hidden files and directories (e.g. dot files) to be included (skipped by default)").BoolVar(&c.dirAllowHidden)
+	c.CmdClause.Flag("dry-run", "Print the keys that would be added, updated and deleted without changing the store").BoolVar(&c.dryRun)
+	c.CmdClause.Flag("file", `Path to a file containing individual JSON objects (e.g., {"key":"...","value":"base64_encoded_value","metadata":"..."}) separated by new-line delimiter`).StringVar(&c.filePath)
+	c.CmdClause.Flag("hash-metadata", "Record the SHA-256 hash of each uploaded value in its metadata, so that later syncs compare the hashes rather than downloading the values (keys with other metadata can't be synced)").BoolVar(&c.hashMetadata)
+	c.RegisterFlagBool(c.JSONFlag()) // --json
+	c.CmdClause.Flag("prefix", "Restrict the sync to keys that match this prefix").StringVar(&c.prefix)
+
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *SyncCommand) Exec(in io.Reader, out io.Writer) error {
+	if c.Globals.Verbose() && c.JSONOutput.Enabled {
+		return fsterr.ErrInvalidVerboseJSONCombo
+	}
+	if (c.dirPath == "") == (c.filePath == "") {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("invalid flag combination"),
+			Remediation: "Provide either --dir or --file, not both.",
+		}
+	}
+	if c.concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency value: %d", c.concurrency)
+	}
+
+	var (
+		local []*syncEntry
+		err   error
+	)
+	if c.dirPath != "" {
+		local, err = c.readDir()
+	} else {
+		local, err = c.readFile()
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	spinner, err := text.NewSpinner(out)
+	if err != nil {
+		return err
+	}
+	// A spinner produces output and is incompatible with JSON expected output.
+	if !c.JSONOutput.Enabled {
+		if err := spinner.Start(); err != nil {
+			return err
+		}
+		spinner.Message("Comparing keys...")
+	}
+	plan, failed, err := c.plan(local)
+	if !c.JSONOutput.Enabled {
+		if err != nil || len(failed) > 0 {
+			spinner.StopFailMessage("Comparing keys")
+			if err := spinner.StopFail(); err != nil {
+				return fmt.Errorf("failed to stop spinner: %w", err)
+			}
+		} else {
+			spinner.StopMessage("Comparing keys")
+			if err := spinner.Stop(); err != nil {
+				return fmt.Errorf("failed to stop spinner: %w", err)
+			}
+		}
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	if len(failed) > 0 {
+		return c.processErrors(out, failed, "failed to compare the keys with the store")
+	}
+
+	if c.dryRun {
+		if ok, err := c.WriteJSON(out, plan); ok {
+			return err
+		}
+		c.printPlan(out, plan)
+		return nil
+	}
+
+	if len(plan.Delete) > 0 && !c.Globals.Flags.AutoYes && !c.Globals.Flags.NonInteractive && !c.JSONOutput.Enabled {
+		text.Warning(out, "This will delete %d keys from your store!\n\n", len(plan.Delete))
+		cont, err := text.AskYesNo(out, "Are you sure you want to continue? [y/N]: ", in)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			return nil
+		}
+		text.Break(out)
+	}
+
+	if !c.JSONOutput.Enabled {
+		if err := spinner.Start(); err != nil {
+			return err
+		}
+		spinner.Message("Syncing keys...")
+	}
+	failed = c.apply(local, plan)
+	if !c.JSONOutput.Enabled {
+		msg := fmt.Sprintf("Synced keys: %d added, %d updated, %d deleted", len(plan.Add), len(plan.Update), len(plan.Delete))
+		if len(failed) > 0 {
+			spinner.StopFailMessage(msg)
+			if err := spinner.StopFail(); err != nil {
+				return fmt.Errorf("failed to stop spinner: %w", err)
+			}
+		} else {
+			spinner.StopMessage(msg)
+			if err := spinner.Stop(); err != nil {
+				return fmt.Errorf("failed to stop spinner: %w", err)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return c.processErrors(out, failed, "failed to sync all the keys")
+	}
+
+	if ok, err := c.WriteJSON(out, plan); ok {
+		return err
+	}
+	if c.Globals.Verbose() {
+		c.printPlan(out, plan)
+	}
+	if plan.Extraneous > 0 {
+		text.Info(out, "\n%d keys in the store aren't present locally (use --delete to remove them)", plan.Extraneous)
+	}
+	text.Success(out, "\nSynced KV Store '%s' (%d unchanged)", c.storeID, plan.Unchanged)
+	return nil
+}
+
+// readDir returns an entry for each file within the directory (recursively),
+// where the key is the slash separated path relative to the directory.
+func (c *SyncCommand) readDir() ([]*syncEntry, error) {
+	root, err := filepath.Abs(c.dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*syncEntry
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if isHiddenFile(d.Name()) && !c.dirAllowHidden {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Skip symlinks and other non-regular files.
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &syncEntry{
+			key:  c.prefix + filepath.ToSlash(rel),
+			hash: hash,
+			path: path,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readFile returns an entry for each JSON object in the file.
+func (c *SyncCommand) readFile() ([]*syncEntry, error) {
+	f, err := os.Open(c.filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var (
+		entries []*syncEntry
+		seen    = make(map[string]bool)
+	)
+	dec := json.NewDecoder(f)
+	for {
+		var item struct {
+			Key      string  `json:"key"`
+			Value    string  `json:"value"`
+			Metadata *string `json:"metadata"`
+		}
+		err := dec.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", c.filePath, err)
+		}
+		if item.Key == "" {
+			return nil, fmt.Errorf("error parsing %s: an object is missing a key", c.filePath)
+		}
+		if !strings.HasPrefix(item.Key, c.prefix) {
+			return nil, fsterr.RemediationError{
+				Inner:       fmt.Errorf("the key '%s' doesn't match the prefix '%s'", item.Key, c.prefix),
+				Remediation: "Remove the keys that don't match --prefix from the file, or change --prefix.",
+			}
+		}
+		if item.Metadata != nil && c.hashMetadata {
+			return nil, fsterr.RemediationError{
+				Inner:       fmt.Errorf("the key '%s' has metadata, which --hash-metadata would overwrite", item.Key),
+				Remediation: "Remove the metadata from the file, or remove --hash-metadata.",
+			}
+		}
+		if seen[item.Key] {
+			return nil, fmt.Errorf("error parsing %s: the key '%s' is defined more than once", c.filePath, item.Key)
+		}
+		seen[item.Key] = true
+
+		value, err := base64.StdEncoding.DecodeString(item.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding the value of the key '%s': %w", item.Key, err)
+		}
+		sum := sha256.Sum256(value)
+		entries = append(entries, &syncEntry{
+			key:      item.Key,
+			hash:     hex.EncodeToString(sum[:]),
+			value:    value,
+			metadata: item.Metadata,
+		})
+	}
+	return entries, nil
+}
+
+// plan compares the local entries with the keys in the store.
+//
+// The values of the items that exist in the store are downloaded and hashed,
+// unless --hash-metadata is set, in which case the hash recorded in the
+// metadata by a previous sync is compared instead.
+func (c *SyncCommand) plan(local []*syncEntry) (*syncPlan, []ProcessErr, error) {
+	p := c.Globals.APIClient.NewListKVStoreKeysPaginator(context.TODO(), &fastly.ListKVStoreKeysInput{
+		StoreID: c.storeID,
+		Prefix:  c.prefix,
+	})
+	remote := make(map[string]bool)
+	for p.Next() {
+		for _, key := range p.Keys() {
+			remote[key] = true
+		}
+	}
+	if err := p.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	plan := &syncPlan{
+		Add:    []string{},
+		Update: []string{},
+		Delete: []string{},
+	}
+	var existing []*syncEntry
+	for _, e := range local {
+		if remote[e.key] {
+			existing = append(existing, e)
+			delete(remote, e.key)
+		} else {
+			plan.Add = append(plan.Add, e.key)
+		}
+	}
+	plan.Extraneous = len(remote)
+	if c.delete {
+		for key := range remote {
+			plan.Delete = append(plan.Delete, key)
+		}
+		plan.Extraneous = 0
+	}
+
+	var mu sync.Mutex
+	failed := c.forEach(existing, func(e *syncEntry) error {
+		changed, err := c.changed(e)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if changed {
+			plan.Update = append(plan.Update, e.key)
+		} else {
+			plan.Unchanged++
+		}
+		return nil
+	})
+
+	slices.Sort(plan.Add)
+	slices.Sort(plan.Update)
+	slices.Sort(plan.Delete)
+	return plan, failed, nil
+}
+
+// changed reports whether the value or metadata of an item in the store
+// differs from the local entry.
+func (c *SyncCommand) changed(e *syncEntry) (bool, error) {
+	item, err := c.Globals.APIClient.GetKVStoreItem(context.TODO(), &fastly.GetKVStoreItemInput{
+		StoreID: c.storeID,
+		Key:     e.key,
+	})
+	if err != nil {
+		return false, err
+	}
+	if item.Value != nil {
+		defer func() {
+			_ = item.Value.Close()
+		}()
+	}
+
+	e.remoteMetadata = item.Metadata
+	if c.hashMetadata && item.Metadata != "" {
+		var m syncMetadata
+		dec := json.NewDecoder(strings.NewReader(item.Metadata))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&m); err != nil || m.SHA256 == "" {
+			return false, fmt.Errorf("the item has metadata that --hash-metadata would overwrite")
+		}
+		return m.SHA256 != e.hash, nil
+	}
+	if e.metadata != nil && *e.metadata != item.Metadata {
+		return true, nil
+	}
+	if item.Value == nil {
+		return true, nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, item.Value); err != nil {
+		return false, err
+	}
+	return hex.EncodeToString(h.Sum(nil)) != e.hash, nil
+}
+
+// apply uploads the added and updated keys and deletes the extraneous keys.
+func (c *SyncCommand) apply(local []*syncEntry, plan *syncPlan) []ProcessErr {
+	upload := make(map[string]bool, len(plan.Add)+len(plan.Update))
+	for _, key := range plan.Add {
+		upload[key] = true
+	}
+	for _, key := range plan.Update {
+		upload[key] = true
+	}
+
+	var entries []*syncEntry
+	for _, e := range local {
+		if upload[e.key] {
+			entries = append(entries, e)
+		}
+	}
+	for _, key := range plan.Delete {
+		entries = append(entries, &syncEntry{key: key, remove: true})
+	}
+
+	return c.forEach(entries, func(e *syncEntry) error {
+		if e.remove {
+			return c.Globals.APIClient.DeleteKVStoreKey(context.TODO(), &fastly.DeleteKVStoreKeyInput{
+				StoreID: c.storeID,
+				Key:     e.key,
+			})
+		}
+		err := c.insert(e)
+		// In case the network connection is lost due to exhaustion of
+		// resources, then try one more time to make the request.
+		//
+		// NOTE: you can't type assert the error as it's not exported.
+		// https://github.com/golang/go/issues/54173
+		if err != nil && strings.Contains(err.Error(), "net/http: cannot rewind body after connection loss") {
+			err = c.insert(e)
+		}
+		return err
+	})
+}
+
+// insert uploads the value of an entry. The metadata of an existing item is
+// kept unless the entry sets it, or --hash-metadata records the hash in it.
+func (c *SyncCommand) insert(e *syncEntry) error {
+	input := &fastly.InsertKVStoreKeyInput{
+		StoreID:  c.storeID,
+		Key:      e.key,
+		Metadata: e.metadata,
+	}
+	switch {
+	case c.hashMetadata:
+		metadata, err := json.Marshal(syncMetadata{SHA256: e.hash})
+		if err != nil {
+			return err
+		}
+		input.Metadata = fastly.ToPointer(string(metadata))
+	case input.Metadata == nil && e.remoteMetadata != "":
+		input.Metadata = fastly.ToPointer(e.remoteMetadata)
+	}
+
+	if e.path == "" {
+		input.Value = string(e.value)
+		return c.Globals.APIClient.InsertKVStoreKey(context.TODO(), input)
+	}
+
+	f, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	lr, err := fastly.FileLengthReader(f)
+	if err != nil {
+		return err
+	}
+	input.Body = lr
+	return c.Globals.APIClient.InsertKVStoreKey(context.TODO(), input)
+}
+
+// forEach calls fn for each entry using a pool of goroutines and returns the
+// errors.
+func (c *SyncCommand) forEach(entries []*syncEntry, fn func(*syncEntry) error) []ProcessErr {
+	var (
+		// NOTE: mu protects access to the 'failed' shared resource.
+		mu     sync.Mutex
+		failed []ProcessErr
+		wg     sync.WaitGroup
+	)
+	entriesCh := make(chan *syncEntry)
+	for range min(c.concurrency, len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range entriesCh {
+				if err := fn(e); err != nil {
+					mu.Lock()
+					failed = append(failed, ProcessErr{File: e.key, Err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, e := range entries {
+		entriesCh <- e
+	}
+	close(entriesCh)
+	wg.Wait()
+
+	slices.SortFunc(failed, func(a, b ProcessErr) int {
+		return strings.Compare(a.File, b.File)
+	})
+	return failed
+}
+
+// printPlan prints the keys to be added (+), updated (~) and deleted (-).
+func (c *SyncCommand) printPlan(out io.Writer, plan *syncPlan) {
+	text.Break(out)
+	for _, key := range plan.Add {
+		text.Output(out, "+ %s", key)
+	}
+	for _, key := range plan.Update {
+		text.Output(out, "~ %s", key)
+	}
+	for _, key := range plan.Delete {
+		text.Output(out, "- %s", key)
+	}
+	text.Break(out)
+	text.Output(out, "Plan: %d to add, %d to update, %d to delete, %d unchanged", len(plan.Add), len(plan.Update), len(plan.Delete), plan.Unchanged)
+	if plan.Extraneous > 0 {
+		text.Info(out, "\n%d keys in the store aren't present locally (use --delete to remove them)", plan.Extraneous)
+	}
+}
+
+// processErrors prints the errors for each key and returns a summary error.
+func (c *SyncCommand) processErrors(out io.Writer, failed []ProcessErr, msg string) error {
+	for _, pe := range failed {
+		c.Globals.ErrLog.Add(pe.Err)
+	}
+	if c.JSONOutput.Enabled {
+		return fmt.Errorf("%s: %w", msg, failed[0].Err)
+	}
+	text.Break(out)
+	for _, pe := range failed {
+		text.Output(out, "Key: %s\nError: %s\n", pe.File, pe.Err.Error())
+	}
+	return fmt.Errorf("%s (see error log above ⬆️)", msg)
+}
+
+// hashFile returns the hex encoded SHA-256 hash of a file.
+func hashFile(path string) (string, error) {
+	// G304 (CWE-22): Potential file inclusion via variable
+	// #nosec
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package logtail
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats supported by the --output flag.
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputLogfmt = "logfmt"
+)
+
+// outputFormats is the list of values accepted by the --output flag.
+var outputFormats = []string{outputText, outputJSON, outputNDJSON, outputLogfmt}
+
+// textOutput reports whether logs are printed as human-readable text, in which
+// case informational messages can be interleaved with them.
+func (c cfg) textOutput() bool {
+	return c.output == "" || c.output == outputText
+}
+
+// record is the machine-readable representation of a Log.
+type record struct {
+	RequestID      string `json:"request_id"`
+	SequenceNum    int    `json:"sequence_number"`
+	RequestStart   string `json:"request_start"`
+	RequestStartUS int64  `json:"request_start_us"`
+	Stream         string `json:"stream"`
+	Message        string `json:"message"`
+	ServiceID      string `json:"service_id,omitempty"`
+}
+
+// toRecord converts a Log into its machine-readable representation.
+func toRecord(l Log) record {
+	return record{
+		RequestID:      l.RequestID,
+		SequenceNum:    l.SequenceNum,
+		RequestStart:   l.RequestStartFromRaw().UTC().Format(time.RFC3339Nano),
+		RequestStartUS: l.RequestStart,
+		Stream:         l.Stream,
+		Message:        l.Message,
+		ServiceID:      l.ServiceID,
+	}
+}
+
+// requestRecord is the machine-readable representation of the logs of a
+// single request.
+type requestRecord struct {
+	RequestID string   `json:"request_id"`
+	ServiceID string   `json:"service_id,omitempty"`
+	Logs      []record `json:"logs"`
+}
+
+// writeJSON prints logs as a JSON object on a single line, so that the stream
+// is newline-delimited JSON. The output loop flushes logs one request at a
+// time, so each object holds the logs of a single request in sequence order.
+func writeJSON(out io.Writer, logs []Log) {
+	r := requestRecord{
+		RequestID: logs[0].RequestID,
+		ServiceID: logs[0].ServiceID,
+		Logs:      make([]record, 0, len(logs)),
+	}
+	for _, l := range logs {
+		r.Logs = append(r.Logs, toRecord(l))
+	}
+	_ = json.NewEncoder(out).Encode(r)
+}
+
+// writeNDJSON prints logs as newline-delimited JSON, one log per line.
+func writeNDJSON(out io.Writer, logs []Log) {
+	enc := json.NewEncoder(out)
+	for _, l := range logs {
+		_ = enc.Encode(toRecord(l))
+	}
+}
+
+// writeLogfmt prints logs in logfmt, one log per line.
+func writeLogfmt(out io.Writer, logs []Log) {
+	for _, l := range logs {
+		r := toRecord(l)
+		if r.ServiceID != "" {
+			fmt.Fprintf(out, "service_id=%s ", logfmtValue(r.ServiceID))
+		}
+		fmt.Fprintf(out, "request_start=%s request_id=%s sequence_number=%d stream=%s message=%s\n",
+			r.RequestStart,
+			logfmtValue(r.RequestID),
+			r.SequenceNum,
+			logfmtValue(r.Stream),
+			logfmtValue(r.Message),
+		)
+	}
+}
+
+// logfmtValue quotes a logfmt value if it's empty or contains characters that
+// would otherwise break parsing.
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\\\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
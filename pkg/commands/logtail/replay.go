@@ -0,0 +1,98 @@
+package logtail
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/global"
+)
+
+// ReplayCommand prints logs previously recorded with `log-tail --record`.
+type ReplayCommand struct {
+	argparser.Base
+
+	file string
+	root *RootCommand
+}
+
+// NewReplayCommand returns a new command registered in the parent.
+//
+// The replay shares the parent's configuration, so flags that control the
+// output of `log-tail` (such as --output, --stream and --sort-buffer) apply to
+// the replay too.
+func NewReplayCommand(parent *RootCommand, g *global.Data) *ReplayCommand {
+	var c ReplayCommand
+	c.Globals = g
+	c.root = parent
+	c.CmdClause = parent.CmdClause.Command("replay", "Print Compute logs recorded with `log-tail --record`")
+	c.CmdClause.Arg("file", "Path to the file written by --record").Required().StringVar(&c.file)
+	return &c
+}
+
+// Exec implements the command interface.
+func (c *ReplayCommand) Exec(_ io.Reader, out io.Writer) error {
+	if err := c.root.compileFilters(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(filepath.Clean(c.file))
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error opening recording: %w", err)
+	}
+	defer f.Close()
+
+	return c.root.replay(f, out)
+}
+
+// replay feeds the batches read from r through the output loop, exactly as if
+// they had been received from the API, and waits for them to be printed.
+func (c *RootCommand) replay(r io.Reader, out io.Writer) error {
+	c.dieCh = make(chan struct{})
+	c.batchCh = make(chan Batch)
+
+	printed := make(chan struct{})
+	go func() {
+		c.outputLoop(out)
+		close(printed)
+	}()
+	// Release any sort buffer timers that are still pending once we're done.
+	defer close(c.dieCh)
+
+	scanner := bufio.NewScanner(r)
+	const tmb = 10 << 20
+	scanner.Buffer(make([]byte, tmb), tmb)
+
+	var (
+		line     int
+		parseErr error
+	)
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		batch, err := parseResponseData(scanner.Bytes())
+		if err != nil {
+			parseErr = fmt.Errorf("error parsing recording (line %d): %w", line, err)
+			break
+		}
+		c.batchCh <- batch
+	}
+
+	// Closing the batch channel flushes whatever is left in the sort buffer.
+	close(c.batchCh)
+	<-printed
+
+	if parseErr != nil {
+		return parseErr
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading recording: %w", err)
+	}
+	return nil
+}
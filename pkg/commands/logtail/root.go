@@ -11,9 +11,12 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -32,14 +35,19 @@ import (
 type RootCommand struct {
 	argparser.Base
 
-	Input       fastly.CreateManagedLoggingInput
-	batchCh     chan Batch // send batches to output loop
-	cfg         cfg
-	dieCh       chan struct{} // channel to end output/printing
-	doneCh      chan struct{} // channel to signal we've reached the end of the run
-	hClient     *http.Client  // TODO: this will go away when GET is in go-fastly
-	serviceName argparser.OptionalServiceNameID
-	token       string // TODO: this will go away when GET is in go-fastly
+	Input             fastly.CreateManagedLoggingInput
+	batchCh           chan Batch // send batches to output loop
+	cfg               cfg
+	dieCh             chan struct{}     // channel to end output/printing
+	doneCh            chan struct{}     // channel to signal we've reached the end of the run
+	hClient           *http.Client      // TODO: this will go away when GET is in go-fastly
+	labels            map[string]string // coloured output prefix for each service ID
+	recorder          *json.Encoder     // writes received batches to the --record file
+	recorderMu        sync.Mutex        // serialises writes to recorder, which is shared by each service's tail
+	serviceIDs        []string
+	serviceName       argparser.OptionalServiceNameID
+	serviceNamePrefix string
+	token             string // TODO: this will go away when GET is in go-fastly
 }
 
 // CommandName is the string to be used to invoke this command.
@@ -49,43 +57,55 @@ const CommandName = "log-tail"
 func NewRootCommand(parent argparser.Registerer, g *global.Data) *RootCommand {
 	var c RootCommand
 	c.Globals = g
-	c.CmdClause = parent.Command(CommandName, "Tail Compute logs")
-	c.RegisterFlag(argparser.StringFlagOpts{
-		Name:        argparser.FlagServiceIDName,
-		Description: argparser.FlagServiceIDDesc,
-		Dst:         &g.Manifest.Flag.ServiceID,
-		Short:       's',
-	})
+	c.CmdClause = parent.Command(CommandName, "Tail Compute logs").OptionalSubcommands()
+	c.CmdClause.Flag(argparser.FlagServiceIDName, argparser.FlagServiceIDDesc+" (repeat to tail several services at once)").Short('s').StringsVar(&c.serviceIDs)
 	c.RegisterFlag(argparser.StringFlagOpts{
 		Action:      c.serviceName.Set,
 		Name:        argparser.FlagServiceName,
 		Description: argparser.FlagServiceNameDesc,
 		Dst:         &c.serviceName.Value,
 	})
+	c.CmdClause.Flag("service-name-prefix", "Tail every Compute service whose name starts with the given prefix").StringVar(&c.serviceNamePrefix)
 	c.CmdClause.Flag("from", "From time, in Unix seconds").Int64Var(&c.cfg.from)
 	c.CmdClause.Flag("to", "To time, in Unix seconds").Int64Var(&c.cfg.to)
 	c.CmdClause.Flag("sort-buffer", "Duration of sort buffer for received logs").Default("1s").DurationVar(&c.cfg.sortBuffer)
 	c.CmdClause.Flag("search-padding", "Time beyond from/to to consider in searches").Default("2s").DurationVar(&c.cfg.searchPadding)
 	c.CmdClause.Flag("stream", "Output: stdout, stderr, both (default)").StringVar(&c.cfg.stream)
 	c.CmdClause.Flag("timestamps", "Print timestamps with logs").BoolVar(&c.cfg.printTimestamps)
+	c.CmdClause.Flag("grep", "Only print logs whose message contains the given text").StringVar(&c.cfg.grep)
+	c.CmdClause.Flag("regex", "Only print logs whose message matches the given regular expression").StringVar(&c.cfg.regex)
+	c.CmdClause.Flag("request-id", "Only print logs for the given request ID (or ID prefix, such as the 8 characters shown in text output)").StringVar(&c.cfg.requestID)
+	c.CmdClause.Flag("record", "Record every batch of logs received to the given file (as NDJSON) for use with `log-tail replay`").StringVar(&c.cfg.record)
+	c.CmdClause.Flag("output", "Output format: text, json (a line with a JSON object for each request), ndjson (a line for each log), logfmt").Default(outputText).HintOptions(outputFormats...).EnumVar(&c.cfg.output, outputFormats...)
 	return &c
 }
 
 // Exec implements the command interface.
 func (c *RootCommand) Exec(_ io.Reader, out io.Writer) error {
-	serviceID, source, flag, err := argparser.ServiceID(c.serviceName, *c.Globals.Manifest, c.Globals.APIClient, c.Globals.ErrLog)
+	services, err := c.services(out)
 	if err != nil {
 		return err
 	}
-	if c.Globals.Verbose() {
-		argparser.DisplayServiceID(serviceID, flag, source, out)
+
+	if err := c.compileFilters(); err != nil {
+		return err
 	}
 
-	c.Input.ServiceID = serviceID
+	if c.cfg.record != "" {
+		f, err := os.OpenFile(filepath.Clean(c.cfg.record), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return fmt.Errorf("error opening --record file: %w", err)
+		}
+		defer f.Close()
+		c.recorder = json.NewEncoder(f)
+	}
 
 	c.Input.Kind = fastly.ManagedLoggingInstanceOutput
 	endpoint, _ := c.Globals.APIEndpoint()
-	c.cfg.path = fmt.Sprintf("%s/service/%s/log_stream/managed/instance_output", endpoint, c.Input.ServiceID)
+	for i := range services {
+		services[i].path = fmt.Sprintf("%s/service/%s/log_stream/managed/instance_output", endpoint, services[i].id)
+	}
 
 	c.dieCh = make(chan struct{})
 	c.batchCh = make(chan Batch)
@@ -99,21 +119,39 @@ func (c *RootCommand) Exec(_ io.Reader, out io.Writer) error {
 	c.adjustTimes()
 
 	// Enable managed logging if not already enabled.
-	if err := c.enableManagedLogging(out); err != nil {
-		c.Globals.ErrLog.Add(err)
-		return err
+	for _, s := range services {
+		if err := c.enableManagedLogging(out, s.id); err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
 	}
 
-	failure := make(chan error)
+	failure := make(chan error, len(services))
 	sigs := make(chan os.Signal, 2)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Start the output loop.
-	go c.outputLoop(out)
+	printed := make(chan struct{})
+	go func() {
+		c.outputLoop(out)
+		close(printed)
+	}()
 
-	// Start tailing the logs.
+	// Start tailing the logs, one tail per service. The run is done once
+	// every tail has passed the requested 'to' time.
+	var wg sync.WaitGroup
+	for _, s := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.tail(out, s, len(services) > 1); err != nil {
+				failure <- err
+			}
+		}()
+	}
 	go func() {
-		failure <- c.tail(out)
+		wg.Wait()
+		close(c.doneCh)
 	}()
 
 	select {
@@ -121,6 +159,16 @@ func (c *RootCommand) Exec(_ io.Reader, out io.Writer) error {
 		close(c.dieCh)
 		return asyncErr
 	case <-c.doneCh:
+		// We are done, but we still want printing to finish.
+		select {
+		case asyncErr := <-failure:
+			close(c.dieCh)
+			return asyncErr
+		default:
+		}
+		close(c.batchCh)
+		<-printed
+		close(c.dieCh)
 		return nil
 	case <-sigs:
 		close(c.dieCh)
@@ -129,16 +177,17 @@ func (c *RootCommand) Exec(_ io.Reader, out io.Writer) error {
 	return nil
 }
 
-// Tail starts the virtual tail process. Tail fetches data from the eventbuffer
-// API. It hands off the requested logs to the outputloop for the actual
-// printing.
-func (c *RootCommand) tail(out io.Writer) error {
+// Tail starts the virtual tail process for a service. Tail fetches data from
+// the eventbuffer API. It hands off the requested logs to the outputloop for
+// the actual printing. When tag is set, each log is tagged with the service ID
+// so that logs from different services can be told apart.
+func (c *RootCommand) tail(out io.Writer, s service, tag bool) error {
 	// Start this with --from and --to if set.
 	curWindow := c.cfg.from
 	toWindow := c.cfg.to
 
 	// Start the loop with an initial address to query.
-	path, err := makeNewPath(c.cfg.path, curWindow, "")
+	path, err := makeNewPath(s.path, curWindow, "")
 	if err != nil {
 		return err
 	}
@@ -150,9 +199,9 @@ func (c *RootCommand) tail(out io.Writer) error {
 	for {
 		// Check to see if we already passed the "to" requirement.
 		if toWindow != 0 && curWindow > toWindow {
-			text.Info(out, "Reached window: %v which is newer than the requested 'to': %v", curWindow, toWindow)
-			// We are done, but we still want printing to finish.
-			close(c.doneCh)
+			if c.cfg.textOutput() {
+				text.Info(out, "Reached window: %v which is newer than the requested 'to': %v", curWindow, toWindow)
+			}
 			break
 		}
 
@@ -238,6 +287,12 @@ func (c *RootCommand) tail(out io.Writer) error {
 				// anything fails along the way, we
 				// can re-request.
 				lastBatchID = batch.ID
+				if tag {
+					for i := range batch.Logs {
+						batch.Logs[i].ServiceID = s.id
+					}
+				}
+				c.recordBatch(out, batch)
 				// Send batch down batchCh to the output loop.
 				c.batchCh <- batch
 			}
@@ -285,6 +340,34 @@ func (c *RootCommand) tail(out io.Writer) error {
 	return nil
 }
 
+// compileFilters validates and compiles the --regex filter.
+func (c *RootCommand) compileFilters() error {
+	if c.cfg.regex == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(c.cfg.regex)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error parsing --regex: %w", err)
+	}
+	c.cfg.pattern = pattern
+	return nil
+}
+
+// recordBatch appends the batch to the --record file, if one was given.
+func (c *RootCommand) recordBatch(out io.Writer, batch Batch) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorderMu.Lock()
+	err := c.recorder.Encode(batch)
+	c.recorderMu.Unlock()
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		text.Warning(out, "unable to record batch %s: %v", batch.ID, err)
+	}
+}
+
 // adjustTimes adjusts the passed in from and to flags based on the
 // specified padding.
 func (c *RootCommand) adjustTimes() {
@@ -301,18 +384,27 @@ func (c *RootCommand) adjustTimes() {
 }
 
 // enableManagedLogging enables managed logging in our API.
-func (c *RootCommand) enableManagedLogging(out io.Writer) error {
-	_, err := c.Globals.APIClient.CreateManagedLogging(context.TODO(), &c.Input)
+func (c *RootCommand) enableManagedLogging(out io.Writer, serviceID string) error {
+	input := c.Input
+	input.ServiceID = serviceID
+	_, err := c.Globals.APIClient.CreateManagedLogging(context.TODO(), &input)
 	if err != nil && err != fastly.ErrManagedLoggingEnabled {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
 
-	text.Info(out, "Managed logging enabled on service %s\n\n", c.Input.ServiceID)
+	// Informational messages would corrupt machine-readable output.
+	if c.cfg.textOutput() {
+		text.Info(out, "Managed logging enabled on service %s\n\n", serviceID)
+	}
 	return nil
 }
 
 // outputLoop processes the logs out of band from the request/response loop.
+//
+// If batchCh is closed (e.g. at the end of a replay) any logs still in the
+// sort buffer are printed, in the order their requests were first received,
+// and the loop returns.
 func (c *RootCommand) outputLoop(out io.Writer) {
 	type (
 		bufferedLog struct {
@@ -328,21 +420,57 @@ func (c *RootCommand) outputLoop(out io.Writer) {
 		logrecv struct {
 			logs     []Log
 			receives []receive
+			arrival  int
 		}
 	)
 
-	// Channel for timers to notify they are done buffering.
+	// Channel for timers to notify they are done buffering. Timers give up
+	// once dieCh is closed, as nothing will be reading tdCh.
 	tdCh := make(chan bufferedLog)
+	dieCh := c.dieCh
 
 	// Single map to keep all buffered logs by RequestID as
 	// well recording when logs were received.
 	logmap := make(map[string]logrecv)
 
+	// arrivals counts the RequestIDs received, so buffered logs can be
+	// flushed in the order their requests arrived.
+	var arrivals int
+
 	for {
 		select {
-		case <-c.dieCh:
+		case <-dieCh:
 			return
-		case batch := <-c.batchCh: // Got new batch.
+		case batch, ok := <-c.batchCh: // Got new batch.
+			if !ok {
+				// No more batches, so print everything that's still
+				// buffered in the order the requests arrived.
+				pending := make([]logrecv, 0, len(logmap))
+				for _, reqLogs := range logmap {
+					pending = append(pending, reqLogs)
+				}
+				sort.Slice(pending, func(i, j int) bool {
+					return pending[i].arrival < pending[j].arrival
+				})
+				for _, reqLogs := range pending {
+					c.printLogs(out, reqLogs.logs)
+				}
+				return
+			}
+
+			// Number the RequestIDs we haven't buffered before in
+			// the order they appear in the batch.
+			arrived := make(map[string]int)
+			for _, l := range batch.Logs {
+				if _, ok := logmap[l.RequestID]; ok {
+					continue
+				}
+				if _, ok := arrived[l.RequestID]; !ok {
+					arrived[l.RequestID] = arrivals
+					arrivals++
+				}
+			}
+
 			// Range through batch logs, for each
 			// RequestID we create a timer based on the
 			// highest SequenceNum we got in this batch
@@ -360,7 +488,10 @@ func (c *RootCommand) outputLoop(out io.Writer) {
 
 				// Whether we have the RequestID or not, we
 				// append and sort the logs slice.
-				reqLogs := logmap[req]
+				reqLogs, seen := logmap[req]
+				if !seen {
+					reqLogs.arrival = arrived[req]
+				}
 				reqLogs.logs = append(reqLogs.logs, logs...)
 				// Sort the current batch of logs by their sequence number.
 				sort.Slice(reqLogs.logs,
@@ -387,9 +518,12 @@ func (c *RootCommand) outputLoop(out io.Writer) {
 				// since this is the head of the slice.
 				if len(recv) == 0 {
 					time.AfterFunc(c.cfg.sortBuffer, func() {
-						tdCh <- bufferedLog{
+						select {
+						case tdCh <- bufferedLog{
 							reqID: req,
 							seq:   highSeq,
+						}:
+						case <-dieCh:
 						}
 					})
 				}
@@ -431,9 +565,12 @@ func (c *RootCommand) outputLoop(out io.Writer) {
 				// off time already served from the
 				// user defined sortBuffer.
 				time.AfterFunc(c.cfg.sortBuffer-time.Since(recv[0].when), func() {
-					tdCh <- bufferedLog{
+					select {
+					case tdCh <- bufferedLog{
 						reqID: reqID,
 						seq:   recv[0].highSeq,
+					}:
+					case <-dieCh:
 					}
 				})
 			}
@@ -446,17 +583,32 @@ func (c *RootCommand) outputLoop(out io.Writer) {
 }
 
 // printLogs is a simple printer for Log slices, only printing requested
-// streams.
+// streams and logs that match the --grep, --regex and --request-id filters.
 func (c *RootCommand) printLogs(out io.Writer, logs []Log) {
 	if len(logs) > 0 {
-		filtered := filterStream(c.cfg.stream, logs)
+		filtered := filterLogs(c.cfg, filterStream(c.cfg.stream, logs))
+		if len(filtered) == 0 {
+			return
+		}
 
-		for _, l := range filtered {
-			if c.cfg.printTimestamps {
-				fmt.Fprint(out, l.RequestStartFromRaw().UTC().Format(time.RFC3339))
-				fmt.Fprint(out, " | ")
+		switch c.cfg.output {
+		case outputJSON:
+			writeJSON(out, filtered)
+		case outputNDJSON:
+			writeNDJSON(out, filtered)
+		case outputLogfmt:
+			writeLogfmt(out, filtered)
+		default:
+			for _, l := range filtered {
+				if l.ServiceID != "" {
+					fmt.Fprint(out, c.label(l.ServiceID))
+				}
+				if c.cfg.printTimestamps {
+					fmt.Fprint(out, l.RequestStartFromRaw().UTC().Format(time.RFC3339))
+					fmt.Fprint(out, " | ")
+				}
+				fmt.Fprintln(out, l.String())
 			}
-			fmt.Fprintln(out, l.String())
 		}
 	}
 }
@@ -487,15 +639,16 @@ type (
 	// cfg holds the configuration parameters passed in through
 	// command line arguments.
 	cfg struct {
-		// path is the full path to fetch
-		path string
-
 		// from is how far in the past to start showing logs.
 		from int64
 
 		// to is when to get logs until.
 		to int64
 
+		// record, when set, is the file every received batch is
+		// written to (as NDJSON).
+		record string
+
 		// printTimestamps is whether to print timestamps with logs.
 		printTimestamps bool
 
@@ -511,6 +664,20 @@ type (
 		// customer wants to consume.
 		// Undefined == both stderr and stdout.
 		stream string
+
+		// grep, when set, is text that a log message must contain.
+		grep string
+		// regex, when set, is a regular expression that a log message
+		// must match. It's compiled into pattern.
+		regex   string
+		pattern *regexp.Regexp
+		// requestID, when set, is the RequestID (or a prefix of it)
+		// whose logs should be printed.
+		requestID string
+
+		// output is the format logs are printed in.
+		// Undefined == text.
+		output string
 	}
 
 	// Log defines the message envelope that the Compute platform wraps the
@@ -529,6 +696,9 @@ type (
 		RequestID string `json:"id"`
 		// Message is the actual message body the user wants printed.
 		Message string `json:"message"`
+		// ServiceID isn't part of the envelope. It's set by the CLI when
+		// tailing more than one service, to identify where the log came from.
+		ServiceID string `json:"service_id,omitempty"`
 	}
 
 	// Batch encompasses a batch ID and the logs for this batch.
@@ -619,6 +789,29 @@ func filterStream(stream string, logs []Log) []Log {
 	return out
 }
 
+// filterLogs returns only logs that match the grep, regex and request ID
+// filters (if set).
+func filterLogs(cfg cfg, logs []Log) []Log {
+	if cfg.grep == "" && cfg.pattern == nil && cfg.requestID == "" {
+		return logs
+	}
+
+	var out []Log
+	for _, l := range logs {
+		if cfg.requestID != "" && !strings.HasPrefix(l.RequestID, cfg.requestID) {
+			continue
+		}
+		if cfg.grep != "" && !strings.Contains(l.Message, cfg.grep) {
+			continue
+		}
+		if cfg.pattern != nil && !cfg.pattern.MatchString(l.Message) {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
 // getTimeFromLink splits a link header format, returning
 // the time.
 func getTimeFromLink(link string) (int64, error) {
@@ -0,0 +1,170 @@
+package logtail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// service is a Compute service whose logs are being tailed.
+type service struct {
+	// id is the service ID.
+	id string
+	// name is the service name, used to label its logs.
+	name string
+	// path is the full path to fetch.
+	path string
+}
+
+// services resolves the services to tail.
+//
+// A single service is resolved the same way as any other command (flag,
+// manifest, then environment). Logs are only labelled with the service they
+// came from when --service-id is repeated or --service-name-prefix is set.
+func (c *RootCommand) services(out io.Writer) ([]service, error) {
+	multi := len(c.serviceIDs) > 1 || c.serviceNamePrefix != ""
+	if !multi {
+		if len(c.serviceIDs) == 1 {
+			c.Globals.Manifest.Flag.ServiceID = c.serviceIDs[0]
+		}
+		serviceID, source, flag, err := argparser.ServiceID(c.serviceName, *c.Globals.Manifest, c.Globals.APIClient, c.Globals.ErrLog)
+		if err != nil {
+			return nil, err
+		}
+		if c.Globals.Verbose() {
+			argparser.DisplayServiceID(serviceID, flag, source, out)
+		}
+		c.Input.ServiceID = serviceID
+		return []service{{id: serviceID}}, nil
+	}
+
+	if c.serviceName.WasSet {
+		err := fsterr.RemediationError{
+			Inner:       errors.New("--service-name can't be combined with multiple services"),
+			Remediation: "Repeat --service-id for each service, or use --service-name-prefix.",
+		}
+		c.Globals.ErrLog.Add(err)
+		return nil, err
+	}
+
+	var (
+		services []service
+		err      error
+	)
+	if c.serviceNamePrefix != "" {
+		services, err = c.servicesByPrefix()
+	} else {
+		services, err = c.servicesByID()
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return nil, err
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].name < services[j].name
+	})
+	c.labels = serviceLabels(services)
+
+	if c.Globals.Verbose() {
+		for _, s := range services {
+			text.Output(out, "Tailing service %s (%s)", s.name, s.id)
+		}
+		text.Break(out)
+	}
+	return services, nil
+}
+
+// servicesByID looks up the name of each service passed with --service-id.
+func (c *RootCommand) servicesByID() ([]service, error) {
+	seen := make(map[string]bool)
+	var services []service
+	for _, id := range c.serviceIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		s, err := c.Globals.APIClient.GetService(context.TODO(), &fastly.GetServiceInput{
+			ServiceID: id,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting service %s: %w", id, err)
+		}
+		services = append(services, service{id: id, name: fastly.ToValue(s.Name)})
+	}
+	return services, nil
+}
+
+// servicesByPrefix lists the Compute services whose name starts with the
+// --service-name-prefix value.
+func (c *RootCommand) servicesByPrefix() ([]service, error) {
+	paginator := c.Globals.APIClient.GetServices(context.TODO(), &fastly.GetServicesInput{})
+	var services []service
+	for paginator.HasNext() {
+		data, err := paginator.GetNext()
+		if err != nil {
+			return nil, fmt.Errorf("error listing services: %w", err)
+		}
+		for _, s := range data {
+			if fastly.ToValue(s.Type) != "wasm" || !strings.HasPrefix(fastly.ToValue(s.Name), c.serviceNamePrefix) {
+				continue
+			}
+			services = append(services, service{
+				id:   fastly.ToValue(s.ServiceID),
+				name: fastly.ToValue(s.Name),
+			})
+		}
+	}
+	if len(services) == 0 {
+		return nil, fsterr.RemediationError{
+			Inner:       fmt.Errorf("no Compute services found with a name starting with %q", c.serviceNamePrefix),
+			Remediation: "Run `fastly service list` to see the available services.",
+		}
+	}
+	return services, nil
+}
+
+// serviceLabels returns the prefix printed before each log line for every
+// service, keyed by service ID. Names are padded to the same width so the logs
+// line up, and each service gets its own colour.
+func serviceLabels(services []service) map[string]string {
+	var width int
+	for _, s := range services {
+		width = max(width, len(labelName(s)))
+	}
+	labels := make(map[string]string, len(services))
+	for i, s := range services {
+		colour := text.ColorCycle[i%len(text.ColorCycle)]
+		labels[s.id] = colour(fmt.Sprintf("%-*s", width, labelName(s))) + " | "
+	}
+	return labels
+}
+
+// labelName is the name a service's logs are labelled with, falling back to
+// the ID for services without a name.
+func labelName(s service) string {
+	if s.name != "" {
+		return s.name
+	}
+	return s.id
+}
+
+// label returns the prefix for a log tagged with a service ID. Logs replayed
+// from a recording may come from services that weren't resolved in this run,
+// in which case the ID is used as is.
+func (c *RootCommand) label(serviceID string) string {
+	if l, ok := c.labels[serviceID]; ok {
+		return l
+	}
+	return serviceID + " | "
+}
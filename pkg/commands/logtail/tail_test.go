@@ -1,9 +1,16 @@
 package logtail
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -192,6 +199,266 @@ func TestFilterStream(t *testing.T) {
 	}
 }
 
+// TestFilterLogs tests that the grep, regex and request ID filters only keep
+// matching logs.
+func TestFilterLogs(t *testing.T) {
+	logs := []Log{
+		{RequestID: "41f82900-aaaa", Message: "GET /api/users 200"},
+		{RequestID: "41f82900-aaaa", Message: "cache miss"},
+		{RequestID: "2bef4613-bbbb", Message: "GET /static/app.js 200"},
+		{RequestID: "2bef4613-bbbb", Message: "GET /api/orders 500"},
+	}
+
+	for i, test := range []struct {
+		cfg  cfg
+		want []Log
+	}{
+		{
+			cfg:  cfg{},
+			want: logs,
+		},
+		{
+			cfg:  cfg{grep: "/api/"},
+			want: []Log{logs[0], logs[3]},
+		},
+		{
+			cfg:  cfg{pattern: regexp.MustCompile(` 5\d\d$`)},
+			want: []Log{logs[3]},
+		},
+		{
+			cfg:  cfg{requestID: "41f82900"},
+			want: []Log{logs[0], logs[1]},
+		},
+		{
+			cfg:  cfg{requestID: "2bef4613", grep: "GET", pattern: regexp.MustCompile(`200$`)},
+			want: []Log{logs[2]},
+		},
+		{
+			cfg: cfg{grep: "nothing matches this"},
+		},
+	} {
+		got := filterLogs(test.cfg, logs)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("#%d: filterLogs mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+// TestPrintLogsOutput tests that logs are printed in each --output format.
+func TestPrintLogsOutput(t *testing.T) {
+	logs := []Log{
+		{SequenceNum: 1, RequestStart: 1601645172164667, Stream: "stdout", RequestID: "44a1eedd-5831-49fe-b094-7435908ba1fb", Message: "hello world"},
+		{SequenceNum: 2, RequestStart: 1601645172164667, Stream: "stderr", RequestID: "44a1eedd-5831-49fe-b094-7435908ba1fb", Message: `say "hi"`},
+	}
+
+	for _, test := range []struct {
+		output string
+		want   string
+	}{
+		{
+			output: outputText,
+			want: "stdout | 44a1eedd | hello world\n" +
+				"stderr | 44a1eedd | say \"hi\"\n",
+		},
+		{
+			output: outputNDJSON,
+			want: `{"request_id":"44a1eedd-5831-49fe-b094-7435908ba1fb","sequence_number":1,"request_start":"2020-10-02T13:26:12.164667Z","request_start_us":1601645172164667,"stream":"stdout","message":"hello world"}` + "\n" +
+				`{"request_id":"44a1eedd-5831-49fe-b094-7435908ba1fb","sequence_number":2,"request_start":"2020-10-02T13:26:12.164667Z","request_start_us":1601645172164667,"stream":"stderr","message":"say \"hi\""}` + "\n",
+		},
+		{
+			output: outputLogfmt,
+			want: "request_start=2020-10-02T13:26:12.164667Z request_id=44a1eedd-5831-49fe-b094-7435908ba1fb sequence_number=1 stream=stdout message=\"hello world\"\n" +
+				"request_start=2020-10-02T13:26:12.164667Z request_id=44a1eedd-5831-49fe-b094-7435908ba1fb sequence_number=2 stream=stderr message=\"say \\\"hi\\\"\"\n",
+		},
+	} {
+		var buf bytes.Buffer
+		c := RootCommand{cfg: cfg{output: test.output}}
+		c.printLogs(&buf, logs)
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("%s: printLogs mismatch (-want +got):\n%s", test.output, diff)
+		}
+	}
+
+	// The json output is a line for each request, so that the output of
+	// several requests is a valid NDJSON stream.
+	var buf bytes.Buffer
+	c := RootCommand{cfg: cfg{output: outputJSON}}
+	c.printLogs(&buf, logs)
+	c.printLogs(&buf, []Log{{RequestID: "2bef4613", SequenceNum: 1, Stream: "stdout", Message: "other"}})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines of json output, got %d: %s", len(lines), buf.String())
+	}
+	var got []requestRecord
+	for _, line := range lines {
+		var r requestRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("json output line is not a JSON object: %v", err)
+		}
+		got = append(got, r)
+	}
+	if got[0].RequestID != "44a1eedd-5831-49fe-b094-7435908ba1fb" || len(got[0].Logs) != 2 || got[0].Logs[1].Message != `say "hi"` || got[0].Logs[0].SequenceNum != 1 {
+		t.Errorf("unexpected json output: %s", lines[0])
+	}
+	if got[1].RequestID != "2bef4613" || len(got[1].Logs) != 1 {
+		t.Errorf("unexpected json output: %s", lines[1])
+	}
+}
+
+// TestRecordConcurrent tests that batches received concurrently from several
+// services are each recorded intact on their own line (run with -race).
+func TestRecordConcurrent(t *testing.T) {
+	const batchesPerService = 100
+	services := []string{"service-a", "service-b"}
+
+	var recording bytes.Buffer
+	c := RootCommand{recorder: json.NewEncoder(&recording)}
+
+	var wg sync.WaitGroup
+	for _, id := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range batchesPerService {
+				c.recordBatch(io.Discard, Batch{
+					ID:   fmt.Sprintf("%s-%d", id, i),
+					Logs: []Log{{ServiceID: id, RequestID: "41f82900", SequenceNum: i, Stream: "stdout", Message: strings.Repeat("x", 512)}},
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	counts := map[string]int{}
+	dec := json.NewDecoder(&recording)
+	for dec.More() {
+		var b Batch
+		if err := dec.Decode(&b); err != nil {
+			t.Fatalf("recording is corrupt: %v", err)
+		}
+		if len(b.Logs) != 1 || !strings.HasPrefix(b.ID, b.Logs[0].ServiceID) {
+			t.Fatalf("unexpected recorded batch: %+v", b)
+		}
+		counts[b.Logs[0].ServiceID]++
+	}
+	for _, id := range services {
+		if counts[id] != batchesPerService {
+			t.Errorf("want %d batches recorded for %s, got %d", batchesPerService, id, counts[id])
+		}
+	}
+}
+
+// TestRecordReplay tests that recorded batches are replayed through the output
+// loop, with logs grouped by request and sorted by sequence number.
+func TestRecordReplay(t *testing.T) {
+	batches := []Batch{
+		{
+			ID: "MC0x",
+			Logs: []Log{
+				{SequenceNum: 2, RequestID: "41f82900", Stream: "stdout", Message: "a2"},
+				{SequenceNum: 1, RequestID: "2bef4613", Stream: "stdout", Message: "b1"},
+				{SequenceNum: 1, RequestID: "41f82900", Stream: "stdout", Message: "a1"},
+			},
+		},
+		{
+			ID: "MC0y",
+			Logs: []Log{
+				{SequenceNum: 3, RequestID: "2bef4613", Stream: "stderr", Message: "b3"},
+				{SequenceNum: 2, RequestID: "2bef4613", Stream: "stdout", Message: "b2"},
+				{SequenceNum: 3, RequestID: "41f82900", Stream: "stdout", Message: "a3"},
+			},
+		},
+	}
+
+	var recording bytes.Buffer
+	recorder := RootCommand{recorder: json.NewEncoder(&recording)}
+	for _, b := range batches {
+		recorder.recordBatch(io.Discard, b)
+	}
+	if n := strings.Count(recording.String(), "\n"); n != len(batches) {
+		t.Fatalf("want %d recorded lines, got %d", len(batches), n)
+	}
+
+	var out bytes.Buffer
+	// A long sort buffer means nothing is printed until the replay is
+	// finished, which makes the output deterministic.
+	c := RootCommand{cfg: cfg{sortBuffer: time.Hour}}
+	if err := c.replay(&recording, &out); err != nil {
+		t.Fatalf("error replaying: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"stdout | 41f82900 | a1",
+		"stdout | 41f82900 | a2",
+		"stdout | 41f82900 | a3",
+		"stdout | 2bef4613 | b1",
+		"stdout | 2bef4613 | b2",
+		"stderr | 2bef4613 | b3",
+	}, "\n") + "\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("replay mismatch (-want +got):\n%s", diff)
+	}
+
+	// Logs that are released by the sort buffer are printed as usual.
+	out.Reset()
+	c = RootCommand{cfg: cfg{sortBuffer: time.Millisecond, stream: "stderr"}}
+	if err := c.replay(strings.NewReader(`{"batch_id":"MC0x","logs":[{"sequence_number":1,"id":"2bef4613","stream":"stderr","message":"b1"}]}`+"\n"), &out); err != nil {
+		t.Fatalf("error replaying: %v", err)
+	}
+	if got := out.String(); got != "stderr | 2bef4613 | b1\n" {
+		t.Errorf("unexpected replay output: %q", got)
+	}
+
+	c = RootCommand{cfg: cfg{sortBuffer: time.Hour}}
+	err := c.replay(strings.NewReader("{\"batch_id\":\"MC0x\"}\nnot json\n"), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("want error parsing line 2, got: %v", err)
+	}
+}
+
+// TestServiceLabels tests that logs from multiple services are labelled with
+// the service they came from, with the labels padded to the same width.
+func TestServiceLabels(t *testing.T) {
+	services := []service{
+		{id: "123", name: "checkout"},
+		{id: "456", name: "api"},
+		{id: "789"},
+	}
+	c := RootCommand{labels: serviceLabels(services)}
+
+	logs := []Log{
+		{SequenceNum: 1, RequestID: "41f82900", Stream: "stdout", Message: "a1", ServiceID: "123"},
+		{SequenceNum: 2, RequestID: "41f82900", Stream: "stdout", Message: "a2", ServiceID: "456"},
+		{SequenceNum: 3, RequestID: "41f82900", Stream: "stdout", Message: "a3", ServiceID: "789"},
+		{SequenceNum: 4, RequestID: "41f82900", Stream: "stdout", Message: "a4", ServiceID: "abc"},
+		{SequenceNum: 5, RequestID: "41f82900", Stream: "stdout", Message: "a5"},
+	}
+
+	var buf bytes.Buffer
+	c.printLogs(&buf, logs)
+	want := strings.Join([]string{
+		"checkout | stdout | 41f82900 | a1",
+		"api      | stdout | 41f82900 | a2",
+		"789      | stdout | 41f82900 | a3",
+		"abc | stdout | 41f82900 | a4",
+		"stdout | 41f82900 | a5",
+	}, "\n") + "\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("printLogs mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	c.cfg.output = outputNDJSON
+	c.printLogs(&buf, logs[:1])
+	var got record
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("ndjson output is not JSON: %v", err)
+	}
+	if got.ServiceID != "123" {
+		t.Errorf("want service_id 123, got %q", got.ServiceID)
+	}
+}
+
 // TestGetLinks tests that we can parse next and prev links from a Link HTTP
 // header.
 func TestGetLinks(t *testing.T) {
@@ -0,0 +1,178 @@
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/secretstoreentry"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/storecopy"
+)
+
+// NewCopyCommand returns a usable command registered under the parent.
+func NewCopyCommand(parent argparser.Registerer, g *global.Data) *CopyCommand {
+	c := CopyCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+
+	c.CmdClause = parent.Command("copy", "Copy the secrets of a secret store to another secret store. As secret values can't be read, they are re-supplied from a local source")
+	c.opts.RegisterFlags(c.CmdClause)
+
+	// One of these must be set.
+	c.CmdClause.Flag("values-dir", "Path to a directory containing a file for each secret, where the filename is the name of the secret in the source store and the file contents is the value").StringVar(&c.valuesDir)
+	c.CmdClause.Flag("values-file", `Path to a JSON file of the secret values, keyed by the name of the secret in the source store (e.g. {"name":"value"})`).StringVar(&c.valuesFile)
+
+	return &c
+}
+
+// CopyCommand calls the Fastly API to copy the secrets of a secret store.
+type CopyCommand struct {
+	argparser.Base
+	opts       storecopy.Options
+	valuesDir  string
+	valuesFile string
+}
+
+// Exec invokes the application logic for the command.
+func (c *CopyCommand) Exec(_ io.Reader, out io.Writer) error {
+	if (c.valuesDir == "") == (c.valuesFile == "") {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("invalid flag combination"),
+			Remediation: "Provide the secret values with either --values-dir or --values-file, not both.",
+		}
+	}
+
+	var names []string
+	input := &fastly.ListSecretsInput{StoreID: c.opts.From}
+	for {
+		o, err := c.Globals.APIClient.ListSecrets(context.TODO(), input)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		for _, s := range o.Data {
+			names = append(names, s.Name)
+		}
+		if o.Meta.NextCursor == "" {
+			break
+		}
+		input.Cursor = o.Meta.NextCursor
+	}
+
+	pairs, err := c.opts.Plan(names)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	// Check every value is available before copying anything.
+	values, err := c.readValues(pairs)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	if c.opts.DryRun {
+		storecopy.PrintPlan(out, pairs)
+		return nil
+	}
+
+	ck, err := secretstoreentry.NewClientKey(c.Globals.APIClient)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	err = storecopy.Copy(out, c.opts, pairs, func(p storecopy.Pair) error {
+		wrapped, err := ck.Encrypt(values[p.From])
+		if err != nil {
+			return err
+		}
+		_, err = c.Globals.APIClient.CreateSecret(context.TODO(), &fastly.CreateSecretInput{
+			StoreID:   c.opts.To,
+			Name:      p.To,
+			Secret:    wrapped,
+			ClientKey: ck.PublicKey,
+			// Create or recreate the secret.
+			Method: http.MethodPut,
+		})
+		return err
+	})
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+	}
+	return err
+}
+
+// readValues returns the value of each secret from the local source.
+func (c *CopyCommand) readValues(pairs []storecopy.Pair) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(pairs))
+
+	if c.valuesFile != "" {
+		data, err := os.ReadFile(c.valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", c.valuesFile, err)
+		}
+		for _, p := range pairs {
+			if v, ok := m[p.From]; ok {
+				values[p.From] = []byte(v)
+			}
+		}
+	} else {
+		for _, p := range pairs {
+			// The name of a secret can't contain a path separator, but the file
+			// path is validated in case the source store was tampered with.
+			if !filepath.IsLocal(p.From) || strings.ContainsAny(p.From, `/\`) {
+				return nil, fmt.Errorf("invalid secret name: %s", p.From)
+			}
+			// G304 (CWE-22): Potential file inclusion via variable
+			// #nosec
+			v, err := os.ReadFile(filepath.Join(c.valuesDir, p.From))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[p.From] = v
+		}
+	}
+
+	var missing []string
+	for _, p := range pairs {
+		v, ok := values[p.From]
+		if !ok {
+			missing = append(missing, p.From)
+			continue
+		}
+		if len(v) > secretstoreentry.MaxSecretLen {
+			return nil, fsterr.RemediationError{
+				Inner:       fmt.Errorf("the value of the secret '%s' exceeds the max secret size", p.From),
+				Remediation: fmt.Sprintf("Maximum secret size is %dKiB", secretstoreentry.MaxSecretKiB),
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fsterr.RemediationError{
+			Inner:       fmt.Errorf("no value was found for the secrets: %s", strings.Join(missing, ", ")),
+			Remediation: "Provide a value for every secret to copy, or restrict the secrets to copy with --prefix.",
+		}
+	}
+	return values, nil
+}
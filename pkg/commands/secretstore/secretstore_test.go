@@ -3,12 +3,21 @@ package secretstore_test
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"golang.org/x/crypto/nacl/box"
+
 	"github.com/fastly/go-fastly/v17/fastly"
 
 	"github.com/fastly/cli/pkg/app"
@@ -17,6 +26,7 @@ import (
 	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/cli/pkg/threadsafe"
 )
 
 func TestCreateStoreCommand(t *testing.T) {
@@ -105,6 +115,108 @@ func TestCreateStoreCommand(t *testing.T) {
 	}
 }
 
+func TestCopyStoreCommand(t *testing.T) {
+	const (
+		fromID = "store-id-123"
+		toID   = "store-id-456"
+	)
+
+	ckPub, ckPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skPub, skPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ck := &fastly.ClientKey{
+		PublicKey: ckPub[:],
+		Signature: ed25519.Sign(skPriv, ckPub[:]),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	// Tests generate their own signing keys, which won't match the hardcoded
+	// value. Disable the check against the hardcoded value.
+	t.Setenv("FASTLY_USE_API_SIGNING_KEY", "1")
+
+	valuesDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(valuesDir, "api_key"), []byte("secret-1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	valuesFile := filepath.Join(t.TempDir(), "values.json")
+	if err := os.WriteFile(valuesFile, []byte(`{"api_key":"secret-2","db_password":"secret-3"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	listSecrets := func(_ context.Context, i *fastly.ListSecretsInput) (*fastly.Secrets, error) {
+		if i.Cursor == "" {
+			return &fastly.Secrets{
+				Data: []fastly.Secret{{Name: "api_key"}},
+				Meta: fastly.SecretStoreMeta{NextCursor: "next"},
+			}, nil
+		}
+		return &fastly.Secrets{Data: []fastly.Secret{{Name: "db_password"}}}, nil
+	}
+
+	var (
+		mu     sync.Mutex
+		copied map[string]string
+	)
+	createSecret := func(_ context.Context, i *fastly.CreateSecretInput) (*fastly.Secret, error) {
+		if i.StoreID != toID || i.Method != http.MethodPut {
+			return nil, errors.New("expected the secret to be created or recreated in the destination store")
+		}
+		plaintext, ok := box.OpenAnonymous(nil, i.Secret, ckPub, ckPriv)
+		if !ok {
+			return nil, errors.New("failed to decrypt")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		copied[i.Name] = string(plaintext)
+		return &fastly.Secret{Name: i.Name}, nil
+	}
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Args:      fmt.Sprintf("--from %s --to %s", fromID, toID),
+			WantError: "invalid flag combination",
+		},
+		{
+			Args:      fmt.Sprintf("--from %s --to %s --values-dir %s", fromID, toID, valuesDir),
+			API:       &mock.API{ListSecretsFn: listSecrets},
+			WantError: "no value was found for the secrets: db_password",
+		},
+		{
+			Args: fmt.Sprintf("--from %s --to %s --values-dir %s --prefix api_ --dry-run", fromID, toID, valuesDir),
+			API:  &mock.API{ListSecretsFn: listSecrets},
+			WantOutputs: []string{
+				"api_key\n",
+				"1 keys would be copied",
+			},
+		},
+		{
+			Args: fmt.Sprintf("--from %s --to %s --values-file %s --rename prod_{{.Key}}", fromID, toID, valuesFile),
+			API: &mock.API{
+				ListSecretsFn:     listSecrets,
+				CreateClientKeyFn: func(_ context.Context) (*fastly.ClientKey, error) { return ck, nil },
+				GetSigningKeyFn:   func(_ context.Context) (ed25519.PublicKey, error) { return skPub, nil },
+				CreateSecretFn:    createSecret,
+			},
+			Setup: func(_ *testing.T, _ *testutil.CLIScenario, _ *global.Data) {
+				copied = make(map[string]string)
+			},
+			WantOutput: fmt.Sprintf("Copied 2 keys from store '%s' to '%s'", fromID, toID),
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				want := map[string]string{"prod_api_key": "secret-2", "prod_db_password": "secret-3"}
+				if !maps.Equal(copied, want) {
+					t.Errorf("want %v, have %v", want, copied)
+				}
+			},
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{secretstore.RootNameStore, "copy"}, scenarios)
+}
+
 func TestDeleteStoreCommand(t *testing.T) {
 	const storeID = "test123"
 	errStoreNotFound := errors.New("store not found")
@@ -12,6 +12,7 @@ import (
 
 	"github.com/fastly/go-fastly/v17/fastly"
 
+	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/argparser"
 	fsterr "github.com/fastly/cli/pkg/errors"
 	"github.com/fastly/cli/pkg/global"
@@ -20,8 +21,8 @@ import (
 
 const (
 	// Maximum secret length, as defined at https://www.fastly.com/documentation/reference/api/services/resources/secret-store-secret
-	maxSecretKiB = 64
-	maxSecretLen = maxSecretKiB * 1024
+	MaxSecretKiB = 64
+	MaxSecretLen = MaxSecretKiB * 1024
 )
 
 // verificationKey is Fastly's Ed25519 public key, used to verify signatures
@@ -45,6 +46,29 @@ func mustDecode(s string) []byte {
 	return b
 }
 
+// NewClientKey returns a client key to encrypt secrets with, once its
+// signature is verified with Fastly's signing key.
+func NewClientKey(client api.Interface) (*fastly.ClientKey, error) {
+	ck, err := client.CreateClientKey(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	apiPublicKey, err := client.GetSigningKey(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(apiPublicKey, verificationKey) && os.Getenv("FASTLY_USE_API_SIGNING_KEY") == "" {
+		return nil, fmt.Errorf("API public key does not match expected verification key")
+	}
+
+	if !ck.VerifySignature(apiPublicKey) {
+		return nil, fmt.Errorf("unable to verify signature of client key")
+	}
+	return ck, nil
+}
+
 // NewCreateCommand returns a usable command registered under the parent.
 func NewCreateCommand(parent argparser.Registerer, g *global.Data) *CreateCommand {
 	c := CreateCommand{
@@ -56,12 +80,16 @@ func NewCreateCommand(parent argparser.Registerer, g *global.Data) *CreateComman
 	c.CmdClause = parent.Command("create", "Create a new secret within specified store")
 
 	// Required.
-	c.RegisterFlag(secretNameFlag(&c.Input.Name))           // --name
 	c.RegisterFlag(argparser.StoreIDFlag(&c.Input.StoreID)) // --store-id
 
 	// Optional.
 	c.RegisterFlag(secretFileFlag(&c.secretFile)) // --file
-	c.RegisterFlagBool(c.JSONFlag())              // --json
+	c.CmdClause.Flag("from-env", "Read secret value from an environment variable instead of prompt").StringVar(&c.fromEnv)
+	c.CmdClause.Flag("from-exec", "Read secret value from the output of a shell command (e.g. 'vault kv get -field=password secret/db') instead of prompt. A single trailing newline is removed").StringVar(&c.fromExec)
+	c.CmdClause.Flag("from-file", "Alias of --file").StringVar(&c.fromFile)
+	c.RegisterFlagBool(c.JSONFlag()) // --json
+	c.CmdClause.Flag("manifest", "Path to a TOML file mapping the names of several secrets to their source, e.g. [secrets.DB_PASSWORD] with one of env, exec or file set (relative files are resolved against the manifest directory)").StringVar(&c.manifest)
+	c.RegisterFlag(secretNameOptionalFlag(&c.Input.Name)) // --name
 	c.RegisterFlagBool(argparser.BoolFlagOpts{
 		Name:        "recreate",
 		Description: "Recreate secret by name (errors if secret doesn't already exist)",
@@ -85,6 +113,10 @@ type CreateCommand struct {
 	argparser.JSONOutput
 
 	Input         fastly.CreateSecretInput
+	fromEnv       string
+	fromExec      string
+	fromFile      string
+	manifest      string
 	recreate      bool
 	recreateAllow bool
 	secretFile    string
@@ -92,13 +124,13 @@ type CreateCommand struct {
 }
 
 var errMultipleSecretValue = fsterr.RemediationError{
-	Inner:       fmt.Errorf("invalid flag combination, --file and --stdin"),
-	Remediation: "Use one of --file or --stdin flag",
+	Inner:       fmt.Errorf("invalid flag combination, multiple secret sources"),
+	Remediation: "Use one of --file, --from-env, --from-exec, --manifest or --stdin flag",
 }
 
 var errMaxSecretLength = fsterr.RemediationError{
 	Inner:       fmt.Errorf("max secret size exceeded"),
-	Remediation: fmt.Sprintf("Maximum secret size is %dKiB", maxSecretKiB),
+	Remediation: fmt.Sprintf("Maximum secret size is %dKiB", MaxSecretKiB),
 }
 
 // Exec invokes the application logic for the command.
@@ -106,9 +138,31 @@ func (c *CreateCommand) Exec(in io.Reader, out io.Writer) error {
 	if c.Globals.Verbose() && c.JSONOutput.Enabled {
 		return fsterr.ErrInvalidVerboseJSONCombo
 	}
-	if c.secretFile != "" && c.secretSTDIN {
+	var sources int
+	for _, set := range []bool{c.secretFile != "", c.fromEnv != "", c.fromExec != "", c.fromFile != "", c.manifest != "", c.secretSTDIN} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
 		return errMultipleSecretValue
 	}
+	if c.fromFile != "" {
+		c.secretFile = c.fromFile
+	}
+
+	switch {
+	case c.manifest != "" && c.Input.Name != "":
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("invalid flag combination, --manifest and --name"),
+			Remediation: "Remove --name, as the names of the secrets are defined by the manifest.",
+		}
+	case c.manifest == "" && c.Input.Name == "":
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("error parsing arguments: required flag --name not provided"),
+			Remediation: "Provide the name of the secret with --name, or create several secrets with --manifest.",
+		}
+	}
 
 	switch {
 	case c.recreate && c.recreateAllow:
@@ -122,7 +176,12 @@ func (c *CreateCommand) Exec(in io.Reader, out io.Writer) error {
 		c.Input.Method = http.MethodPut
 	}
 
-	// Read secret's value: either from STDIN, a file, or prompt.
+	if c.manifest != "" {
+		return c.createFromManifest(in, out)
+	}
+
+	// Read secret's value: either from STDIN, a file, an environment
+	// variable, a command, or prompt.
 	switch {
 	case c.secretSTDIN:
 		// Determine if 'in' has data available.
@@ -142,6 +201,15 @@ func (c *CreateCommand) Exec(in io.Reader, out io.Writer) error {
 			return err
 		}
 
+	case c.fromEnv != "" || c.fromExec != "":
+		var err error
+		src := secretSource{Env: c.fromEnv, Exec: c.fromExec}
+		// nosemgrep: trailofbits.go.questionable-assignment.questionable-assignment
+		if c.Input.Secret, err = src.read(in, c.Globals.ErrOutput); err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+
 	default:
 		secret, err := text.InputSecure(out, "Secret: ", in)
 		if err != nil {
@@ -150,57 +218,95 @@ func (c *CreateCommand) Exec(in io.Reader, out io.Writer) error {
 		c.Input.Secret = []byte(secret)
 	}
 
-	if len(c.Input.Secret) > maxSecretLen {
+	if len(c.Input.Secret) > MaxSecretLen {
 		return errMaxSecretLength
 	}
 
-	ck, err := c.Globals.APIClient.CreateClientKey(context.TODO())
+	ck, err := NewClientKey(c.Globals.APIClient)
 	if err != nil {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
 
-	apiPublicKey, err := c.Globals.APIClient.GetSigningKey(context.TODO())
+	o, err := c.create(ck, c.Input.Name, c.Input.Secret)
 	if err != nil {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
 
-	if !bytes.Equal(apiPublicKey, verificationKey) && os.Getenv("FASTLY_USE_API_SIGNING_KEY") == "" {
-		err := fmt.Errorf("API public key does not match expected verification key")
-		c.Globals.ErrLog.Add(err)
+	if ok, err := c.WriteJSON(out, o); ok {
 		return err
 	}
 
-	if !ck.VerifySignature(apiPublicKey) {
-		err := fmt.Errorf("unable to verify signature of client key")
-		c.Globals.ErrLog.Add(err)
-		return err
-	}
+	c.printSuccess(out, o)
+	return nil
+}
 
-	wrapped, err := ck.Encrypt(c.Input.Secret)
+// createFromManifest creates the secrets defined by the manifest. Every value
+// is read before any secret is created.
+func (c *CreateCommand) createFromManifest(in io.Reader, out io.Writer) error {
+	m, err := readManifest(c.manifest)
 	if err != nil {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
 
-	c.Input.Secret = wrapped
-	c.Input.ClientKey = ck.PublicKey
+	names := m.names()
+	values := make(map[string][]byte, len(names))
+	for _, name := range names {
+		v, err := m.Secrets[name].read(in, c.Globals.ErrOutput)
+		if err != nil {
+			err = fmt.Errorf("error reading the secret '%s': %w", name, err)
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		values[name] = v
+	}
 
-	o, err := c.Globals.APIClient.CreateSecret(context.TODO(), &c.Input)
+	ck, err := NewClientKey(c.Globals.APIClient)
 	if err != nil {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
 
-	if ok, err := c.WriteJSON(out, o); ok {
-		return err
+	secrets := make([]*fastly.Secret, 0, len(names))
+	for _, name := range names {
+		o, err := c.create(ck, name, values[name])
+		if err != nil {
+			err = fmt.Errorf("error creating the secret '%s': %w", name, err)
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		secrets = append(secrets, o)
+		if !c.JSONOutput.Enabled {
+			c.printSuccess(out, o)
+		}
+	}
+
+	_, err = c.WriteJSON(out, secrets)
+	return err
+}
+
+// create encrypts the value with the client key and creates the secret.
+func (c *CreateCommand) create(ck *fastly.ClientKey, name string, value []byte) (*fastly.Secret, error) {
+	wrapped, err := ck.Encrypt(value)
+	if err != nil {
+		return nil, err
 	}
 
+	input := c.Input
+	input.Name = name
+	input.Secret = wrapped
+	input.ClientKey = ck.PublicKey
+
+	return c.Globals.APIClient.CreateSecret(context.TODO(), &input)
+}
+
+// printSuccess prints the secret that was created or recreated.
+func (c *CreateCommand) printSuccess(out io.Writer, o *fastly.Secret) {
 	action := "Created"
 	if o.Recreated {
 		action = "Recreated"
 	}
 	text.Success(out, "%s secret '%s' in Secret Store '%s' (digest: %s)", action, o.Name, c.Input.StoreID, hex.EncodeToString(o.Digest))
-	return nil
 }
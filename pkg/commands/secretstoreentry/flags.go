@@ -14,6 +14,12 @@ func secretNameFlag(dst *string) argparser.StringFlagOpts {
 	}
 }
 
+func secretNameOptionalFlag(dst *string) argparser.StringFlagOpts {
+	f := secretNameFlag(dst)
+	f.Required = false
+	return f
+}
+
 func secretFileFlag(dst *string) argparser.StringFlagOpts {
 	return argparser.StringFlagOpts{
 		Name:        "file",
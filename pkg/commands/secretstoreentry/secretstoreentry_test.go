@@ -43,6 +43,42 @@ func TestCreateSecretCommand(t *testing.T) {
 	}
 	doesNotExistFile := path.Join(tmpDir, "DOES-NOT-EXIST")
 
+	t.Setenv("FASTLY_TEST_SECRET", secretValue)
+
+	// The command prints the secret with a trailing newline, which is removed.
+	secretCommand := path.Join(tmpDir, "secret.sh")
+	script := "#!/bin/sh\necho " + secretValue + "\n"
+	if runtime.GOOS == "windows" {
+		secretCommand = path.Join(tmpDir, "secret.bat")
+		script = "@echo " + secretValue + "\r\n"
+	}
+	if err := os.WriteFile(secretCommand, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	// The command prompts on the error output, which is passed through.
+	promptCommand := path.Join(tmpDir, "prompt.sh")
+	prompt := "Authenticating\n"
+	script = "#!/bin/sh\necho Authenticating >&2\necho " + secretValue + "\n"
+	if runtime.GOOS == "windows" {
+		promptCommand = path.Join(tmpDir, "prompt.bat")
+		prompt = "Authenticating\r\n"
+		script = "@echo Authenticating>&2\r\n@echo " + secretValue + "\r\n"
+	}
+	if err := os.WriteFile(promptCommand, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	manifestFile := path.Join(tmpDir, "secrets.toml")
+	manifest := fmt.Sprintf("[secrets.a]\nenv = \"FASTLY_TEST_SECRET\"\n[secrets.b]\nexec = '%s'\n[secrets.c]\nfile = \"secret-file\"\n", secretCommand)
+	if err := os.WriteFile(manifestFile, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalidManifestFile := path.Join(tmpDir, "invalid.toml")
+	if err := os.WriteFile(invalidManifestFile, []byte("[secrets.a]\nenv = \"FASTLY_TEST_SECRET\"\nfile = \"secret-file\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
 	ckPub, ckPriv, err := box.GenerateKey(rand.Reader)
 	if err != nil {
 		t.Fatal(err)
@@ -70,6 +106,18 @@ func TestCreateSecretCommand(t *testing.T) {
 		return string(plaintext), nil
 	}
 
+	mockCreateSecret := func(_ context.Context, i *fastly.CreateSecretInput) (*fastly.Secret, error) {
+		if got, err := decrypt(i.Secret); err != nil {
+			return nil, err
+		} else if got != secretValue {
+			return nil, fmt.Errorf("invalid secret: %s", got)
+		}
+		return &fastly.Secret{
+			Name:   i.Name,
+			Digest: []byte(secretDigest),
+		}, nil
+	}
+
 	scenarios := []struct {
 		args           string
 		stdin          string
@@ -86,6 +134,30 @@ func TestCreateSecretCommand(t *testing.T) {
 			args:      "create --store-id abc123",
 			wantError: "error parsing arguments: required flag --name not provided",
 		},
+		{
+			args:      fmt.Sprintf("create --store-id %s --name %s --file %s --from-env FASTLY_TEST_SECRET", storeID, secretName, secretFile),
+			wantError: "invalid flag combination, multiple secret sources",
+		},
+		{
+			args:      fmt.Sprintf("create --store-id %s --name %s --file %s --from-file %s", storeID, secretName, secretFile, secretFile),
+			wantError: "invalid flag combination, multiple secret sources",
+		},
+		{
+			args:      fmt.Sprintf("create --store-id %s --name %s --manifest %s", storeID, secretName, manifestFile),
+			wantError: "invalid flag combination, --manifest and --name",
+		},
+		{
+			args:      fmt.Sprintf("create --store-id %s --name %s --from-env FASTLY_TEST_DOES_NOT_EXIST", storeID, secretName),
+			wantError: "the environment variable FASTLY_TEST_DOES_NOT_EXIST is not set",
+		},
+		{
+			args:      fmt.Sprintf("create --store-id %s --name %s --from-exec %s", storeID, secretName, doesNotExistFile),
+			wantError: "error running",
+		},
+		{
+			args:      fmt.Sprintf("create --store-id %s --manifest %s", storeID, invalidManifestFile),
+			wantError: "error reading the secret 'a': exactly one of env, exec or file must be set",
+		},
 		{
 			args: fmt.Sprintf("create --store-id %s --name %s --file %s", storeID, secretName, doesNotExistFile),
 			wantError: func() string {
@@ -169,6 +241,63 @@ func TestCreateSecretCommand(t *testing.T) {
 				Digest: []byte(secretDigest),
 			}),
 		},
+		// Read from file with the --from-file alias.
+		{
+			args: fmt.Sprintf("create --store-id %s --name %s --from-file %s", storeID, secretName, secretFile),
+			api: mock.API{
+				CreateClientKeyFn: mockCreateClientKey,
+				GetSigningKeyFn:   mockGetSigningKey,
+				CreateSecretFn:    mockCreateSecret,
+			},
+			wantAPIInvoked: true,
+			wantOutput:     fstfmt.Success("Created secret '%s' in Secret Store '%s' (digest: %s)", secretName, storeID, hex.EncodeToString([]byte(secretDigest))),
+		},
+		// Read from an environment variable.
+		{
+			args: fmt.Sprintf("create --store-id %s --name %s --from-env FASTLY_TEST_SECRET", storeID, secretName),
+			api: mock.API{
+				CreateClientKeyFn: mockCreateClientKey,
+				GetSigningKeyFn:   mockGetSigningKey,
+				CreateSecretFn:    mockCreateSecret,
+			},
+			wantAPIInvoked: true,
+			wantOutput:     fstfmt.Success("Created secret '%s' in Secret Store '%s' (digest: %s)", secretName, storeID, hex.EncodeToString([]byte(secretDigest))),
+		},
+		// Read from the output of a command.
+		{
+			args: fmt.Sprintf("create --store-id %s --name %s --from-exec %s", storeID, secretName, secretCommand),
+			api: mock.API{
+				CreateClientKeyFn: mockCreateClientKey,
+				GetSigningKeyFn:   mockGetSigningKey,
+				CreateSecretFn:    mockCreateSecret,
+			},
+			wantAPIInvoked: true,
+			wantOutput:     fstfmt.Success("Created secret '%s' in Secret Store '%s' (digest: %s)", secretName, storeID, hex.EncodeToString([]byte(secretDigest))),
+		},
+		// The prompt of the command is written to the error output.
+		{
+			args: fmt.Sprintf("create --store-id %s --name %s --from-exec %s", storeID, secretName, promptCommand),
+			api: mock.API{
+				CreateClientKeyFn: mockCreateClientKey,
+				GetSigningKeyFn:   mockGetSigningKey,
+				CreateSecretFn:    mockCreateSecret,
+			},
+			wantAPIInvoked: true,
+			wantOutput:     prompt + fstfmt.Success("Created secret '%s' in Secret Store '%s' (digest: %s)", secretName, storeID, hex.EncodeToString([]byte(secretDigest))),
+		},
+		// Read several secrets from a manifest.
+		{
+			args: fmt.Sprintf("create --store-id %s --manifest %s", storeID, manifestFile),
+			api: mock.API{
+				CreateClientKeyFn: mockCreateClientKey,
+				GetSigningKeyFn:   mockGetSigningKey,
+				CreateSecretFn:    mockCreateSecret,
+			},
+			wantAPIInvoked: true,
+			wantOutput: fstfmt.Success("Created secret 'a' in Secret Store '%s' (digest: %s)", storeID, hex.EncodeToString([]byte(secretDigest))) +
+				fstfmt.Success("Created secret 'b' in Secret Store '%s' (digest: %s)", storeID, hex.EncodeToString([]byte(secretDigest))) +
+				fstfmt.Success("Created secret 'c' in Secret Store '%s' (digest: %s)", storeID, hex.EncodeToString([]byte(secretDigest))),
+		},
 		// CreateOrRecreate
 		{
 			args: fmt.Sprintf("create --store-id %s --name %s --file %s --json --recreate-allow", storeID, secretName, secretFile),
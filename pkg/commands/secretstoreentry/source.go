@@ -0,0 +1,154 @@
+package secretstoreentry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	toml "github.com/pelletier/go-toml"
+)
+
+// secretSource is where the value of a secret is read from. Exactly one of its
+// fields must be set.
+type secretSource struct {
+	// Env is the name of an environment variable.
+	Env string `toml:"env"`
+	// Exec is a shell command (e.g. `vault kv get -field=password secret/db`)
+	// whose standard output is the value. A single trailing newline is removed.
+	Exec string `toml:"exec"`
+	// File is the path to a file whose content is the value.
+	File string `toml:"file"`
+}
+
+// read returns the value of the secret. The input and error output are passed
+// to an exec source.
+func (s secretSource) read(in io.Reader, errOut io.Writer) ([]byte, error) {
+	var set int
+	for _, v := range []string{s.Env, s.Exec, s.File} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("exactly one of env, exec or file must be set")
+	}
+
+	var (
+		value []byte
+		err   error
+	)
+	switch {
+	case s.Env != "":
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return nil, fmt.Errorf("the environment variable %s is not set", s.Env)
+		}
+		value = []byte(v)
+	case s.Exec != "":
+		value, err = runSecretCommand(s.Exec, in, errOut)
+	case s.File != "":
+		value, err = os.ReadFile(s.File)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) > MaxSecretLen {
+		return nil, errMaxSecretLength
+	}
+	return value, nil
+}
+
+// runSecretCommand runs a command with the shell and returns its standard
+// output. The command can read the input and write to the error output so that
+// tools such as the 1Password CLI can prompt for authentication.
+func runSecretCommand(command string, in io.Reader, errOut io.Writer) ([]byte, error) {
+	name, args := "sh", []string{"-c", command}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd.exe", []string{"/C", command}
+	}
+
+	var stdout bytes.Buffer
+	// gosec flagged this:
+	// G204 (CWE-78): Subprocess launched with variable
+	// Disabling as the command is provided by the user.
+	// #nosec
+	// nosemgrep
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = in
+	cmd.Stdout = &stdout
+	cmd.Stderr = errOut
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running '%s': %w", command, err)
+	}
+
+	value := stdout.Bytes()
+	if bytes.HasSuffix(value, []byte("\r\n")) {
+		value = value[:len(value)-2]
+	} else if bytes.HasSuffix(value, []byte("\n")) {
+		value = value[:len(value)-1]
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("'%s' produced no output", command)
+	}
+	return value, nil
+}
+
+// secretManifest maps the names of secrets to their source, e.g.
+//
+//	[secrets.DB_PASSWORD]
+//	exec = "vault kv get -field=password secret/db"
+//
+//	[secrets.API_TOKEN]
+//	env = "API_TOKEN"
+//
+//	[secrets.TLS_KEY]
+//	file = "tls/key.pem"
+type secretManifest struct {
+	Secrets map[string]secretSource `toml:"secrets"`
+}
+
+// readManifest reads a manifest file. Relative file sources are resolved
+// against the directory of the manifest.
+func readManifest(path string) (*secretManifest, error) {
+	// G304 (CWE-22): Potential file inclusion via variable
+	// #nosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var m secretManifest
+	if err := toml.NewDecoder(f).Strict(true).Decode(&m); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if len(m.Secrets) == 0 {
+		return nil, fmt.Errorf("error parsing %s: no secrets are defined", path)
+	}
+	for name, s := range m.Secrets {
+		if s.File != "" && !filepath.IsAbs(s.File) {
+			s.File = filepath.Join(filepath.Dir(path), s.File)
+			m.Secrets[name] = s
+		}
+	}
+	return &m, nil
+}
+
+// names returns the names of the secrets, sorted.
+func (m *secretManifest) names() []string {
+	names := make([]string, 0, len(m.Secrets))
+	for name := range m.Secrets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/service/snapshot"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/undo"
+)
+
+// ApplyCommand reconciles a service with a desired-state document by creating,
+// updating and deleting resources on an editable version of the service.
+type ApplyCommand struct {
+	argparser.Base
+
+	activate       bool
+	autoClone      argparser.OptionalAutoClone
+	file           string
+	format         string
+	serviceName    argparser.OptionalServiceNameID
+	serviceVersion argparser.OptionalServiceVersion
+}
+
+// NewApplyCommand returns a usable command registered under the parent.
+func NewApplyCommand(parent argparser.Registerer, g *global.Data) *ApplyCommand {
+	c := ApplyCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("apply", "Make a Fastly service version match a desired-state document")
+
+	// Required.
+	c.CmdClause.Flag("file", "Path to a desired-state document (in the format produced by `service export`)").Short('f').Required().StringVar(&c.file)
+
+	// Optional.
+	c.CmdClause.Flag("activate", "Activate the service version once the changes have been applied and validated").BoolVar(&c.activate)
+	c.RegisterAutoCloneFlag(argparser.AutoCloneFlagOpts{
+		Action: c.autoClone.Set,
+		Dst:    &c.autoClone.Value,
+	})
+	c.CmdClause.Flag("format", "The document format (inferred from the --file extension, otherwise toml)").HintOptions(snapshot.Formats...).EnumVar(&c.format, snapshot.Formats...)
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagServiceIDName,
+		Description: argparser.FlagServiceIDDesc,
+		Dst:         &g.Manifest.Flag.ServiceID,
+		Short:       's',
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceName.Set,
+		Name:        argparser.FlagServiceName,
+		Description: argparser.FlagServiceNameDesc,
+		Dst:         &c.serviceName.Value,
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceVersion.Set,
+		Name:        argparser.FlagVersionName,
+		Description: "The service version to apply the changes to, or to clone when used with --autoclone (defaults to the active version): " + argparser.FlagVersionDesc,
+		Dst:         &c.serviceVersion.Value,
+	})
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ApplyCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	kinds := snapshot.Kinds(c.Globals.APIClient)
+
+	desired, err := readDocument(c.file, c.format, kinds)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	serviceID, source, changes, err := planChanges(c.Globals, kinds, desired, c.serviceName, &c.serviceVersion, out)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		text.Info(out, "No changes. Service %s version %d matches %s", serviceID, fastly.ToValue(source.Number), c.file)
+		return nil
+	}
+	writePlan(out, serviceID, fastly.ToValue(source.Number), c.file, changes)
+	text.Break(out)
+
+	if !c.Globals.Flags.AutoYes && !c.Globals.Flags.NonInteractive {
+		prompt := "Apply these changes? [y/N] "
+		if snapshot.Summarize(changes).Immediate > 0 {
+			prompt = "Apply these changes, including those that affect the live service immediately? [y/N] "
+		}
+		answer, err := text.AskYesNo(out, prompt, in)
+		if err != nil {
+			return err
+		}
+		if !answer {
+			return nil
+		}
+		text.Break(out)
+	}
+
+	serviceVersion, err := c.autoClone.Parse(source, serviceID, c.Globals.Verbose(), out, c.Globals.APIClient)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": fastly.ToValue(source.Number),
+		})
+		return err
+	}
+	version := fastly.ToValue(serviceVersion.Number)
+
+	undoStack := undo.NewStack()
+	defer func() {
+		if err != nil && undoStack.Len() > 0 {
+			text.Warning(out, "Reverting the changes made to service %s version %d", serviceID, version)
+			undoStack.RunIfError(out, err)
+		}
+	}()
+
+	err = snapshot.Apply(context.TODO(), kinds, serviceID, version, changes, undoStack, func(change snapshot.Change) {
+		if c.Globals.Verbose() {
+			text.Output(out, "%s %s %q", actionVerbs[change.Action], change.Kind, change.Name)
+		}
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": version,
+		})
+		return err
+	}
+
+	valid, msg, err := c.Globals.APIClient.ValidateVersion(context.TODO(), &fastly.ValidateVersionInput{
+		ServiceID:      serviceID,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": version,
+		})
+		return fmt.Errorf("error validating service version %d: %w", version, err)
+	}
+	if !valid {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("service version %d is invalid: %s", version, msg),
+			Remediation: "Correct the desired-state document and run `fastly service plan` to review the changes before applying them again.",
+		}
+	}
+
+	text.Success(out, "Applied %d changes to service %s version %d", len(changes), serviceID, version)
+
+	if !c.activate {
+		return nil
+	}
+	_, err = c.Globals.APIClient.ActivateVersion(context.TODO(), &fastly.ActivateVersionInput{
+		ServiceID:      serviceID,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": version,
+		})
+		return fmt.Errorf("error activating service version %d: %w", version, err)
+	}
+	text.Success(out, "Activated service %s version %d", serviceID, version)
+	return nil
+}
+
+// actionVerbs describes each planned action once it has been applied.
+var actionVerbs = map[snapshot.Action]string{
+	snapshot.ActionAdded:   "Created",
+	snapshot.ActionChanged: "Updated",
+	snapshot.ActionRemoved: "Deleted",
+}
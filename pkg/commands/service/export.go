@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/service/snapshot"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// ExportCommand writes the configuration of a service version to a
+// declarative TOML or JSON document.
+type ExportCommand struct {
+	argparser.Base
+
+	file           string
+	format         string
+	serviceName    argparser.OptionalServiceNameID
+	serviceVersion argparser.OptionalServiceVersion
+}
+
+// NewExportCommand returns a usable command registered under the parent.
+func NewExportCommand(parent argparser.Registerer, g *global.Data) *ExportCommand {
+	c := ExportCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("export", "Export the configuration of a Fastly service version as a TOML or JSON document")
+
+	// Optional.
+	c.CmdClause.Flag("file", "Path to write the document to (defaults to stdout). NOTE: the document may contain credentials used by logging endpoints and backends").StringVar(&c.file)
+	c.CmdClause.Flag("format", "The document format (inferred from the --file extension, otherwise toml)").HintOptions(snapshot.Formats...).EnumVar(&c.format, snapshot.Formats...)
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagServiceIDName,
+		Description: argparser.FlagServiceIDDesc,
+		Dst:         &g.Manifest.Flag.ServiceID,
+		Short:       's',
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceName.Set,
+		Name:        argparser.FlagServiceName,
+		Description: argparser.FlagServiceNameDesc,
+		Dst:         &c.serviceName.Value,
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceVersion.Set,
+		Name:        argparser.FlagVersionName,
+		Description: argparser.FlagVersionDesc,
+		Dst:         &c.serviceVersion.Value,
+	})
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ExportCommand) Exec(_ io.Reader, out io.Writer) error {
+	serviceID, serviceVersion, err := argparser.ServiceDetails(argparser.ServiceDetailsOpts{
+		APIClient:          c.Globals.APIClient,
+		Manifest:           *c.Globals.Manifest,
+		Out:                out,
+		ServiceNameFlag:    c.serviceName,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flags.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": fsterr.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+	version := fastly.ToValue(serviceVersion.Number)
+
+	s, err := c.Globals.APIClient.GetService(context.TODO(), &fastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID": serviceID,
+		})
+		return err
+	}
+
+	doc, err := snapshot.Capture(context.TODO(), snapshot.Kinds(c.Globals.APIClient), serviceID, version)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": version,
+		})
+		return err
+	}
+	doc.Service.Name = fastly.ToValue(s.Name)
+	doc.Service.Type = fastly.ToValue(s.Type)
+
+	format := snapshot.Format(c.format)
+	if format == "" {
+		format = snapshot.FormatFromPath(c.file)
+	}
+	data, err := doc.Encode(format)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error encoding service configuration: %w", err)
+	}
+
+	if c.file == "" {
+		_, err = out.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(c.file, data, 0o600); err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	text.Success(out, "Exported %d resources from service %s version %d to %s", doc.Count(), serviceID, version, c.file)
+	return nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"4d63.com/optional"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/service/snapshot"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// ImportCommand recreates the resources described by a document produced by
+// `service export` on an editable service version.
+type ImportCommand struct {
+	argparser.Base
+
+	autoClone      argparser.OptionalAutoClone
+	file           string
+	format         string
+	serviceName    argparser.OptionalServiceNameID
+	serviceVersion argparser.OptionalServiceVersion
+}
+
+// NewImportCommand returns a usable command registered under the parent.
+func NewImportCommand(parent argparser.Registerer, g *global.Data) *ImportCommand {
+	c := ImportCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("import", "Create the resources described by a `service export` document on a Fastly service version")
+
+	// Required.
+	c.CmdClause.Flag("file", "Path to a document produced by `service export`").Required().StringVar(&c.file)
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagVersionName,
+		Description: argparser.FlagVersionDesc,
+		Dst:         &c.serviceVersion.Value,
+		Required:    true,
+	})
+
+	// Optional.
+	c.RegisterAutoCloneFlag(argparser.AutoCloneFlagOpts{
+		Action: c.autoClone.Set,
+		Dst:    &c.autoClone.Value,
+	})
+	c.CmdClause.Flag("format", "The document format (inferred from the --file extension, otherwise toml)").HintOptions(snapshot.Formats...).EnumVar(&c.format, snapshot.Formats...)
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagServiceIDName,
+		Description: argparser.FlagServiceIDDesc,
+		Dst:         &g.Manifest.Flag.ServiceID,
+		Short:       's',
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceName.Set,
+		Name:        argparser.FlagServiceName,
+		Description: argparser.FlagServiceNameDesc,
+		Dst:         &c.serviceName.Value,
+	})
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ImportCommand) Exec(_ io.Reader, out io.Writer) error {
+	kinds := snapshot.Kinds(c.Globals.APIClient)
+
+	doc, err := readDocument(c.file, c.format, kinds)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	serviceID, serviceVersion, err := argparser.ServiceDetails(argparser.ServiceDetailsOpts{
+		Active:             optional.Of(false),
+		Locked:             optional.Of(false),
+		AutoCloneFlag:      c.autoClone,
+		APIClient:          c.Globals.APIClient,
+		Manifest:           *c.Globals.Manifest,
+		Out:                out,
+		ServiceNameFlag:    c.serviceName,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flags.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": fsterr.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+	version := fastly.ToValue(serviceVersion.Number)
+
+	// Check for name collisions before creating anything, so an import either
+	// applies cleanly or doesn't start at all.
+	var conflicts []string
+	for _, k := range kinds {
+		if len(doc.Resources[k.Key]) == 0 {
+			continue
+		}
+		existing, err := k.List(context.TODO(), serviceID, version)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]any{
+				"Service ID":      serviceID,
+				"Service Version": version,
+			})
+			return fmt.Errorf("error listing %s: %w", k.Key, err)
+		}
+		names := make(map[string]bool, len(existing))
+		for _, r := range existing {
+			names[r.Name()] = true
+		}
+		for _, r := range doc.Resources[k.Key] {
+			if names[r.Name()] {
+				conflicts = append(conflicts, fmt.Sprintf("%s %q", k.Key, r.Name()))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("service version %d already contains: %s", version, strings.Join(conflicts, ", ")),
+			Remediation: "Import into a version that doesn't contain these resources (e.g. a newly created service), or delete them first.",
+		}
+	}
+
+	var created int
+	for _, k := range kinds {
+		for _, r := range doc.Resources[k.Key] {
+			if err := k.Create(context.TODO(), serviceID, version, r); err != nil {
+				c.Globals.ErrLog.AddWithContext(err, map[string]any{
+					"Service ID":      serviceID,
+					"Service Version": version,
+					"Resource Type":   k.Key,
+					"Resource Name":   r.Name(),
+				})
+				return fmt.Errorf("error creating %s %q (%d of %d resources were created): %w", k.Key, r.Name(), created, doc.Count(), err)
+			}
+			created++
+			if c.Globals.Verbose() {
+				text.Output(out, "Created %s %q", k.Key, r.Name())
+			}
+		}
+	}
+
+	text.Success(out, "Imported %d resources into service %s version %d", created, serviceID, version)
+	return nil
+}
+
+// readDocument reads and decodes a service document from disk.
+func readDocument(path, format string, kinds []snapshot.Kind) (*snapshot.Document, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	f := snapshot.Format(format)
+	if f == "" {
+		f = snapshot.FormatFromPath(path)
+	}
+	doc, err := snapshot.Decode(data, f, kinds)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return doc, nil
+}
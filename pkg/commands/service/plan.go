@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/service/snapshot"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// PlanCommand shows the changes `service apply` would make to bring a service
+// version in line with a desired-state document.
+type PlanCommand struct {
+	argparser.Base
+	argparser.JSONOutput
+
+	file           string
+	format         string
+	serviceName    argparser.OptionalServiceNameID
+	serviceVersion argparser.OptionalServiceVersion
+}
+
+// NewPlanCommand returns a usable command registered under the parent.
+func NewPlanCommand(parent argparser.Registerer, g *global.Data) *PlanCommand {
+	c := PlanCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("plan", "Show the changes required to make a Fastly service version match a desired-state document")
+
+	// Required.
+	c.CmdClause.Flag("file", "Path to a desired-state document (in the format produced by `service export`)").Short('f').Required().StringVar(&c.file)
+
+	// Optional.
+	c.CmdClause.Flag("format", "The document format (inferred from the --file extension, otherwise toml)").HintOptions(snapshot.Formats...).EnumVar(&c.format, snapshot.Formats...)
+	c.RegisterFlagBool(c.JSONFlag()) // --json
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagServiceIDName,
+		Description: argparser.FlagServiceIDDesc,
+		Dst:         &g.Manifest.Flag.ServiceID,
+		Short:       's',
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceName.Set,
+		Name:        argparser.FlagServiceName,
+		Description: argparser.FlagServiceNameDesc,
+		Dst:         &c.serviceName.Value,
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceVersion.Set,
+		Name:        argparser.FlagVersionName,
+		Description: "The service version to compare against (defaults to the active version): " + argparser.FlagVersionDesc,
+		Dst:         &c.serviceVersion.Value,
+	})
+	return &c
+}
+
+// planOutput is the structure emitted when --json is set.
+type planOutput struct {
+	ServiceID string            `json:"service_id"`
+	Version   int               `json:"version"`
+	Summary   snapshot.Summary  `json:"summary"`
+	Changes   []snapshot.Change `json:"changes"`
+}
+
+// Exec invokes the application logic for the command.
+func (c *PlanCommand) Exec(_ io.Reader, out io.Writer) error {
+	if c.Globals.Verbose() && c.JSONOutput.Enabled {
+		return fsterr.ErrInvalidVerboseJSONCombo
+	}
+
+	kinds := snapshot.Kinds(c.Globals.APIClient)
+
+	desired, err := readDocument(c.file, c.format, kinds)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	serviceID, serviceVersion, changes, err := planChanges(c.Globals, kinds, desired, c.serviceName, &c.serviceVersion, out)
+	if err != nil {
+		return err
+	}
+	version := fastly.ToValue(serviceVersion.Number)
+
+	if ok, err := c.WriteJSON(out, planOutput{
+		ServiceID: serviceID,
+		Version:   version,
+		Summary:   snapshot.Summarize(changes),
+		Changes:   append([]snapshot.Change{}, changes...),
+	}); ok {
+		return err
+	}
+
+	if len(changes) == 0 {
+		text.Info(out, "No changes. Service %s version %d matches %s", serviceID, version, c.file)
+		return nil
+	}
+	writePlan(out, serviceID, version, c.file, changes)
+	return nil
+}
+
+// planChanges resolves the service version that a desired-state document is
+// compared against, and returns the changes required to reconcile the two.
+func planChanges(
+	g *global.Data,
+	kinds []snapshot.Kind,
+	desired *snapshot.Document,
+	serviceName argparser.OptionalServiceNameID,
+	serviceVersion *argparser.OptionalServiceVersion,
+	out io.Writer,
+) (string, *fastly.Version, []snapshot.Change, error) {
+	serviceID, source, flag, err := argparser.ServiceID(serviceName, *g.Manifest, g.APIClient, g.ErrLog)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if g.Verbose() {
+		argparser.DisplayServiceID(serviceID, flag, source, out)
+	}
+
+	v, err := serviceVersion.Parse(serviceID, g.APIClient)
+	if err != nil {
+		g.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Value,
+		})
+		return serviceID, nil, nil, err
+	}
+
+	current, err := snapshot.Capture(context.TODO(), kinds, serviceID, fastly.ToValue(v.Number))
+	if err != nil {
+		g.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": fastly.ToValue(v.Number),
+		})
+		return serviceID, v, nil, err
+	}
+	return serviceID, v, snapshot.Plan(kinds, current, desired), nil
+}
+
+// writePlan renders the planned changes followed by a summary line.
+func writePlan(out io.Writer, serviceID string, version int, file string, changes []snapshot.Change) {
+	snapshot.WriteUnified(out, fmt.Sprintf("service %s version %d", serviceID, version), file, changes)
+	text.Break(out)
+	summary := snapshot.Summarize(changes)
+	text.Output(out, "Plan: %s.", summary)
+	if summary.Immediate > 0 {
+		text.Break(out)
+		text.Warning(out, "%d of these changes apply immediately to every version of the service, including the active version, because dictionary items, ACL entries and dynamic snippet content aren't versioned.", summary.Immediate)
+	}
+}
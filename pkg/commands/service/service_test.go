@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -20,6 +21,7 @@ import (
 	"github.com/fastly/cli/pkg/manifest"
 	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/cli/pkg/threadsafe"
 )
 
 func TestServiceCreate(t *testing.T) {
@@ -436,6 +438,389 @@ func TestServiceDelete(t *testing.T) {
 	}
 }
 
+func TestServiceExport(t *testing.T) {
+	scenarios := []testutil.CLIScenario{
+		{
+			Name:      "validate missing --service-id flag",
+			Args:      "--version 1",
+			EnvVars:   map[string]string{"FASTLY_SERVICE_ID": ""},
+			WantError: "error reading service: no service ID found",
+		},
+		{
+			Name: "validate export as toml to stdout",
+			Args: "--service-id 123 --version 1",
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:   testutil.GetVersion,
+				GetServiceFn:   getServiceOK,
+				ListBackendsFn: listBackendsOK,
+			}),
+			WantOutputs: []string{
+				"[service]",
+				`name = "Foo"`,
+				"[[backends]]",
+				`address = "example.com"`,
+				"port = 443",
+			},
+			DontWantOutput: "service_id",
+		},
+		{
+			Name: "validate export as json to stdout",
+			Args: "--service-id 123 --version 1 --format json",
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:   testutil.GetVersion,
+				GetServiceFn:   getServiceOK,
+				ListBackendsFn: listBackendsOK,
+			}),
+			WantOutputs: []string{
+				`"backends": [`,
+				`"address": "example.com"`,
+			},
+		},
+		{
+			Name: "validate list error is passed through",
+			Args: "--service-id 123 --version 1",
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn: testutil.GetVersion,
+				GetServiceFn: getServiceOK,
+				ListBackendsFn: func(_ context.Context, _ *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+					return nil, testutil.Err
+				},
+			}),
+			WantError: "error listing backends: test error",
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "export"}, scenarios)
+}
+
+func TestServiceImport(t *testing.T) {
+	document := testutil.MakeTempFile(t, serviceImportDocument)
+	defer os.RemoveAll(document)
+
+	var created []string
+	scenarios := []testutil.CLIScenario{
+		{
+			Name:      "validate missing --file flag",
+			Args:      "--service-id 123 --version 3",
+			WantError: "error parsing arguments: required flag --file not provided",
+		},
+		{
+			Name: "validate active version requires --autoclone",
+			Args: "--service-id 123 --version 1 --file " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn: testutil.GetVersion,
+			}),
+			WantError: "service version 1 is active",
+		},
+		{
+			Name: "validate conflicting resources are reported before creating anything",
+			Args: "--service-id 123 --version 3 --file " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:   testutil.GetVersion,
+				ListBackendsFn: listBackendsOK,
+			}),
+			WantError: `service version 3 already contains: backends "origin"`,
+		},
+		{
+			Name: "validate successful import with --autoclone",
+			Args: "--service-id 123 --version 1 --autoclone --file " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:   testutil.GetVersion,
+				CloneVersionFn: testutil.CloneVersionResult(4),
+				CreateConditionFn: func(_ context.Context, i *fastly.CreateConditionInput) (*fastly.Condition, error) {
+					created = append(created, fmt.Sprintf("condition %s v%d", fastly.ToValue(i.Name), i.ServiceVersion))
+					return &fastly.Condition{}, nil
+				},
+				CreateBackendFn: func(_ context.Context, i *fastly.CreateBackendInput) (*fastly.Backend, error) {
+					created = append(created, fmt.Sprintf("backend %s v%d", fastly.ToValue(i.Name), i.ServiceVersion))
+					return &fastly.Backend{}, nil
+				},
+			}),
+			WantOutput: "Imported 2 resources into service 123 version 4",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				// Conditions must be created before the backends that reference them.
+				testutil.AssertEqual(t, []string{"condition is_api v4", "backend origin v4"}, created)
+			},
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "import"}, scenarios)
+}
+
+func TestServicePlan(t *testing.T) {
+	document := testutil.MakeTempFile(t, serviceDesiredDocument)
+	defer os.RemoveAll(document)
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Name:      "validate missing --file flag",
+			Args:      "--service-id 123 --version 1",
+			WantError: "error parsing arguments: required flag --file not provided",
+		},
+		{
+			Name: "validate plan against service version",
+			Args: "--service-id 123 --version 1 -f " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:   testutil.GetVersion,
+				ListBackendsFn: listBackendsOK,
+				ListDomainsFn:  listDomainsOK,
+			}),
+			WantOutputs: []string{
+				"--- service 123 version 1",
+				`@@ conditions "is_api" (added) @@`,
+				`@@ backends "origin" (changed) @@`,
+				"- port = 443",
+				"+ port = 8443",
+				`@@ domains "old.example.com" (removed) @@`,
+				"Plan: 1 to add, 1 to change, 1 to delete.",
+			},
+		},
+		{
+			Name: "validate no changes",
+			Args: "--service-id 123 --version 1 -f " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn: testutil.GetVersion,
+				ListBackendsFn: func(_ context.Context, _ *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+					return []*fastly.Backend{
+						{
+							Address:          fastly.ToPointer("example.com"),
+							ConnectTimeout:   fastly.ToPointer(1000),
+							Name:             fastly.ToPointer("origin"),
+							Port:             fastly.ToPointer(8443),
+							RequestCondition: fastly.ToPointer("is_api"),
+						},
+					}, nil
+				},
+				ListConditionsFn: func(_ context.Context, _ *fastly.ListConditionsInput) ([]*fastly.Condition, error) {
+					return []*fastly.Condition{
+						{
+							Name:      fastly.ToPointer("is_api"),
+							Statement: fastly.ToPointer(`req.url ~ "^/api"`),
+							Type:      fastly.ToPointer("REQUEST"),
+						},
+					}, nil
+				},
+			}),
+			WantOutput: "No changes. Service 123 version 1 matches " + document,
+		},
+		{
+			Name: "validate json output",
+			Args: "--service-id 123 --version 1 -f " + document + " --json",
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:   testutil.GetVersion,
+				ListBackendsFn: listBackendsOK,
+				ListDomainsFn:  listDomainsOK,
+			}),
+			WantOutputs: []string{
+				`"add": 1`,
+				`"change": 1`,
+				`"delete": 1`,
+				`"action": "removed"`,
+			},
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "plan"}, scenarios)
+}
+
+func TestServicePlanUnversioned(t *testing.T) {
+	document := testutil.MakeTempFile(t, dynamicSnippetDocument)
+	defer os.RemoveAll(document)
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Name: "validate dynamic snippet content applies immediately",
+			Args: "--service-id 123 --version 1 -f " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:        testutil.GetVersion,
+				ListSnippetsFn:      listDynamicSnippetsOK,
+				GetDynamicSnippetFn: getDynamicSnippetOK,
+			}),
+			WantOutputs: []string{
+				`@@ snippets "geo" (changed, applies immediately) @@`,
+				"Plan: 0 to add, 1 to change, 0 to delete.",
+				"1 of these changes apply immediately to every version of the service",
+			},
+		},
+		{
+			Name: "validate json output counts immediate changes",
+			Args: "--service-id 123 --version 1 -f " + document + " --json",
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:        testutil.GetVersion,
+				ListSnippetsFn:      listDynamicSnippetsOK,
+				GetDynamicSnippetFn: getDynamicSnippetOK,
+			}),
+			WantOutputs: []string{
+				`"immediate": 1`,
+				`"immediate": true`,
+			},
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "plan"}, scenarios)
+}
+
+func TestServiceApply(t *testing.T) {
+	document := testutil.MakeTempFile(t, serviceDesiredDocument)
+	defer os.RemoveAll(document)
+
+	var calls []string
+	reset := func(_ *testing.T, _ *testutil.CLIScenario, _ *global.Data) {
+		calls = nil
+	}
+	api := func(m mock.API) *mock.API {
+		m.GetVersionFn = testutil.GetVersion
+		m.CloneVersionFn = testutil.CloneVersionResult(4)
+		m.ListBackendsFn = listBackendsOK
+		m.ListDomainsFn = listDomainsOK
+		m.CreateConditionFn = func(_ context.Context, i *fastly.CreateConditionInput) (*fastly.Condition, error) {
+			calls = append(calls, fmt.Sprintf("create condition %s v%d", fastly.ToValue(i.Name), i.ServiceVersion))
+			return &fastly.Condition{}, nil
+		}
+		m.DeleteConditionFn = func(_ context.Context, i *fastly.DeleteConditionInput) error {
+			calls = append(calls, fmt.Sprintf("delete condition %s v%d", i.Name, i.ServiceVersion))
+			return nil
+		}
+		if m.UpdateBackendFn == nil {
+			m.UpdateBackendFn = func(_ context.Context, i *fastly.UpdateBackendInput) (*fastly.Backend, error) {
+				calls = append(calls, fmt.Sprintf("update backend %s v%d port %d", i.Name, i.ServiceVersion, fastly.ToValue(i.Port)))
+				return &fastly.Backend{}, nil
+			}
+		}
+		m.DeleteDomainFn = func(_ context.Context, i *fastly.DeleteDomainInput) error {
+			calls = append(calls, fmt.Sprintf("delete domain %s v%d", i.Name, i.ServiceVersion))
+			return nil
+		}
+		m.CreateDomainFn = func(_ context.Context, i *fastly.CreateDomainInput) (*fastly.Domain, error) {
+			calls = append(calls, fmt.Sprintf("create domain %s v%d", fastly.ToValue(i.Name), i.ServiceVersion))
+			return &fastly.Domain{}, nil
+		}
+		if m.ValidateVersionFn == nil {
+			m.ValidateVersionFn = func(_ context.Context, _ *fastly.ValidateVersionInput) (bool, string, error) {
+				return true, "", nil
+			}
+		}
+		m.ActivateVersionFn = func(_ context.Context, i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+			calls = append(calls, fmt.Sprintf("activate v%d", i.ServiceVersion))
+			return &fastly.Version{}, nil
+		}
+		return testutil.EmptyServiceResources(&m)
+	}
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Name:      "validate missing --file flag",
+			Args:      "--service-id 123",
+			WantError: "error parsing arguments: required flag --file not provided",
+		},
+		{
+			Name:      "validate active version requires --autoclone",
+			Args:      "--service-id 123 --version 1 --auto-yes -f " + document,
+			API:       api(mock.API{}),
+			WantError: "service version 1 is not editable",
+		},
+		{
+			Name:       "validate declining the prompt makes no changes",
+			Args:       "--service-id 123 --version 1 --autoclone -f " + document,
+			API:        api(mock.API{}),
+			Stdin:      []string{"n"},
+			Setup:      reset,
+			WantOutput: "Plan: 1 to add, 1 to change, 1 to delete.",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				testutil.AssertEqual(t, 0, len(calls))
+			},
+		},
+		{
+			Name:  "validate successful apply in dependency order with activation",
+			Args:  "--service-id 123 --version 1 --autoclone --activate --auto-yes -f " + document,
+			API:   api(mock.API{}),
+			Setup: reset,
+			WantOutputs: []string{
+				"Applied 3 changes to service 123 version 4",
+				"Activated service 123 version 4",
+			},
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				testutil.AssertEqual(t, []string{
+					"create condition is_api v4",
+					"update backend origin v4 port 8443",
+					"delete domain old.example.com v4",
+					"activate v4",
+				}, calls)
+			},
+		},
+		{
+			Name: "validate failed step rolls back earlier changes",
+			Args: "--service-id 123 --version 1 --autoclone --auto-yes -f " + document,
+			API: api(mock.API{
+				UpdateBackendFn: func(_ context.Context, _ *fastly.UpdateBackendInput) (*fastly.Backend, error) {
+					return nil, testutil.Err
+				},
+			}),
+			Setup:      reset,
+			WantError:  `error updating backends "origin": test error`,
+			WantOutput: "Reverting the changes made to service 123 version 4",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				testutil.AssertEqual(t, []string{
+					"create condition is_api v4",
+					"delete condition is_api v4",
+				}, calls)
+			},
+		},
+		{
+			Name: "validate invalid version rolls back every change",
+			Args: "--service-id 123 --version 1 --autoclone --activate --auto-yes -f " + document,
+			API: api(mock.API{
+				ValidateVersionFn: func(_ context.Context, _ *fastly.ValidateVersionInput) (bool, string, error) {
+					return false, "unknown backend", nil
+				},
+			}),
+			Setup:     reset,
+			WantError: "service version 4 is invalid: unknown backend",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				testutil.AssertEqual(t, []string{
+					"create condition is_api v4",
+					"update backend origin v4 port 8443",
+					"delete domain old.example.com v4",
+					"create domain old.example.com v4",
+					"update backend origin v4 port 443",
+					"delete condition is_api v4",
+				}, calls)
+			},
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "apply"}, scenarios)
+}
+
+func TestServiceApplyUnversioned(t *testing.T) {
+	document := testutil.MakeTempFile(t, dynamicSnippetDocument)
+	defer os.RemoveAll(document)
+
+	var updated bool
+	scenarios := []testutil.CLIScenario{
+		{
+			Name: "validate the prompt warns about changes to the live service",
+			Args: "--service-id 123 --version 1 --autoclone -f " + document,
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn:        testutil.GetVersion,
+				ListSnippetsFn:      listDynamicSnippetsOK,
+				GetDynamicSnippetFn: getDynamicSnippetOK,
+				UpdateDynamicSnippetFn: func(_ context.Context, _ *fastly.UpdateDynamicSnippetInput) (*fastly.DynamicSnippet, error) {
+					updated = true
+					return &fastly.DynamicSnippet{}, nil
+				},
+			}),
+			Stdin:      []string{"n"},
+			WantOutput: "Apply these changes, including those that affect the live service immediately? [y/N]",
+			Validator: func(t *testing.T, _ *testutil.CLIScenario, _ *global.Data, _ *threadsafe.Buffer) {
+				testutil.AssertEqual(t, false, updated)
+			},
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, "apply"}, scenarios)
+}
+
 var errTest = errors.New("fixture error")
 
 func createServiceOK(_ context.Context, i *fastly.CreateServiceInput) (*fastly.Service, error) {
@@ -729,3 +1114,77 @@ func deleteServiceOK(_ context.Context, _ *fastly.DeleteServiceInput) error {
 func deleteServiceError(_ context.Context, _ *fastly.DeleteServiceInput) error {
 	return errTest
 }
+
+func listBackendsOK(_ context.Context, i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+	return []*fastly.Backend{
+		{
+			Address:        fastly.ToPointer("example.com"),
+			Name:           fastly.ToPointer("origin"),
+			Port:           fastly.ToPointer(443),
+			ServiceID:      fastly.ToPointer(i.ServiceID),
+			ServiceVersion: fastly.ToPointer(i.ServiceVersion),
+		},
+	}, nil
+}
+
+func listDomainsOK(_ context.Context, _ *fastly.ListDomainsInput) ([]*fastly.Domain, error) {
+	return []*fastly.Domain{
+		{Name: fastly.ToPointer("old.example.com")},
+	}, nil
+}
+
+var serviceImportDocument = `
+[service]
+id = "abc"
+name = "Foo"
+type = "vcl"
+version = 7
+
+[[backends]]
+address = "example.com"
+name = "origin"
+port = 443
+request_condition = "is_api"
+
+[[conditions]]
+name = "is_api"
+statement = "req.url ~ \"^/api\""
+type = "REQUEST"
+`
+
+var dynamicSnippetDocument = `
+[[snippets]]
+content = "set req.http.X-Geo = client.geo.country_code;"
+name = "geo"
+`
+
+func listDynamicSnippetsOK(_ context.Context, _ *fastly.ListSnippetsInput) ([]*fastly.Snippet, error) {
+	return []*fastly.Snippet{
+		{
+			Dynamic:   fastly.ToPointer(1),
+			Name:      fastly.ToPointer("geo"),
+			SnippetID: fastly.ToPointer("abc"),
+			Type:      fastly.ToPointer(fastly.SnippetTypeRecv),
+		},
+	}, nil
+}
+
+func getDynamicSnippetOK(_ context.Context, _ *fastly.GetDynamicSnippetInput) (*fastly.DynamicSnippet, error) {
+	return &fastly.DynamicSnippet{
+		Content:   fastly.ToPointer("set req.http.X-Geo = \"\";"),
+		SnippetID: fastly.ToPointer("abc"),
+	}, nil
+}
+
+var serviceDesiredDocument = `
+[[backends]]
+address = "example.com"
+name = "origin"
+port = 8443
+request_condition = "is_api"
+
+[[conditions]]
+name = "is_api"
+statement = "req.url ~ \"^/api\""
+type = "REQUEST"
+`
@@ -0,0 +1,318 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// omitted are the fields that describe where a resource lives, or which are
+// generated by the API, rather than how the resource is configured. They're
+// dropped when a resource is converted so that documents can be applied to a
+// different service or version.
+var omitted = map[string]bool{
+	"acl_id":          true,
+	"created_at":      true,
+	"deleted_at":      true,
+	"dictionary_id":   true,
+	"entry_id":        true,
+	"link_id":         true,
+	"rate_limiter_id": true,
+	"service_id":      true,
+	"service_version": true,
+	"snippet_id":      true,
+	"updated_at":      true,
+}
+
+// FieldKey converts a Go struct field name into the snake_case key used
+// within a document (e.g. SSLCertHostname becomes ssl_cert_hostname).
+func FieldKey(name string) string {
+	// Acronyms swallow a trailing ID (e.g. ACLID), so it's split off first.
+	if len(name) > 2 && strings.HasSuffix(name, "ID") {
+		return FieldKey(strings.TrimSuffix(name, "ID")) + "_id"
+	}
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toResource converts an API response struct into a Resource.
+//
+// Only configuration fields are kept: nil pointers, timestamps and the fields
+// listed in `omitted` are dropped.
+func toResource(v any) Resource {
+	r := Resource{}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return r
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := FieldKey(f.Name)
+		if omitted[key] {
+			continue
+		}
+		if val, ok := plain(rv.Field(i)); ok {
+			r[key] = val
+		}
+	}
+	return r
+}
+
+// plain converts a reflected value into one of the basic types stored in a
+// Resource (string, bool, int64, float64, []any or map[string]any).
+func plain(v reflect.Value) (any, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if _, ok := v.Interface().(time.Time); ok {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true // #nosec G115
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, false
+		}
+		items := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if item, ok := plain(v.Index(i)); ok {
+				items = append(items, item)
+			}
+		}
+		return items, true
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		m := map[string]any{}
+		for _, k := range v.MapKeys() {
+			if item, ok := plain(v.MapIndex(k)); ok {
+				m[k.String()] = item
+			}
+		}
+		return m, true
+	case reflect.Struct:
+		return map[string]any(toResource(v.Interface())), true
+	default:
+		return nil, false
+	}
+}
+
+// populate assigns the values of r to the matching fields of the struct that
+// dst points to (typically an API input struct). Keys without a matching field
+// are ignored.
+func populate(r map[string]any, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot populate %T", dst)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := FieldKey(f.Name)
+		if omitted[key] {
+			continue
+		}
+		val, ok := r[key]
+		if !ok || val == nil {
+			continue
+		}
+		if err := assign(rv.Field(i), val); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// assign sets dst to val, allocating pointers and converting between the
+// basic document types and the destination type as required.
+func assign(dst reflect.Value, val any) error {
+	switch dst.Kind() {
+	case reflect.Pointer:
+		ptr := reflect.New(dst.Type().Elem())
+		if err := assign(ptr.Elem(), val); err != nil {
+			return err
+		}
+		dst.Set(ptr)
+		return nil
+	case reflect.Struct:
+		m, ok := val.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected a table, got %T", val)
+		}
+		return populate(m, dst.Addr().Interface())
+	case reflect.Slice:
+		items, ok := val.([]any)
+		if !ok {
+			return fmt.Errorf("expected a list, got %T", val)
+		}
+		s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		dst.Set(s)
+		return nil
+	case reflect.Map:
+		m, ok := val.(map[string]any)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("expected a table, got %T", val)
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(m))
+		for k, item := range m {
+			ev := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(ev, item); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), ev)
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(val))
+		return nil
+	}
+
+	switch v := val.(type) {
+	case string:
+		switch dst.Kind() {
+		case reflect.String:
+			dst.SetString(v)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			dst.SetInt(n)
+		case reflect.Float32, reflect.Float64:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			dst.SetFloat(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			dst.SetBool(b)
+		default:
+			return fmt.Errorf("cannot use string as %s", dst.Type())
+		}
+	case bool:
+		if dst.Kind() != reflect.Bool {
+			return fmt.Errorf("cannot use bool as %s", dst.Type())
+		}
+		dst.SetBool(v)
+	case int64, float64:
+		n := reflect.ValueOf(v)
+		switch dst.Kind() {
+		case reflect.String:
+			dst.SetString(fmt.Sprint(v))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			dst.Set(n.Convert(dst.Type()))
+		case reflect.Bool:
+			dst.SetBool(n.Convert(reflect.TypeFor[float64]()).Float() != 0)
+		default:
+			return fmt.Errorf("cannot use number as %s", dst.Type())
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", val)
+	}
+	return nil
+}
+
+// normalize converts decoded TOML/JSON values into the basic types produced by
+// toResource, so that values read from a file compare equal to values read
+// from the API.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, item := range t {
+			t[k] = normalize(item)
+		}
+		return t
+	case []any:
+		for i, item := range t {
+			t[i] = normalize(item)
+		}
+		return t
+	case []map[string]any:
+		items := make([]any, len(t))
+		for i, item := range t {
+			items[i] = normalize(item)
+		}
+		return items
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		f, _ := t.Float64()
+		return normalize(f)
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < math.MaxInt64 {
+			return int64(t)
+		}
+		return t
+	case int:
+		return int64(t)
+	case uint64:
+		return int64(t) // #nosec G115
+	case time.Time:
+		return t.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
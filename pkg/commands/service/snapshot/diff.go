@@ -0,0 +1,215 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Action describes how a resource differs between two documents.
+type Action string
+
+const (
+	// ActionAdded means the resource only exists in the second document.
+	ActionAdded Action = "added"
+	// ActionRemoved means the resource only exists in the first document.
+	ActionRemoved Action = "removed"
+	// ActionChanged means the resource exists in both documents but at least
+	// one of its fields differs.
+	ActionChanged Action = "changed"
+)
+
+// Change is the difference of a single resource between two documents.
+type Change struct {
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Action Action        `json:"action"`
+	Fields []FieldChange `json:"fields"`
+	// Old is the resource as found in the first document (nil when added).
+	Old Resource `json:"-"`
+	// New is the resource as found in the second document (nil when removed).
+	New Resource `json:"-"`
+	// Immediate is set by Plan when the change takes effect on the live
+	// service as soon as it's applied (see Kind.Immediate).
+	Immediate bool `json:"immediate,omitempty"`
+}
+
+// FieldChange is the difference of a single field of a resource.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// Diff compares two documents resource-by-resource. Resources are matched by
+// kind and name, and changes are returned in kind order then name order.
+func Diff(kinds []Kind, a, b *Document) []Change {
+	var changes []Change
+	for _, k := range kinds {
+		old := byName(a.Resources[k.Key])
+		cur := byName(b.Resources[k.Key])
+
+		names := map[string]bool{}
+		for n := range old {
+			names[n] = true
+		}
+		for n := range cur {
+			names[n] = true
+		}
+
+		for _, name := range sortedKeys(names) {
+			o, inOld := old[name]
+			n, inNew := cur[name]
+			switch {
+			case !inOld:
+				changes = append(changes, Change{Kind: k.Key, Name: name, Action: ActionAdded, Fields: fieldChanges(nil, n), New: n})
+			case !inNew:
+				changes = append(changes, Change{Kind: k.Key, Name: name, Action: ActionRemoved, Fields: fieldChanges(o, nil), Old: o})
+			default:
+				if fields := fieldChanges(o, n); len(fields) > 0 {
+					changes = append(changes, Change{Kind: k.Key, Name: name, Action: ActionChanged, Fields: fields, Old: o, New: n})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// byName indexes resources by their name.
+func byName(rs []Resource) map[string]Resource {
+	m := make(map[string]Resource, len(rs))
+	for _, r := range rs {
+		m[r.Name()] = r
+	}
+	return m
+}
+
+// fieldChanges returns the fields whose values differ between a and b.
+func fieldChanges(a, b Resource) []FieldChange {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	var fields []FieldChange
+	for _, k := range sortedKeys(keys) {
+		if !reflect.DeepEqual(a[k], b[k]) {
+			fields = append(fields, FieldChange{Field: k, Old: a[k], New: b[k]})
+		}
+	}
+	return fields
+}
+
+// WriteUnified renders changes in a unified diff style, one hunk per resource.
+func WriteUnified(w io.Writer, labelA, labelB string, changes []Change) {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", labelA, labelB)
+	for _, c := range changes {
+		if c.Immediate {
+			fmt.Fprintf(w, "@@ %s %q (%s, applies immediately) @@\n", c.Kind, c.Name, c.Action)
+		} else {
+			fmt.Fprintf(w, "@@ %s %q (%s) @@\n", c.Kind, c.Name, c.Action)
+		}
+		for _, f := range c.Fields {
+			oldStr, oldOK := f.Old.(string)
+			newStr, newOK := f.New.(string)
+			multiline := strings.Contains(oldStr, "\n") || strings.Contains(newStr, "\n")
+			if multiline && (oldOK || f.Old == nil) && (newOK || f.New == nil) {
+				fmt.Fprintf(w, "  %s:\n", f.Field)
+				for _, l := range lineDiff(oldStr, newStr) {
+					fmt.Fprintf(w, "%c   %s\n", l.op, l.text)
+				}
+				continue
+			}
+			if f.Old != nil {
+				fmt.Fprintf(w, "- %s = %s\n", f.Field, formatValue(f.Old))
+			}
+			if f.New != nil {
+				fmt.Fprintf(w, "+ %s = %s\n", f.Field, formatValue(f.New))
+			}
+		}
+	}
+}
+
+// formatValue renders a document value on a single line.
+func formatValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", t)
+	case []any:
+		items := make([]string, len(t))
+		for i, item := range t {
+			items[i] = formatValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		items := make([]string, 0, len(t))
+		for _, k := range sortedKeys(t) {
+			items = append(items, fmt.Sprintf("%s = %s", k, formatValue(t[k])))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// diffLine is a single line of a line-based diff.
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// lineDiff computes a line-based diff of two strings using the longest common
+// subsequence of their lines.
+func lineDiff(a, b string) []diffLine {
+	al := splitLines(a)
+	bl := splitLines(b)
+
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []diffLine
+	i, j := 0, 0
+	for i < len(al) && j < len(bl) {
+		switch {
+		case al[i] == bl[j]:
+			out = append(out, diffLine{' ', al[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{'-', al[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', bl[j]})
+			j++
+		}
+	}
+	for ; i < len(al); i++ {
+		out = append(out, diffLine{'-', al[i]})
+	}
+	for ; j < len(bl); j++ {
+		out = append(out, diffLine{'+', bl[j]})
+	}
+	return out
+}
+
+// splitLines splits s into lines, treating an empty string as no lines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
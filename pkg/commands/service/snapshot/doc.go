@@ -0,0 +1,4 @@
+// Package snapshot models the versioned configuration of a Fastly service
+// (backends, domains, VCL, logging endpoints etc) as a declarative document
+// that can be exported, imported and compared.
+package snapshot
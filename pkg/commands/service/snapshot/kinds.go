@@ -0,0 +1,753 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/api"
+)
+
+// Kind describes how to read and write one type of versioned resource.
+type Kind struct {
+	// Key is the document section holding resources of this kind.
+	Key string
+
+	list   func(ctx context.Context, serviceID string, version int) ([]Resource, error)
+	create func(ctx context.Context, serviceID string, version int, r Resource) error
+	update func(ctx context.Context, serviceID string, version int, name string, fields Resource) error
+	remove func(ctx context.Context, serviceID string, version int, name string) error
+	// unversioned reports whether a change updates data that isn't versioned,
+	// and so takes effect immediately (nil when every field is versioned).
+	unversioned func(c Change) bool
+	// unreadable returns the fields of an existing resource that the API
+	// doesn't return, and so can't be compared (nil when every field is read).
+	unreadable func(r Resource) []string
+}
+
+// List returns every resource of this kind on the given service version,
+// sorted by name.
+func (k Kind) List(ctx context.Context, serviceID string, version int) ([]Resource, error) {
+	rs, err := k.list(ctx, serviceID, version)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rs[i].Name() < rs[j].Name()
+	})
+	return rs, nil
+}
+
+// Create creates the resource on the given service version.
+func (k Kind) Create(ctx context.Context, serviceID string, version int, r Resource) error {
+	return k.create(ctx, serviceID, version, r)
+}
+
+// Update sets the given fields of the named resource on the given service
+// version. Fields that aren't present are left unchanged.
+func (k Kind) Update(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+	return k.update(ctx, serviceID, version, name, fields)
+}
+
+// Delete deletes the named resource from the given service version.
+func (k Kind) Delete(ctx context.Context, serviceID string, version int, name string) error {
+	return k.remove(ctx, serviceID, version, name)
+}
+
+// Immediate reports whether the change takes effect immediately on every
+// version of the service, including the active one, because it updates data
+// that isn't versioned (e.g. dictionary items). Resources that are added or
+// removed only exist on the edited version, so only updates apply immediately.
+func (k Kind) Immediate(c Change) bool {
+	return c.Action == ActionChanged && k.unversioned != nil && k.unversioned(c)
+}
+
+// Unreadable returns the fields of an existing resource that the API doesn't
+// return (e.g. the items of a write-only dictionary).
+func (k Kind) Unreadable(r Resource) []string {
+	if k.unreadable == nil {
+		return nil
+	}
+	return k.unreadable(r)
+}
+
+// changesField returns an unversioned func reporting whether a change updates
+// the given field.
+func changesField(field string) func(Change) bool {
+	return func(c Change) bool {
+		for _, f := range c.Fields {
+			if f.Field == field {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Kinds returns every supported resource kind, bound to the given API client.
+//
+// The order is significant: resources that are referenced by others (such as
+// conditions, health checks and dictionaries) come first, so creating
+// resources in this order satisfies their dependencies.
+func Kinds(c api.Interface) []Kind {
+	return []Kind{
+		versioned("conditions", c.ListConditions, c.CreateCondition, c.UpdateCondition, c.DeleteCondition),
+		versioned("healthchecks", c.ListHealthChecks, c.CreateHealthCheck, c.UpdateHealthCheck, c.DeleteHealthCheck),
+		versioned("backends", c.ListBackends, c.CreateBackend, c.UpdateBackend, c.DeleteBackend),
+		versioned("domains", c.ListDomains, c.CreateDomain, c.UpdateDomain, c.DeleteDomain),
+		dictionaries(c),
+		acls(c),
+		versioned("vcls", c.ListVCLs, c.CreateVCL, c.UpdateVCL, c.DeleteVCL),
+		snippets(c),
+		rateLimiters(c),
+		resourceLinks(c),
+		versioned("logging_azureblob", c.ListBlobStorages, c.CreateBlobStorage, c.UpdateBlobStorage, c.DeleteBlobStorage),
+		versioned("logging_bigquery", c.ListBigQueries, c.CreateBigQuery, c.UpdateBigQuery, c.DeleteBigQuery),
+		versioned("logging_cloudfiles", c.ListCloudfiles, c.CreateCloudfiles, c.UpdateCloudfiles, c.DeleteCloudfiles),
+		versioned("logging_datadog", c.ListDatadog, c.CreateDatadog, c.UpdateDatadog, c.DeleteDatadog),
+		versioned("logging_digitalocean", c.ListDigitalOceans, c.CreateDigitalOcean, c.UpdateDigitalOcean, c.DeleteDigitalOcean),
+		versioned("logging_elasticsearch", c.ListElasticsearch, c.CreateElasticsearch, c.UpdateElasticsearch, c.DeleteElasticsearch),
+		versioned("logging_ftp", c.ListFTPs, c.CreateFTP, c.UpdateFTP, c.DeleteFTP),
+		versioned("logging_gcs", c.ListGCSs, c.CreateGCS, c.UpdateGCS, c.DeleteGCS),
+		versioned("logging_googlepubsub", c.ListPubsubs, c.CreatePubsub, c.UpdatePubsub, c.DeletePubsub),
+		versioned("logging_grafanacloudlogs", c.ListGrafanaCloudLogs, c.CreateGrafanaCloudLogs, c.UpdateGrafanaCloudLogs, c.DeleteGrafanaCloudLogs),
+		versioned("logging_heroku", c.ListHerokus, c.CreateHeroku, c.UpdateHeroku, c.DeleteHeroku),
+		versioned("logging_honeycomb", c.ListHoneycombs, c.CreateHoneycomb, c.UpdateHoneycomb, c.DeleteHoneycomb),
+		versioned("logging_https", c.ListHTTPS, c.CreateHTTPS, c.UpdateHTTPS, c.DeleteHTTPS),
+		versioned("logging_kafka", c.ListKafkas, c.CreateKafka, c.UpdateKafka, c.DeleteKafka),
+		versioned("logging_kinesis", c.ListKinesis, c.CreateKinesis, c.UpdateKinesis, c.DeleteKinesis),
+		versioned("logging_loggly", c.ListLoggly, c.CreateLoggly, c.UpdateLoggly, c.DeleteLoggly),
+		versioned("logging_logshuttle", c.ListLogshuttles, c.CreateLogshuttle, c.UpdateLogshuttle, c.DeleteLogshuttle),
+		versioned("logging_newrelic", c.ListNewRelic, c.CreateNewRelic, c.UpdateNewRelic, c.DeleteNewRelic),
+		versioned("logging_newrelicotlp", c.ListNewRelicOTLP, c.CreateNewRelicOTLP, c.UpdateNewRelicOTLP, c.DeleteNewRelicOTLP),
+		versioned("logging_openstack", c.ListOpenstack, c.CreateOpenstack, c.UpdateOpenstack, c.DeleteOpenstack),
+		versioned("logging_papertrail", c.ListPapertrails, c.CreatePapertrail, c.UpdatePapertrail, c.DeletePapertrail),
+		versioned("logging_s3", c.ListS3s, c.CreateS3, c.UpdateS3, c.DeleteS3),
+		versioned("logging_scalyr", c.ListScalyrs, c.CreateScalyr, c.UpdateScalyr, c.DeleteScalyr),
+		versioned("logging_sftp", c.ListSFTPs, c.CreateSFTP, c.UpdateSFTP, c.DeleteSFTP),
+		versioned("logging_splunk", c.ListSplunks, c.CreateSplunk, c.UpdateSplunk, c.DeleteSplunk),
+		versioned("logging_sumologic", c.ListSumologics, c.CreateSumologic, c.UpdateSumologic, c.DeleteSumologic),
+		versioned("logging_syslog", c.ListSyslogs, c.CreateSyslog, c.UpdateSyslog, c.DeleteSyslog),
+	}
+}
+
+// versioned builds a Kind from the list, create, update and delete methods of
+// a resource whose API inputs are scoped by ServiceID and ServiceVersion
+// fields and whose update and delete inputs identify it by Name.
+func versioned[T, L, C, U, D any](
+	key string,
+	list func(context.Context, *L) ([]*T, error),
+	create func(context.Context, *C) (*T, error),
+	update func(context.Context, *U) (*T, error),
+	remove func(context.Context, *D) error,
+) Kind {
+	return Kind{
+		Key: key,
+		list: func(ctx context.Context, serviceID string, version int) ([]Resource, error) {
+			in := new(L)
+			scope(in, serviceID, version)
+			items, err := list(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			rs := make([]Resource, 0, len(items))
+			for _, item := range items {
+				rs = append(rs, toResource(item))
+			}
+			return rs, nil
+		},
+		create: func(ctx context.Context, serviceID string, version int, r Resource) error {
+			in := new(C)
+			if err := populate(r, in); err != nil {
+				return err
+			}
+			scope(in, serviceID, version)
+			_, err := create(ctx, in)
+			return err
+		},
+		update: func(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+			in := new(U)
+			if err := populate(fields, in); err != nil {
+				return err
+			}
+			scope(in, serviceID, version)
+			identify(in, name)
+			_, err := update(ctx, in)
+			return err
+		},
+		remove: func(ctx context.Context, serviceID string, version int, name string) error {
+			in := new(D)
+			scope(in, serviceID, version)
+			identify(in, name)
+			return remove(ctx, in)
+		},
+	}
+}
+
+// scope sets the ServiceID and ServiceVersion fields of an API input struct.
+func scope(in any, serviceID string, version int) {
+	v := reflect.ValueOf(in).Elem()
+	if f := v.FieldByName("ServiceID"); f.IsValid() && f.Kind() == reflect.String {
+		f.SetString(serviceID)
+	}
+	if f := v.FieldByName("ServiceVersion"); f.IsValid() && f.Kind() == reflect.Int {
+		f.SetInt(int64(version))
+	}
+}
+
+// identify sets the Name field of an API input struct, which is how most update
+// and delete inputs select the resource to operate on.
+func identify(in any, name string) {
+	f := reflect.ValueOf(in).Elem().FieldByName("Name")
+	switch {
+	case !f.IsValid():
+	case f.Kind() == reflect.String:
+		f.SetString(name)
+	case f.Kind() == reflect.Pointer && f.Type().Elem().Kind() == reflect.String:
+		f.Set(reflect.ValueOf(&name))
+	}
+}
+
+// dictionaries returns the Kind for edge dictionaries, including their items
+// (unless the dictionary is write-only, in which case the items are hidden).
+//
+// NOTE: dictionary items aren't versioned, so updating them takes effect
+// immediately on every version that uses the dictionary.
+func dictionaries(c api.Interface) Kind {
+	return Kind{
+		Key:         "dictionaries",
+		unversioned: changesField("items"),
+		unreadable: func(r Resource) []string {
+			if r["write_only"] == true {
+				return []string{"items"}
+			}
+			return nil
+		},
+		list: func(ctx context.Context, serviceID string, version int) ([]Resource, error) {
+			ds, err := c.ListDictionaries(ctx, &fastly.ListDictionariesInput{
+				ServiceID:      serviceID,
+				ServiceVersion: version,
+			})
+			if err != nil {
+				return nil, err
+			}
+			rs := make([]Resource, 0, len(ds))
+			for _, d := range ds {
+				r := toResource(d)
+				if !fastly.ToValue(d.WriteOnly) {
+					items, err := dictionaryItems(ctx, c, serviceID, fastly.ToValue(d.DictionaryID))
+					if err != nil {
+						return nil, fmt.Errorf("error listing items of dictionary %s: %w", fastly.ToValue(d.Name), err)
+					}
+					list := make([]any, 0, len(items))
+					for _, k := range sortedKeys(items) {
+						list = append(list, map[string]any{
+							"item_key":   k,
+							"item_value": items[k],
+						})
+					}
+					r["items"] = list
+				}
+				rs = append(rs, r)
+			}
+			return rs, nil
+		},
+		create: func(ctx context.Context, serviceID string, version int, r Resource) error {
+			in := &fastly.CreateDictionaryInput{}
+			if err := populate(r, in); err != nil {
+				return err
+			}
+			scope(in, serviceID, version)
+			d, err := c.CreateDictionary(ctx, in)
+			if err != nil {
+				return err
+			}
+			for _, item := range children(r, "items") {
+				itemIn := &fastly.CreateDictionaryItemInput{}
+				if err := populate(item, itemIn); err != nil {
+					return err
+				}
+				itemIn.ServiceID = serviceID
+				itemIn.DictionaryID = fastly.ToValue(d.DictionaryID)
+				if _, err := c.CreateDictionaryItem(ctx, itemIn); err != nil {
+					return fmt.Errorf("error creating item %v: %w", item["item_key"], err)
+				}
+			}
+			return nil
+		},
+		update: func(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+			if attrs := without(fields, "items"); len(attrs) > 0 {
+				in := &fastly.UpdateDictionaryInput{}
+				if err := populate(attrs, in); err != nil {
+					return err
+				}
+				scope(in, serviceID, version)
+				identify(in, name)
+				if _, err := c.UpdateDictionary(ctx, in); err != nil {
+					return err
+				}
+			}
+			if _, ok := fields["items"]; !ok {
+				return nil
+			}
+			in := &fastly.GetDictionaryInput{}
+			scope(in, serviceID, version)
+			identify(in, name)
+			d, err := c.GetDictionary(ctx, in)
+			if err != nil {
+				return err
+			}
+			return syncDictionaryItems(ctx, c, serviceID, fastly.ToValue(d.DictionaryID), children(fields, "items"))
+		},
+		remove: func(ctx context.Context, serviceID string, version int, name string) error {
+			in := &fastly.DeleteDictionaryInput{}
+			scope(in, serviceID, version)
+			identify(in, name)
+			return c.DeleteDictionary(ctx, in)
+		},
+	}
+}
+
+// dictionaryItems returns the items of a dictionary keyed by item key.
+func dictionaryItems(ctx context.Context, c api.Interface, serviceID, dictionaryID string) (map[string]string, error) {
+	paginator := c.GetDictionaryItems(ctx, &fastly.GetDictionaryItemsInput{
+		ServiceID:    serviceID,
+		DictionaryID: dictionaryID,
+	})
+	items := map[string]string{}
+	for paginator.HasNext() {
+		data, err := paginator.GetNext()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range data {
+			items[fastly.ToValue(item.ItemKey)] = fastly.ToValue(item.ItemValue)
+		}
+	}
+	return items, nil
+}
+
+// syncDictionaryItems creates, updates and deletes dictionary items so that
+// the dictionary holds exactly the desired items.
+func syncDictionaryItems(ctx context.Context, c api.Interface, serviceID, dictionaryID string, desired []map[string]any) error {
+	current, err := dictionaryItems(ctx, c, serviceID, dictionaryID)
+	if err != nil {
+		return fmt.Errorf("error listing items: %w", err)
+	}
+	for _, item := range desired {
+		key := fmt.Sprint(item["item_key"])
+		value, ok := current[key]
+		delete(current, key)
+		switch {
+		case !ok:
+			in := &fastly.CreateDictionaryItemInput{}
+			if err := populate(item, in); err != nil {
+				return err
+			}
+			in.ServiceID = serviceID
+			in.DictionaryID = dictionaryID
+			if _, err := c.CreateDictionaryItem(ctx, in); err != nil {
+				return fmt.Errorf("error creating item %s: %w", key, err)
+			}
+		case value != fmt.Sprint(item["item_value"]):
+			in := &fastly.UpdateDictionaryItemInput{}
+			if err := populate(item, in); err != nil {
+				return err
+			}
+			in.ServiceID = serviceID
+			in.DictionaryID = dictionaryID
+			if _, err := c.UpdateDictionaryItem(ctx, in); err != nil {
+				return fmt.Errorf("error updating item %s: %w", key, err)
+			}
+		}
+	}
+	for _, key := range sortedKeys(current) {
+		err := c.DeleteDictionaryItem(ctx, &fastly.DeleteDictionaryItemInput{
+			ServiceID:    serviceID,
+			DictionaryID: dictionaryID,
+			ItemKey:      key,
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting item %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// acls returns the Kind for ACLs, including their entries.
+//
+// NOTE: ACL entries aren't versioned, so updating them takes effect
+// immediately on every version that uses the ACL.
+func acls(c api.Interface) Kind {
+	return Kind{
+		Key:         "acls",
+		unversioned: changesField("entries"),
+		list: func(ctx context.Context, serviceID string, version int) ([]Resource, error) {
+			as, err := c.ListACLs(ctx, &fastly.ListACLsInput{
+				ServiceID:      serviceID,
+				ServiceVersion: version,
+			})
+			if err != nil {
+				return nil, err
+			}
+			rs := make([]Resource, 0, len(as))
+			for _, a := range as {
+				r := toResource(a)
+				data, err := aclEntries(ctx, c, serviceID, fastly.ToValue(a.ACLID))
+				if err != nil {
+					return nil, fmt.Errorf("error listing entries of ACL %s: %w", fastly.ToValue(a.Name), err)
+				}
+				entries := make([]any, 0, len(data))
+				for _, entry := range data {
+					entries = append(entries, map[string]any(toResource(entry)))
+				}
+				r["entries"] = entries
+				rs = append(rs, r)
+			}
+			return rs, nil
+		},
+		create: func(ctx context.Context, serviceID string, version int, r Resource) error {
+			in := &fastly.CreateACLInput{}
+			if err := populate(r, in); err != nil {
+				return err
+			}
+			scope(in, serviceID, version)
+			a, err := c.CreateACL(ctx, in)
+			if err != nil {
+				return err
+			}
+			for _, entry := range children(r, "entries") {
+				entryIn := &fastly.CreateACLEntryInput{}
+				if err := populate(entry, entryIn); err != nil {
+					return err
+				}
+				entryIn.ServiceID = serviceID
+				entryIn.ACLID = fastly.ToValue(a.ACLID)
+				if _, err := c.CreateACLEntry(ctx, entryIn); err != nil {
+					return fmt.Errorf("error creating entry %v: %w", entry["ip"], err)
+				}
+			}
+			return nil
+		},
+		update: func(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+			if attrs := without(fields, "entries"); len(attrs) > 0 {
+				in := &fastly.UpdateACLInput{}
+				if err := populate(attrs, in); err != nil {
+					return err
+				}
+				scope(in, serviceID, version)
+				identify(in, name)
+				if _, err := c.UpdateACL(ctx, in); err != nil {
+					return err
+				}
+			}
+			if _, ok := fields["entries"]; !ok {
+				return nil
+			}
+			in := &fastly.GetACLInput{}
+			scope(in, serviceID, version)
+			identify(in, name)
+			a, err := c.GetACL(ctx, in)
+			if err != nil {
+				return err
+			}
+			return syncACLEntries(ctx, c, serviceID, fastly.ToValue(a.ACLID), children(fields, "entries"))
+		},
+		remove: func(ctx context.Context, serviceID string, version int, name string) error {
+			in := &fastly.DeleteACLInput{}
+			scope(in, serviceID, version)
+			identify(in, name)
+			return c.DeleteACL(ctx, in)
+		},
+	}
+}
+
+// aclEntries returns every entry of an ACL.
+func aclEntries(ctx context.Context, c api.Interface, serviceID, aclID string) ([]*fastly.ACLEntry, error) {
+	paginator := c.GetACLEntries(ctx, &fastly.GetACLEntriesInput{
+		ServiceID: serviceID,
+		ACLID:     aclID,
+	})
+	var entries []*fastly.ACLEntry
+	for paginator.HasNext() {
+		data, err := paginator.GetNext()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, data...)
+	}
+	return entries, nil
+}
+
+// syncACLEntries creates, updates and deletes ACL entries so that the ACL
+// holds exactly the desired entries. Entries are matched by IP and subnet.
+func syncACLEntries(ctx context.Context, c api.Interface, serviceID, aclID string, desired []map[string]any) error {
+	entries, err := aclEntries(ctx, c, serviceID, aclID)
+	if err != nil {
+		return fmt.Errorf("error listing entries: %w", err)
+	}
+	current := make(map[string]*fastly.ACLEntry, len(entries))
+	for _, entry := range entries {
+		current[aclEntryKey(toResource(entry))] = entry
+	}
+	for _, entry := range desired {
+		key := aclEntryKey(entry)
+		existing, ok := current[key]
+		delete(current, key)
+		switch {
+		case !ok:
+			in := &fastly.CreateACLEntryInput{}
+			if err := populate(entry, in); err != nil {
+				return err
+			}
+			in.ServiceID = serviceID
+			in.ACLID = aclID
+			if _, err := c.CreateACLEntry(ctx, in); err != nil {
+				return fmt.Errorf("error creating entry %s: %w", key, err)
+			}
+		case len(fieldChanges(subset(toResource(existing), entry), entry)) > 0:
+			in := &fastly.UpdateACLEntryInput{}
+			if err := populate(entry, in); err != nil {
+				return err
+			}
+			in.ServiceID = serviceID
+			in.ACLID = aclID
+			in.EntryID = fastly.ToValue(existing.EntryID)
+			if _, err := c.UpdateACLEntry(ctx, in); err != nil {
+				return fmt.Errorf("error updating entry %s: %w", key, err)
+			}
+		}
+	}
+	for _, key := range sortedKeys(current) {
+		err := c.DeleteACLEntry(ctx, &fastly.DeleteACLEntryInput{
+			ServiceID: serviceID,
+			ACLID:     aclID,
+			EntryID:   fastly.ToValue(current[key].EntryID),
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting entry %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// aclEntryKey identifies an ACL entry by its IP and subnet.
+func aclEntryKey(entry map[string]any) string {
+	if subnet, ok := entry["subnet"]; ok {
+		return fmt.Sprintf("%v/%v", entry["ip"], subnet)
+	}
+	return fmt.Sprint(entry["ip"])
+}
+
+// snippets returns the Kind for VCL snippets. The content of dynamic snippets
+// isn't versioned, so it's fetched separately and recorded alongside the
+// snippet so it can be used as the initial content when recreated.
+//
+// NOTE: updating the content of a dynamic snippet takes effect immediately.
+func snippets(c api.Interface) Kind {
+	return Kind{
+		Key: "snippets",
+		unversioned: func(c Change) bool {
+			return fmt.Sprint(c.Old["dynamic"]) == "1" && changesField("content")(c)
+		},
+		list: func(ctx context.Context, serviceID string, version int) ([]Resource, error) {
+			ss, err := c.ListSnippets(ctx, &fastly.ListSnippetsInput{
+				ServiceID:      serviceID,
+				ServiceVersion: version,
+			})
+			if err != nil {
+				return nil, err
+			}
+			rs := make([]Resource, 0, len(ss))
+			for _, s := range ss {
+				r := toResource(s)
+				if fastly.ToValue(s.Dynamic) == 1 {
+					ds, err := c.GetDynamicSnippet(ctx, &fastly.GetDynamicSnippetInput{
+						ServiceID: serviceID,
+						SnippetID: fastly.ToValue(s.SnippetID),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("error getting content of dynamic snippet %s: %w", fastly.ToValue(s.Name), err)
+					}
+					r["content"] = fastly.ToValue(ds.Content)
+				}
+				rs = append(rs, r)
+			}
+			return rs, nil
+		},
+		create: func(ctx context.Context, serviceID string, version int, r Resource) error {
+			in := &fastly.CreateSnippetInput{}
+			if err := populate(r, in); err != nil {
+				return err
+			}
+			scope(in, serviceID, version)
+			_, err := c.CreateSnippet(ctx, in)
+			return err
+		},
+		update: func(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+			get := &fastly.GetSnippetInput{}
+			scope(get, serviceID, version)
+			identify(get, name)
+			s, err := c.GetSnippet(ctx, get)
+			if err != nil {
+				return err
+			}
+			attrs := fields
+			if content, ok := fields["content"]; ok && fastly.ToValue(s.Dynamic) == 1 {
+				_, err := c.UpdateDynamicSnippet(ctx, &fastly.UpdateDynamicSnippetInput{
+					ServiceID: serviceID,
+					SnippetID: fastly.ToValue(s.SnippetID),
+					Content:   fastly.ToPointer(fmt.Sprint(content)),
+				})
+				if err != nil {
+					return fmt.Errorf("error updating content of dynamic snippet: %w", err)
+				}
+				attrs = without(fields, "content")
+			}
+			if len(attrs) == 0 {
+				return nil
+			}
+			in := &fastly.UpdateSnippetInput{}
+			if err := populate(attrs, in); err != nil {
+				return err
+			}
+			scope(in, serviceID, version)
+			identify(in, name)
+			_, err = c.UpdateSnippet(ctx, in)
+			return err
+		},
+		remove: func(ctx context.Context, serviceID string, version int, name string) error {
+			in := &fastly.DeleteSnippetInput{}
+			scope(in, serviceID, version)
+			identify(in, name)
+			return c.DeleteSnippet(ctx, in)
+		},
+	}
+}
+
+// rateLimiters returns the Kind for Edge Rate Limiters, which are updated and
+// deleted by ID rather than by name.
+func rateLimiters(c api.Interface) Kind {
+	k := versioned("rate_limiters", c.ListERLs, c.CreateERL, c.UpdateERL, c.DeleteERL)
+	lookup := func(ctx context.Context, serviceID string, version int, name string) (string, error) {
+		erls, err := c.ListERLs(ctx, &fastly.ListERLsInput{
+			ServiceID:      serviceID,
+			ServiceVersion: version,
+		})
+		if err != nil {
+			return "", err
+		}
+		for _, e := range erls {
+			if fastly.ToValue(e.Name) == name {
+				return fastly.ToValue(e.RateLimiterID), nil
+			}
+		}
+		return "", fmt.Errorf("rate limiter %q not found", name)
+	}
+	k.update = func(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+		id, err := lookup(ctx, serviceID, version, name)
+		if err != nil {
+			return err
+		}
+		in := &fastly.UpdateERLInput{}
+		if err := populate(fields, in); err != nil {
+			return err
+		}
+		in.ERLID = id
+		_, err = c.UpdateERL(ctx, in)
+		return err
+	}
+	k.remove = func(ctx context.Context, serviceID string, version int, name string) error {
+		id, err := lookup(ctx, serviceID, version, name)
+		if err != nil {
+			return err
+		}
+		return c.DeleteERL(ctx, &fastly.DeleteERLInput{ERLID: id})
+	}
+	return k
+}
+
+// resourceLinks returns the Kind for resource links, which are updated and
+// deleted by their link ID rather than by name.
+func resourceLinks(c api.Interface) Kind {
+	k := versioned("resource_links", c.ListResources, c.CreateResource, c.UpdateResource, c.DeleteResource)
+	lookup := func(ctx context.Context, serviceID string, version int, name string) (string, error) {
+		links, err := c.ListResources(ctx, &fastly.ListResourcesInput{
+			ServiceID:      serviceID,
+			ServiceVersion: version,
+		})
+		if err != nil {
+			return "", err
+		}
+		for _, l := range links {
+			if fastly.ToValue(l.Name) == name {
+				return fastly.ToValue(l.LinkID), nil
+			}
+		}
+		return "", fmt.Errorf("resource link %q not found", name)
+	}
+	k.update = func(ctx context.Context, serviceID string, version int, name string, fields Resource) error {
+		id, err := lookup(ctx, serviceID, version, name)
+		if err != nil {
+			return err
+		}
+		in := &fastly.UpdateResourceInput{}
+		if err := populate(without(fields, "resource_id"), in); err != nil {
+			return err
+		}
+		scope(in, serviceID, version)
+		in.ResourceID = id
+		_, err = c.UpdateResource(ctx, in)
+		return err
+	}
+	k.remove = func(ctx context.Context, serviceID string, version int, name string) error {
+		id, err := lookup(ctx, serviceID, version, name)
+		if err != nil {
+			return err
+		}
+		return c.DeleteResource(ctx, &fastly.DeleteResourceInput{
+			ResourceID:     id,
+			ServiceID:      serviceID,
+			ServiceVersion: version,
+		})
+	}
+	return k
+}
+
+// children returns the nested tables stored under key (e.g. dictionary items).
+func children(r Resource, key string) []map[string]any {
+	items, _ := r[key].([]any)
+	out := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// without returns a copy of r without the given keys.
+func without(r Resource, keys ...string) Resource {
+	out := make(Resource, len(r))
+	for k, v := range r {
+		out[k] = v
+	}
+	for _, k := range keys {
+		delete(out, k)
+	}
+	return out
+}
+
+// subset returns the fields of r that are also present in other.
+func subset(r, other Resource) Resource {
+	out := make(Resource, len(other))
+	for k := range other {
+		if v, ok := r[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/fastly/cli/pkg/undo"
+)
+
+// Plan compares the current configuration of a service version with the
+// desired configuration and returns the changes required to reconcile them.
+//
+// Unlike Diff, fields that are absent from a desired resource are treated as
+// unmanaged and never produce a change, so a desired-state document only needs
+// to declare the fields it cares about. Fields that can't be read from the
+// current resource (e.g. the items of a write-only dictionary) are skipped too,
+// as they'd otherwise be rewritten on every apply.
+//
+// Changes to data that isn't versioned are marked as Immediate.
+func Plan(kinds []Kind, current, desired *Document) []Change {
+	byKey := make(map[string]Kind, len(kinds))
+	for _, k := range kinds {
+		byKey[k.Key] = k
+	}
+
+	var changes []Change
+	for _, c := range Diff(kinds, current, desired) {
+		if c.Action == ActionChanged {
+			unreadable := byKey[c.Kind].Unreadable(c.Old)
+			var fields []FieldChange
+			for _, f := range c.Fields {
+				if f.New != nil && !slices.Contains(unreadable, f.Field) {
+					fields = append(fields, f)
+				}
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			c.Fields = fields
+		}
+		c.Immediate = byKey[c.Kind].Immediate(c)
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// Summary is a count of planned changes by action.
+type Summary struct {
+	Add    int `json:"add"`
+	Change int `json:"change"`
+	Delete int `json:"delete"`
+	// Immediate is the number of changes that take effect immediately.
+	Immediate int `json:"immediate"`
+}
+
+// Summarize counts the changes by action.
+func Summarize(changes []Change) Summary {
+	var s Summary
+	for _, c := range changes {
+		switch c.Action {
+		case ActionAdded:
+			s.Add++
+		case ActionChanged:
+			s.Change++
+		case ActionRemoved:
+			s.Delete++
+		}
+		if c.Immediate {
+			s.Immediate++
+		}
+	}
+	return s
+}
+
+// String renders the summary as a single sentence.
+func (s Summary) String() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to delete", s.Add, s.Change, s.Delete)
+}
+
+// Apply makes the planned changes on a service version.
+//
+// Resources are created and updated in kind order, then deleted in reverse
+// kind order, so that a resource is never created before, or deleted after,
+// the resources it references. For every change that's made, a function that
+// reverts it is pushed onto stack so a failed run can be rolled back.
+//
+// The optional progress function is called after each change is made.
+func Apply(ctx context.Context, kinds []Kind, serviceID string, version int, changes []Change, stack *undo.Stack, progress func(Change)) error {
+	apply := func(k Kind, c Change) error {
+		if err := applyChange(ctx, k, serviceID, version, c, stack); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(c)
+		}
+		return nil
+	}
+
+	for _, k := range kinds {
+		for _, c := range changes {
+			if c.Kind == k.Key && c.Action != ActionRemoved {
+				if err := apply(k, c); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for i := len(kinds) - 1; i >= 0; i-- {
+		for _, c := range changes {
+			if c.Kind == kinds[i].Key && c.Action == ActionRemoved {
+				if err := apply(kinds[i], c); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyChange makes a single change and pushes its inverse onto stack.
+func applyChange(ctx context.Context, k Kind, serviceID string, version int, c Change, stack *undo.Stack) error {
+	switch c.Action {
+	case ActionAdded:
+		if err := k.Create(ctx, serviceID, version, c.New); err != nil {
+			return fmt.Errorf("error creating %s %q: %w", c.Kind, c.Name, err)
+		}
+		stack.Push(func() error {
+			if err := k.Delete(ctx, serviceID, version, c.Name); err != nil {
+				return fmt.Errorf("error reverting creation of %s %q: %w", c.Kind, c.Name, err)
+			}
+			return nil
+		})
+	case ActionChanged:
+		desired, previous := Resource{}, Resource{}
+		for _, f := range c.Fields {
+			desired[f.Field] = f.New
+			if f.Old != nil {
+				previous[f.Field] = f.Old
+			}
+		}
+		if err := k.Update(ctx, serviceID, version, c.Name, desired); err != nil {
+			return fmt.Errorf("error updating %s %q: %w", c.Kind, c.Name, err)
+		}
+		stack.Push(func() error {
+			if len(previous) == 0 {
+				return nil
+			}
+			if err := k.Update(ctx, serviceID, version, c.Name, previous); err != nil {
+				return fmt.Errorf("error reverting update of %s %q: %w", c.Kind, c.Name, err)
+			}
+			return nil
+		})
+	case ActionRemoved:
+		if err := k.Delete(ctx, serviceID, version, c.Name); err != nil {
+			return fmt.Errorf("error deleting %s %q: %w", c.Kind, c.Name, err)
+		}
+		stack.Push(func() error {
+			if err := k.Create(ctx, serviceID, version, c.Old); err != nil {
+				return fmt.Errorf("error reverting deletion of %s %q: %w", c.Kind, c.Name, err)
+			}
+			return nil
+		})
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Format is the serialisation format of a Document.
+type Format string
+
+const (
+	// FormatJSON encodes a Document as JSON.
+	FormatJSON Format = "json"
+	// FormatTOML encodes a Document as TOML.
+	FormatTOML Format = "toml"
+)
+
+// Formats is the list of supported document formats.
+var Formats = []string{string(FormatTOML), string(FormatJSON)}
+
+// FormatFromPath returns the document format implied by a file extension,
+// defaulting to TOML.
+func FormatFromPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatTOML
+}
+
+// Resource is the configuration of a single service resource, keyed by the
+// snake_case name of each API field.
+type Resource map[string]any
+
+// Name returns the name that identifies the resource within its kind.
+func (r Resource) Name() string {
+	if s, ok := r["name"].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// Service describes the service version a Document was captured from.
+type Service struct {
+	ID      string
+	Name    string
+	Type    string
+	Version int
+}
+
+// Document is the declarative representation of a service version.
+type Document struct {
+	Service Service
+	// Resources maps a Kind key (e.g. "backends") to the resources of that kind.
+	Resources map[string][]Resource
+}
+
+// Count returns the total number of resources in the document.
+func (d *Document) Count() int {
+	var n int
+	for _, rs := range d.Resources {
+		n += len(rs)
+	}
+	return n
+}
+
+// Capture reads every versioned resource of the given service version.
+func Capture(ctx context.Context, kinds []Kind, serviceID string, version int) (*Document, error) {
+	doc := &Document{
+		Service:   Service{ID: serviceID, Version: version},
+		Resources: map[string][]Resource{},
+	}
+	for _, k := range kinds {
+		rs, err := k.List(ctx, serviceID, version)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s: %w", k.Key, err)
+		}
+		if len(rs) > 0 {
+			doc.Resources[k.Key] = rs
+		}
+	}
+	return doc, nil
+}
+
+// Encode serialises the document in the given format.
+func (d *Document) Encode(format Format) ([]byte, error) {
+	m := map[string]any{
+		"service": map[string]any{
+			"id":      d.Service.ID,
+			"name":    d.Service.Name,
+			"type":    d.Service.Type,
+			"version": int64(d.Service.Version),
+		},
+	}
+	for key, rs := range d.Resources {
+		items := make([]any, len(rs))
+		for i, r := range rs {
+			items[i] = map[string]any(r)
+		}
+		m[key] = items
+	}
+
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(m, "", "  ")
+	case FormatTOML:
+		tree, err := toml.TreeFromMap(m)
+		if err != nil {
+			return nil, err
+		}
+		s, err := tree.ToTomlString()
+		return []byte(s), err
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// Decode parses a document previously produced by Encode.
+//
+// Sections that don't match one of the given kinds are rejected so that typos
+// don't silently result in resources being skipped.
+func Decode(data []byte, format Format, kinds []Kind) (*Document, error) {
+	var m map[string]any
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		m = tree.ToMap()
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	normalize(m)
+
+	doc := &Document{Resources: map[string][]Resource{}}
+	if s, ok := m["service"].(map[string]any); ok {
+		doc.Service.ID, _ = s["id"].(string)
+		doc.Service.Name, _ = s["name"].(string)
+		doc.Service.Type, _ = s["type"].(string)
+		if v, ok := s["version"].(int64); ok {
+			doc.Service.Version = int(v)
+		}
+	}
+	delete(m, "service")
+
+	known := map[string]bool{}
+	for _, k := range kinds {
+		known[k.Key] = true
+	}
+	for _, key := range sortedKeys(m) {
+		if !known[key] {
+			return nil, fmt.Errorf("unrecognised resource type %q", key)
+		}
+		items, ok := m[key].([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected %q to be a list of tables", key)
+		}
+		for i, item := range items {
+			r, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("expected %s[%d] to be a table", key, i)
+			}
+			if Resource(r).Name() == "" {
+				return nil, fmt.Errorf("%s[%d] has no name", key, i)
+			}
+			doc.Resources[key] = append(doc.Resources[key], r)
+		}
+	}
+	return doc, nil
+}
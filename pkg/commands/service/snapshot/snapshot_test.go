@@ -0,0 +1,114 @@
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/commands/service/snapshot"
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/cli/pkg/testutil"
+)
+
+func TestFieldKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"Name":            "name",
+		"ServiceID":       "service_id",
+		"ACLID":           "acl_id",
+		"SSLCertHostname": "ssl_cert_hostname",
+		"HTTPMethods":     "http_methods",
+		"RpsLimit":        "rps_limit",
+		"IP":              "ip",
+	} {
+		if got := snapshot.FieldKey(name); got != want {
+			t.Errorf("FieldKey(%q): want %q, have %q", name, want, got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var created *fastly.CreateBackendInput
+	api := testutil.EmptyServiceResources(&mock.API{
+		ListBackendsFn: func(_ context.Context, i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+			return []*fastly.Backend{
+				{
+					Address:        fastly.ToPointer("example.com"),
+					Name:           fastly.ToPointer("origin"),
+					Port:           fastly.ToPointer(443),
+					ServiceID:      fastly.ToPointer(i.ServiceID),
+					ServiceVersion: fastly.ToPointer(i.ServiceVersion),
+				},
+			}, nil
+		},
+		CreateBackendFn: func(_ context.Context, i *fastly.CreateBackendInput) (*fastly.Backend, error) {
+			created = i
+			return &fastly.Backend{}, nil
+		},
+	})
+	kinds := snapshot.Kinds(api)
+
+	doc, err := snapshot.Capture(context.Background(), kinds, "123", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertEqual(t, 1, doc.Count())
+	if _, ok := doc.Resources["backends"][0]["service_id"]; ok {
+		t.Fatal("expected service_id to be omitted from the captured backend")
+	}
+
+	for _, format := range []snapshot.Format{snapshot.FormatTOML, snapshot.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := doc.Encode(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := snapshot.Decode(data, format, kinds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testutil.AssertEqual(t, doc.Service, decoded.Service)
+			testutil.AssertEqual(t, doc.Resources, decoded.Resources)
+
+			for _, k := range kinds {
+				for _, r := range decoded.Resources[k.Key] {
+					if err := k.Create(context.Background(), "456", 2, r); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			testutil.AssertString(t, "456", created.ServiceID)
+			testutil.AssertEqual(t, 2, created.ServiceVersion)
+			testutil.AssertString(t, "origin", fastly.ToValue(created.Name))
+			testutil.AssertString(t, "example.com", fastly.ToValue(created.Address))
+			testutil.AssertEqual(t, 443, fastly.ToValue(created.Port))
+		})
+	}
+}
+
+func TestPlanWriteOnlyDictionary(t *testing.T) {
+	kinds := snapshot.Kinds(&mock.API{})
+	items := []any{map[string]any{"item_key": "token", "item_value": "secret"}}
+	current := &snapshot.Document{Resources: map[string][]snapshot.Resource{
+		"dictionaries": {
+			{"name": "public", "write_only": false, "items": []any{}},
+			{"name": "secrets", "write_only": true},
+		},
+	}}
+	desired := &snapshot.Document{Resources: map[string][]snapshot.Resource{
+		"dictionaries": {
+			{"name": "public", "items": items},
+			{"name": "secrets", "write_only": true, "items": items},
+		},
+	}}
+
+	changes := snapshot.Plan(kinds, current, desired)
+	if len(changes) != 1 || changes[0].Name != "public" {
+		t.Fatalf("expected only the readable dictionary to change, got %+v", changes)
+	}
+}
+
+func TestDecodeUnknownKind(t *testing.T) {
+	_, err := snapshot.Decode([]byte("[[widgets]]\nname = \"foo\"\n"), snapshot.FormatTOML, snapshot.Kinds(&mock.API{}))
+	testutil.AssertErrorContains(t, err, `unrecognised resource type "widgets"`)
+}
@@ -2,13 +2,16 @@ package version
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/fastly/go-fastly/v17/fastly"
 
 	"4d63.com/optional"
 
 	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/stats"
 	"github.com/fastly/cli/pkg/errors"
 	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/text"
@@ -21,6 +24,11 @@ type ActivateCommand struct {
 	serviceName    argparser.OptionalServiceNameID
 	serviceVersion argparser.OptionalServiceVersion
 	autoClone      argparser.OptionalAutoClone
+
+	canary       canary
+	canaryEnable bool
+	// canaryFlags records whether a flag that requires --canary was set.
+	canaryFlags argparser.Optional
 }
 
 // NewActivateCommand returns a usable command registered under the parent.
@@ -50,11 +58,24 @@ func NewActivateCommand(parent argparser.Registerer, g *global.Data) *ActivateCo
 		Action: c.autoClone.Set,
 		Dst:    &c.autoClone.Value,
 	})
+	c.CmdClause.Flag("canary", "Watch realtime stats after activating, and reactivate the previously active version if a threshold is breached").BoolVar(&c.canaryEnable)
+	c.CmdClause.Flag("alert", "Additional stats expression that triggers a rollback during the bake window, e.g. 'status_4xx/requests > 0.1' (repeatable, requires --canary)").Action(c.canaryFlags.Set).StringsVar(&c.canary.alerts)
+	c.CmdClause.Flag("bake", "How long to watch realtime stats before considering the activation successful (requires --canary)").Action(c.canaryFlags.Set).Default("5m").DurationVar(&c.canary.bake)
+	c.CmdClause.Flag("breach-for", "How long a threshold must be breached before rolling back (requires --canary)").Action(c.canaryFlags.Set).Default("30s").DurationVar(&c.canary.breachFor)
+	c.CmdClause.Flag("max-error-ratio", "Highest acceptable ratio of 5xx responses to requests (requires --canary)").Action(c.canaryFlags.Set).Default("0.02").Float64Var(&c.canary.maxErrorRatio)
+	c.CmdClause.Flag("max-origin-latency", "Highest acceptable average origin latency on cache misses, e.g. 500ms (requires --canary)").Action(c.canaryFlags.Set).DurationVar(&c.canary.maxOriginLatency)
 	return &c
 }
 
 // Exec invokes the application logic for the command.
 func (c *ActivateCommand) Exec(_ io.Reader, out io.Writer) error {
+	if c.canaryFlags.WasSet && !c.canaryEnable {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("invalid flag combination, --alert, --bake, --breach-for, --max-error-ratio and --max-origin-latency require --canary"),
+			Remediation: "Add --canary to watch the service and roll back if a threshold is breached, or remove the canary flags.",
+		}
+	}
+
 	serviceID, serviceVersion, err := argparser.ServiceDetails(argparser.ServiceDetailsOpts{
 		Active:             optional.Of(false),
 		AutoCloneFlag:      c.autoClone,
@@ -76,6 +97,31 @@ func (c *ActivateCommand) Exec(_ io.Reader, out io.Writer) error {
 	c.Input.ServiceID = serviceID
 	c.Input.ServiceVersion = fastly.ToValue(serviceVersion.Number)
 
+	var (
+		alerts   []*stats.Alert
+		previous int
+	)
+	if c.canaryEnable {
+		alerts, err = c.canary.thresholds()
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		previous, err = c.activeVersion(serviceID)
+		if err != nil {
+			return err
+		}
+		if err := checkAlerts(c.Globals.RTSClient, serviceID, alerts); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]any{
+				"Service ID": serviceID,
+			})
+			return errors.RemediationError{
+				Inner:       err,
+				Remediation: "Correct the --alert expressions (see `fastly stats realtime --json` for the available fields) before activating the version with --canary.",
+			}
+		}
+	}
+
 	ver, err := c.Globals.APIClient.ActivateVersion(context.TODO(), &c.Input)
 	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]any{
@@ -86,5 +132,93 @@ func (c *ActivateCommand) Exec(_ io.Reader, out io.Writer) error {
 	}
 
 	text.Success(out, "Activated service %s version %d", fastly.ToValue(ver.ServiceID), c.Input.ServiceVersion)
+
+	if !c.canaryEnable {
+		return nil
+	}
+	return c.bake(serviceID, previous, alerts, out)
+}
+
+// activeVersion returns the version to fall back to if the canary fails.
+func (c *ActivateCommand) activeVersion(serviceID string) (int, error) {
+	details, err := c.Globals.APIClient.GetServiceDetails(context.TODO(), &fastly.GetServiceDetailsInput{
+		ServiceID: serviceID,
+		Filters: []fastly.ServiceDetailsFilter{
+			{Key: "versions.active", Value: true},
+		},
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID": serviceID,
+		})
+		return 0, fmt.Errorf("error getting service details: %w", err)
+	}
+	if details.ActiveVersion == nil {
+		return 0, errors.RemediationError{
+			Inner:       fmt.Errorf("service %s has no active version to roll back to", serviceID),
+			Remediation: "Activate the version without --canary.",
+		}
+	}
+	previous := fastly.ToValue(details.ActiveVersion.Number)
+	if previous == c.Input.ServiceVersion {
+		return 0, errors.RemediationError{
+			Inner:       fmt.Errorf("service version %d is already active", previous),
+			Remediation: "Pass the --version to activate as a canary (or use --autoclone).",
+		}
+	}
+	return previous, nil
+}
+
+// bake watches the service during the bake window, and reactivates the
+// previous version if a threshold is breached or the canary couldn't be
+// watched.
+func (c *ActivateCommand) bake(serviceID string, previous int, alerts []*stats.Alert, out io.Writer) error {
+	text.Info(out, "Watching realtime stats for %s. Service version %d will be reactivated if a threshold is breached.", c.canary.bake, previous)
+	text.Break(out)
+
+	breach, err := bake(c.Globals.RTSClient, serviceID, alerts, time.Now().Add(c.canary.bake), c.Globals.Verbose(), out)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": c.Input.ServiceVersion,
+		})
+		text.Warning(out, "Unable to watch the canary: %s", err)
+		if err := c.rollback(serviceID, previous, out); err != nil {
+			return err
+		}
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("canary service version %d was rolled back: %w", c.Input.ServiceVersion, err),
+			Remediation: "Check that realtime stats are available for the service (`fastly stats realtime`) before activating the version again.",
+		}
+	}
+	if breach == nil {
+		text.Success(out, "Service %s version %d passed its %s bake", serviceID, c.Input.ServiceVersion, c.canary.bake)
+		return nil
+	}
+
+	text.Warning(out, "Threshold %q breached at %s (value: %g)", breach.alert, breach.at.UTC().Format(time.RFC3339), breach.value)
+	if err := c.rollback(serviceID, previous, out); err != nil {
+		return err
+	}
+	return errors.RemediationError{
+		Inner:       fmt.Errorf("canary service version %d was rolled back: %q breached", c.Input.ServiceVersion, breach.alert),
+		Remediation: "Review the service with `fastly stats realtime` (or `fastly log-tail` for Compute services) before activating the version again.",
+	}
+}
+
+// rollback reactivates the version that was active before the canary.
+func (c *ActivateCommand) rollback(serviceID string, previous int, out io.Writer) error {
+	_, err := c.Globals.APIClient.ActivateVersion(context.TODO(), &fastly.ActivateVersionInput{
+		ServiceID:      serviceID,
+		ServiceVersion: previous,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]any{
+			"Service ID":      serviceID,
+			"Service Version": previous,
+		})
+		return fmt.Errorf("error reactivating service version %d after the canary failed: %w", previous, err)
+	}
+	text.Success(out, "Reactivated service %s version %d", serviceID, previous)
 	return nil
 }
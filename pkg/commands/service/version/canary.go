@@ -0,0 +1,154 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/cli/pkg/commands/stats"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// canary holds the configuration of `service version activate --canary`.
+type canary struct {
+	// alerts are additional stats expressions that trigger a rollback.
+	alerts []string
+	// bake is how long to watch the service after activating.
+	bake time.Duration
+	// breachFor is how long a threshold must be breached before rolling back.
+	breachFor time.Duration
+	// maxErrorRatio is the highest acceptable ratio of 5xx responses.
+	maxErrorRatio float64
+	// maxOriginLatency is the highest acceptable average time spent fetching
+	// from origin on a cache miss (zero to disable the check).
+	maxOriginLatency time.Duration
+}
+
+// thresholds returns the alerts that trigger a rollback.
+func (c canary) thresholds() ([]*stats.Alert, error) {
+	exprs := []string{
+		fmt.Sprintf("status_5xx/requests > %g for %s", c.maxErrorRatio, c.breachFor),
+	}
+	if c.maxOriginLatency > 0 {
+		exprs = append(exprs, fmt.Sprintf("miss_time/miss > %g for %s", c.maxOriginLatency.Seconds(), c.breachFor))
+	}
+	exprs = append(exprs, c.alerts...)
+
+	alerts := make([]*stats.Alert, 0, len(exprs))
+	for _, expr := range exprs {
+		a, err := stats.ParseAlert(expr)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
+
+// canaryBreach describes the threshold that was breached during a bake.
+type canaryBreach struct {
+	alert *stats.Alert
+	at    time.Time
+	value float64
+}
+
+// maxStatsErrors is how many consecutive errors fetching realtime stats
+// abort a bake.
+const maxStatsErrors = 10
+
+// statsRetryDelay is how long to wait before fetching realtime stats again
+// after an error.
+var statsRetryDelay = time.Second
+
+// realtimeEnvelope is a response of the realtime stats API.
+type realtimeEnvelope struct {
+	Timestamp uint64 `json:"timestamp"`
+	Data      []struct {
+		Recorded   float64        `json:"recorded"`
+		Aggregated map[string]any `json:"aggregated"`
+	} `json:"data"`
+}
+
+// checkAlerts checks that the realtime stats of a service can be fetched and,
+// if any stats are available, that every alert can be evaluated against them
+// (e.g. that the fields it refers to exist). It's called before activating, so
+// that a mistake in an --alert expression doesn't leave the canary live.
+func checkAlerts(client api.RealtimeStatsInterface, serviceID string, alerts []*stats.Alert) error {
+	var envelope realtimeEnvelope
+	err := client.GetRealtimeStatsJSON(context.TODO(), &fastly.GetRealtimeStatsInput{
+		ServiceID: serviceID,
+	}, &envelope)
+	if err != nil {
+		return fmt.Errorf("error fetching realtime stats: %w", err)
+	}
+	if len(envelope.Data) == 0 {
+		return nil
+	}
+	for _, a := range alerts {
+		if err := a.Validate(envelope.Data[0].Aggregated); err != nil {
+			return fmt.Errorf("error evaluating %q: %w", a, err)
+		}
+	}
+	return nil
+}
+
+// bake watches the realtime stats of a service until the bake window ends,
+// and returns the first threshold that was breached (if any).
+//
+// It returns an error if no stats are received during the window, or if
+// fetching them fails repeatedly, as the canary can't be considered healthy
+// when nothing was observed.
+func bake(client api.RealtimeStatsInterface, serviceID string, alerts []*stats.Alert, until time.Time, verbose bool, out io.Writer) (*canaryBreach, error) {
+	var (
+		timestamp uint64
+		failures  int
+		observed  bool
+	)
+	for time.Now().Before(until) {
+		var envelope realtimeEnvelope
+		err := client.GetRealtimeStatsJSON(context.TODO(), &fastly.GetRealtimeStatsInput{
+			ServiceID: serviceID,
+			Timestamp: timestamp,
+		}, &envelope)
+		if err != nil {
+			// A blip fetching stats isn't a reason to roll back, but there's
+			// no point baking if we can't see the stats at all.
+			failures++
+			if failures == maxStatsErrors {
+				return nil, fmt.Errorf("error fetching realtime stats (%d consecutive failures): %w", failures, err)
+			}
+			text.Warning(out, "Error fetching realtime stats: %s", err)
+			time.Sleep(statsRetryDelay)
+			continue
+		}
+		failures = 0
+		timestamp = envelope.Timestamp
+
+		for _, block := range envelope.Data {
+			observed = true
+			recorded := time.Unix(int64(block.Recorded), 0)
+			if verbose {
+				requests, _ := block.Aggregated["requests"].(float64)
+				errors, _ := block.Aggregated["status_5xx"].(float64)
+				text.Output(out, "%s: %.0f requests, %.0f 5xx responses", recorded.UTC().Format(time.RFC3339), requests, errors)
+			}
+			for _, a := range alerts {
+				value, fire, err := a.Observe(block.Aggregated, recorded, time.Second)
+				if err != nil {
+					return nil, fmt.Errorf("error evaluating %q: %w", a, err)
+				}
+				if fire {
+					return &canaryBreach{alert: a, at: recorded, value: value}, nil
+				}
+			}
+		}
+	}
+	if !observed {
+		return nil, fmt.Errorf("no realtime stats were received during the bake window")
+	}
+	return nil, nil
+}
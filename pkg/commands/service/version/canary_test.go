@@ -0,0 +1,174 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/mock"
+)
+
+// realtimeStats replays a realtime stats response per call. Once the
+// responses are exhausted, it either repeats the last one or fails.
+type realtimeStats struct {
+	responses []string
+	repeat    bool
+	calls     int
+}
+
+func (r *realtimeStats) GetRealtimeStatsJSON(_ context.Context, _ *fastly.GetRealtimeStatsInput, dst any) error {
+	r.calls++
+	if r.calls > len(r.responses) {
+		if !r.repeat || len(r.responses) == 0 {
+			return fmt.Errorf("no more stats")
+		}
+		return json.Unmarshal([]byte(r.responses[len(r.responses)-1]), dst)
+	}
+	return json.Unmarshal([]byte(r.responses[r.calls-1]), dst)
+}
+
+func realtimeResponse(recorded, requests, errors int) string {
+	return fmt.Sprintf(`{"timestamp":%d,"data":[{"recorded":%d,"aggregated":{"requests":%d,"status_5xx":%d,"miss":10,"miss_time":1}}]}`, recorded, recorded, requests, errors)
+}
+
+func TestCanaryBake(t *testing.T) {
+	c := canary{
+		alerts:           []string{"requests < 10"},
+		breachFor:        2 * time.Second,
+		maxErrorRatio:    0.02,
+		maxOriginLatency: 200 * time.Millisecond,
+	}
+	alerts, err := c.thresholds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 3 {
+		t.Fatalf("want 3 thresholds, got %d", len(alerts))
+	}
+
+	// The error ratio has to stay above the threshold for two seconds.
+	client := &realtimeStats{responses: []string{
+		realtimeResponse(1, 100, 1),
+		realtimeResponse(2, 100, 5),
+		realtimeResponse(3, 100, 1),
+		realtimeResponse(4, 100, 5),
+		realtimeResponse(5, 100, 5),
+		realtimeResponse(6, 100, 5),
+	}}
+	breach, err := bake(client, "123", alerts, time.Now().Add(time.Minute), false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breach == nil {
+		t.Fatal("want a breach")
+	}
+	if got := breach.alert.String(); got != "status_5xx/requests > 0.02 for 2s" {
+		t.Errorf("unexpected threshold breached: %s", got)
+	}
+	if breach.at.Unix() != 5 || breach.value != 0.05 {
+		t.Errorf("unexpected breach: %+v", breach)
+	}
+
+	// Nothing is breached when the bake window ends.
+	alerts, _ = c.thresholds()
+	client = &realtimeStats{responses: []string{
+		realtimeResponse(1, 100, 1),
+		realtimeResponse(2, 100, 2),
+	}}
+	breach, err = bake(client, "123", alerts, time.Now().Add(50*time.Millisecond), false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breach != nil {
+		t.Errorf("unexpected breach: %s", breach.alert)
+	}
+}
+
+func TestCanaryBakeWithoutStats(t *testing.T) {
+	statsRetryDelay = 0
+	defer func() { statsRetryDelay = time.Second }()
+
+	c := canary{breachFor: time.Second, maxErrorRatio: 0.02}
+	alerts, err := c.thresholds()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Repeated errors fetching stats abort the bake.
+	client := &realtimeStats{}
+	_, err = bake(client, "123", alerts, time.Now().Add(time.Minute), false, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "10 consecutive failures") {
+		t.Errorf("want an error after consecutive failures, got: %v", err)
+	}
+
+	// A bake that observed no stats doesn't pass.
+	client = &realtimeStats{responses: []string{`{"timestamp":1,"data":[]}`}, repeat: true}
+	_, err = bake(client, "123", alerts, time.Now().Add(50*time.Millisecond), false, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "no realtime stats were received") {
+		t.Errorf("want an error when no stats were received, got: %v", err)
+	}
+}
+
+func TestCheckAlerts(t *testing.T) {
+	c := canary{alerts: []string{"status_4xx/requests > 0.1"}, breachFor: time.Second, maxErrorRatio: 0.02}
+	alerts, err := c.thresholds()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// realtimeResponse has no status_4xx field.
+	err = checkAlerts(&realtimeStats{responses: []string{realtimeResponse(1, 100, 1)}}, "123", alerts)
+	if err == nil || !strings.Contains(err.Error(), `unknown stats field "status_4xx"`) {
+		t.Errorf("want an unknown field error, got: %v", err)
+	}
+
+	// The fields can't be checked without traffic.
+	err = checkAlerts(&realtimeStats{responses: []string{`{"timestamp":1,"data":[]}`}}, "123", alerts)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = checkAlerts(&realtimeStats{}, "123", alerts)
+	if err == nil || !strings.Contains(err.Error(), "error fetching realtime stats") {
+		t.Errorf("want a fetch error, got: %v", err)
+	}
+}
+
+func TestCanaryRollbackOnBakeError(t *testing.T) {
+	var activated []int
+	c := ActivateCommand{
+		canary: canary{bake: time.Minute, breachFor: time.Second, maxErrorRatio: 0.02},
+	}
+	c.Input.ServiceVersion = 4
+	c.Globals = &global.Data{
+		APIClient: mock.API{
+			ActivateVersionFn: func(_ context.Context, i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+				activated = append(activated, i.ServiceVersion)
+				return &fastly.Version{}, nil
+			},
+		},
+		ErrLog: fsterr.MockLog{},
+		// The block has no status_5xx field, so the threshold can't be evaluated.
+		RTSClient: &realtimeStats{responses: []string{`{"timestamp":1,"data":[{"recorded":1,"aggregated":{"requests":1}}]}`}},
+	}
+	alerts, err := c.canary.thresholds()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.bake("123", 3, alerts, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "canary service version 4 was rolled back") {
+		t.Errorf("want a rollback error, got: %v", err)
+	}
+	if len(activated) != 1 || activated[0] != 3 {
+		t.Errorf("want service version 3 reactivated, got: %v", activated)
+	}
+}
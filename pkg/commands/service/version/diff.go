@@ -0,0 +1,131 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+
+	"github.com/fastly/cli/pkg/argparser"
+	"github.com/fastly/cli/pkg/commands/service/snapshot"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/global"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// DiffCommand compares the versioned resources of two service versions.
+type DiffCommand struct {
+	argparser.Base
+	argparser.JSONOutput
+
+	serviceName argparser.OptionalServiceNameID
+	versionA    argparser.OptionalServiceVersion
+	versionB    argparser.OptionalServiceVersion
+}
+
+// NewDiffCommand returns a usable command registered under the parent.
+func NewDiffCommand(parent argparser.Registerer, g *global.Data) *DiffCommand {
+	c := DiffCommand{
+		Base: argparser.Base{
+			Globals: g,
+		},
+	}
+	c.CmdClause = parent.Command("diff", "Show the differences between the resources of two Fastly service versions")
+
+	// Required.
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.versionA.Set,
+		Name:        "version-a",
+		Description: "The version to compare from: " + argparser.FlagVersionDesc,
+		Dst:         &c.versionA.Value,
+		Required:    true,
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.versionB.Set,
+		Name:        "version-b",
+		Description: "The version to compare to: " + argparser.FlagVersionDesc,
+		Dst:         &c.versionB.Value,
+		Required:    true,
+	})
+
+	// Optional.
+	c.RegisterFlagBool(c.JSONFlag()) // --json
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Name:        argparser.FlagServiceIDName,
+		Description: argparser.FlagServiceIDDesc,
+		Dst:         &g.Manifest.Flag.ServiceID,
+		Short:       's',
+	})
+	c.RegisterFlag(argparser.StringFlagOpts{
+		Action:      c.serviceName.Set,
+		Name:        argparser.FlagServiceName,
+		Description: argparser.FlagServiceNameDesc,
+		Dst:         &c.serviceName.Value,
+	})
+	return &c
+}
+
+// diffOutput is the structure emitted when --json is set.
+type diffOutput struct {
+	ServiceID string            `json:"service_id"`
+	VersionA  int               `json:"version_a"`
+	VersionB  int               `json:"version_b"`
+	Changes   []snapshot.Change `json:"changes"`
+}
+
+// Exec invokes the application logic for the command.
+func (c *DiffCommand) Exec(_ io.Reader, out io.Writer) error {
+	if c.Globals.Verbose() && c.JSONOutput.Enabled {
+		return fsterr.ErrInvalidVerboseJSONCombo
+	}
+
+	serviceID, source, flag, err := argparser.ServiceID(c.serviceName, *c.Globals.Manifest, c.Globals.APIClient, c.Globals.ErrLog)
+	if err != nil {
+		return err
+	}
+	if c.Globals.Verbose() {
+		argparser.DisplayServiceID(serviceID, flag, source, out)
+	}
+
+	kinds := snapshot.Kinds(c.Globals.APIClient)
+
+	var docs [2]*snapshot.Document
+	for i, sv := range []*argparser.OptionalServiceVersion{&c.versionA, &c.versionB} {
+		v, err := sv.Parse(serviceID, c.Globals.APIClient)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]any{
+				"Service ID":      serviceID,
+				"Service Version": sv.Value,
+			})
+			return err
+		}
+		docs[i], err = snapshot.Capture(context.TODO(), kinds, serviceID, fastly.ToValue(v.Number))
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]any{
+				"Service ID":      serviceID,
+				"Service Version": fastly.ToValue(v.Number),
+			})
+			return err
+		}
+	}
+	a, b := docs[0].Service.Version, docs[1].Service.Version
+
+	changes := snapshot.Diff(kinds, docs[0], docs[1])
+
+	if ok, err := c.WriteJSON(out, diffOutput{
+		ServiceID: serviceID,
+		VersionA:  a,
+		VersionB:  b,
+		Changes:   append([]snapshot.Change{}, changes...),
+	}); ok {
+		return err
+	}
+
+	if len(changes) == 0 {
+		text.Info(out, "No differences between service %s version %d and version %d", serviceID, a, b)
+		return nil
+	}
+	snapshot.WriteUnified(out, fmt.Sprintf("service %s version %d", serviceID, a), fmt.Sprintf("service %s version %d", serviceID, b), changes)
+	return nil
+}
@@ -162,6 +162,58 @@ func TestVersionActivate(t *testing.T) {
 			},
 			WantOutput: "Activated service 123 version 4",
 		},
+		{
+			Name: "validate --canary requires a previously active version",
+			API: &mock.API{
+				GetVersionFn: testutil.GetVersion,
+				GetServiceDetailsFn: func(_ context.Context, i *fastly.GetServiceDetailsInput) (*fastly.ServiceDetail, error) {
+					return &fastly.ServiceDetail{ServiceID: fastly.ToPointer(i.ServiceID)}, nil
+				},
+				ActivateVersionFn: activateVersionOK,
+			},
+			Args:            "--service-id 123 --version 3 --canary",
+			WantError:       "service 123 has no active version to roll back to",
+			DontWantOutputs: []string{"Activated service"},
+		},
+		{
+			Name: "validate --canary with the active version",
+			API: &mock.API{
+				GetVersionFn:        testutil.GetVersion,
+				GetServiceDetailsFn: testutil.GetServiceDetails,
+				ActivateVersionFn:   activateVersionOK,
+			},
+			Args:      "--service-id 123 --version 1 --canary",
+			WantError: "service version 1 is already active",
+		},
+		{
+			Name: "validate --canary with an invalid --alert",
+			API: &mock.API{
+				GetVersionFn:        testutil.GetVersion,
+				GetServiceDetailsFn: testutil.GetServiceDetails,
+				ActivateVersionFn:   activateVersionOK,
+			},
+			Args:      "--service-id 123 --version 3 --canary --alert requests",
+			WantError: `error parsing alert "requests": expected a comparison`,
+		},
+		{
+			Name: "validate --alert requires --canary",
+			API: &mock.API{
+				GetVersionFn:      testutil.GetVersion,
+				ActivateVersionFn: activateVersionOK,
+			},
+			Args:            "--service-id 123 --version 3 --alert status_5xx/requests>0.1",
+			WantError:       "invalid flag combination, --alert, --bake, --breach-for, --max-error-ratio and --max-origin-latency require --canary",
+			WantRemediation: "Add --canary",
+		},
+		{
+			Name: "validate --bake requires --canary",
+			API: &mock.API{
+				GetVersionFn:      testutil.GetVersion,
+				ActivateVersionFn: activateVersionOK,
+			},
+			Args:      "--service-id 123 --version 3 --bake 5m",
+			WantError: "require --canary",
+		},
 		{
 			Name: "validate API error when modifying active version",
 			API: &mock.API{
@@ -415,6 +467,102 @@ func TestVersionUnstage(t *testing.T) {
 	testutil.RunCLIScenarios(t, []string{root.CommandName, sub.CommandName, "unstage"}, scenarios)
 }
 
+func TestVersionDiff(t *testing.T) {
+	api := func() *mock.API {
+		return testutil.EmptyServiceResources(&mock.API{
+			GetVersionFn: testutil.GetVersion,
+			ListBackendsFn: func(_ context.Context, i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+				port := 80
+				if i.ServiceVersion == 3 {
+					port = 443
+				}
+				return []*fastly.Backend{
+					{
+						Address: fastly.ToPointer("example.com"),
+						Name:    fastly.ToPointer("origin"),
+						Port:    fastly.ToPointer(port),
+					},
+				}, nil
+			},
+			ListDomainsFn: func(_ context.Context, i *fastly.ListDomainsInput) ([]*fastly.Domain, error) {
+				if i.ServiceVersion != 3 {
+					return nil, nil
+				}
+				return []*fastly.Domain{{Name: fastly.ToPointer("www.example.com")}}, nil
+			},
+			ListVCLsFn: func(_ context.Context, i *fastly.ListVCLsInput) ([]*fastly.VCL, error) {
+				content := "sub vcl_recv {\n  set req.http.X = \"1\";\n}"
+				if i.ServiceVersion == 3 {
+					content = "sub vcl_recv {\n  set req.http.X = \"2\";\n}"
+				}
+				return []*fastly.VCL{
+					{
+						Content: fastly.ToPointer(content),
+						Main:    fastly.ToPointer(true),
+						Name:    fastly.ToPointer("main"),
+					},
+				}, nil
+			},
+		})
+	}
+
+	scenarios := []testutil.CLIScenario{
+		{
+			Name:      "validate missing --version-b flag",
+			Args:      "--service-id 123 --version-a 1",
+			WantError: "error parsing arguments: required flag --version-b not provided",
+		},
+		{
+			Name: "validate unified diff",
+			Args: "--service-id 123 --version-a 1 --version-b 3",
+			API:  api(),
+			WantOutputs: []string{
+				"--- service 123 version 1",
+				"+++ service 123 version 3",
+				`@@ backends "origin" (changed) @@`,
+				"- port = 80",
+				"+ port = 443",
+				`@@ domains "www.example.com" (added) @@`,
+				`+ name = "www.example.com"`,
+				`@@ vcls "main" (changed) @@`,
+				`-     set req.http.X = "1";`,
+				`+     set req.http.X = "2";`,
+			},
+			DontWantOutput: "- address",
+		},
+		{
+			Name:       "validate identical versions",
+			Args:       "--service-id 123 --version-a 1 --version-b 1",
+			API:        api(),
+			WantOutput: "No differences between service 123 version 1 and version 1",
+		},
+		{
+			Name: "validate json output",
+			Args: "--service-id 123 --version-a 1 --version-b 3 --json",
+			API:  api(),
+			WantOutputs: []string{
+				`"version_a": 1`,
+				`"version_b": 3`,
+				`"kind": "domains"`,
+				`"action": "added"`,
+			},
+		},
+		{
+			Name: "validate list error is passed through",
+			Args: "--service-id 123 --version-a 1 --version-b 3",
+			API: testutil.EmptyServiceResources(&mock.API{
+				GetVersionFn: testutil.GetVersion,
+				ListBackendsFn: func(_ context.Context, _ *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+					return nil, testutil.Err
+				},
+			}),
+			WantError: "error listing backends: test error",
+		},
+	}
+
+	testutil.RunCLIScenarios(t, []string{root.CommandName, sub.CommandName, "diff"}, scenarios)
+}
+
 var cloneServiceVersionJSONOutput = strings.TrimSpace(`
 {
   "Active": null,
@@ -0,0 +1,399 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	fstexec "github.com/fastly/cli/pkg/exec"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// alertSuffix matches the optional `for <duration>` suffix of an expression.
+var alertSuffix = regexp.MustCompile(`^(.*?)\s+for\s+(\S+)\s*$`)
+
+// alertOperators are the supported comparisons. Two character operators are
+// listed first so they're matched before their one character prefixes.
+var alertOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Alert is a condition over stats fields, parsed from an --alert expression
+// such as `status_5xx/requests > 0.02 for 30s`.
+type Alert struct {
+	// Duration is how long the condition must hold before the alert fires.
+	Duration time.Duration
+
+	// expr is the expression as given by the user.
+	expr string
+
+	lhs, rhs alertNode
+	op       string
+
+	// since is the start of the window in which the condition started to
+	// hold (zero if it doesn't currently hold).
+	since time.Time
+	// fired is set once the alert fires, so that it fires once per breach.
+	fired bool
+}
+
+// ParseAlert parses an alert expression.
+//
+// Expressions compare two arithmetic expressions over stats fields (e.g.
+// requests, status_5xx) and numbers, and may require the condition to hold
+// for a duration: `<expr> <op> <expr> [for <duration>]`.
+func ParseAlert(expr string) (*Alert, error) {
+	a := Alert{expr: expr}
+
+	cond := expr
+	if m := alertSuffix.FindStringSubmatch(expr); m != nil {
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing alert %q: invalid duration %q", expr, m[2])
+		}
+		cond, a.Duration = m[1], d
+	}
+
+	for _, op := range alertOperators {
+		lhs, rhs, ok := strings.Cut(cond, op)
+		if !ok {
+			continue
+		}
+		var err error
+		if a.lhs, err = parseAlertExpr(lhs); err != nil {
+			return nil, fmt.Errorf("error parsing alert %q: %w", expr, err)
+		}
+		if a.rhs, err = parseAlertExpr(rhs); err != nil {
+			return nil, fmt.Errorf("error parsing alert %q: %w", expr, err)
+		}
+		a.op = op
+		return &a, nil
+	}
+	return nil, fmt.Errorf("error parsing alert %q: expected a comparison (one of %s)", expr, strings.Join(alertOperators, " "))
+}
+
+// String returns the expression the alert was parsed from.
+func (a *Alert) String() string {
+	return a.expr
+}
+
+// Validate checks that the alert can be evaluated against the stats (e.g. that
+// every field it refers to exists) without observing them.
+func (a *Alert) Validate(data map[string]any) error {
+	if _, err := a.lhs.eval(data); err != nil {
+		return err
+	}
+	_, err := a.rhs.eval(data)
+	return err
+}
+
+// Observe evaluates the alert against the stats (a block of realtime or
+// historical stats, keyed by field name) for a window of time starting at
+// start. It reports whether the alert fires, which happens once the condition
+// has held for the alert's duration.
+func (a *Alert) Observe(data map[string]any, start time.Time, span time.Duration) (value float64, fire bool, err error) {
+	value, err = a.lhs.eval(data)
+	if err != nil {
+		return 0, false, err
+	}
+	threshold, err := a.rhs.eval(data)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if !compare(value, a.op, threshold) {
+		a.since, a.fired = time.Time{}, false
+		return value, false, nil
+	}
+	if a.since.IsZero() {
+		a.since = start
+	}
+	if a.fired || start.Add(span).Sub(a.since) < a.Duration {
+		return value, false, nil
+	}
+	a.fired = true
+	return value, true, nil
+}
+
+// compare applies a comparison operator. Comparisons involving NaN (e.g. the
+// result of dividing by a field that's zero) are always false.
+func compare(a float64, op string, b float64) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case "==":
+		return a == b
+	case "!=":
+		return !math.IsNaN(a) && !math.IsNaN(b) && a != b
+	}
+	return false
+}
+
+// alertNode is a node of a parsed arithmetic expression.
+type alertNode interface {
+	eval(statsResponseData) (float64, error)
+}
+
+type (
+	alertNumber float64
+	alertField  string
+	alertNeg    struct{ n alertNode }
+	alertBinary struct {
+		op   rune
+		l, r alertNode
+	}
+)
+
+func (n alertNumber) eval(statsResponseData) (float64, error) {
+	return float64(n), nil
+}
+
+func (f alertField) eval(data statsResponseData) (float64, error) {
+	v, ok := data[string(f)]
+	if !ok {
+		return 0, fmt.Errorf("unknown stats field %q", string(f))
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("stats field %q isn't a number", string(f))
+	}
+	return n, nil
+}
+
+func (n alertNeg) eval(data statsResponseData) (float64, error) {
+	v, err := n.n.eval(data)
+	return -v, err
+}
+
+func (b alertBinary) eval(data statsResponseData) (float64, error) {
+	l, err := b.l.eval(data)
+	if err != nil {
+		return 0, err
+	}
+	r, err := b.r.eval(data)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	default:
+		if r == 0 {
+			return math.NaN(), nil
+		}
+		return l / r, nil
+	}
+}
+
+// alertParser is a recursive descent parser for arithmetic expressions:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | field | "-" factor | "(" expr ")"
+type alertParser struct {
+	s   string
+	pos int
+}
+
+func parseAlertExpr(s string) (alertNode, error) {
+	p := alertParser{s: s}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+	return n, nil
+}
+
+func (p *alertParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// next consumes the next character if it's one of chars.
+func (p *alertParser) next(chars string) (rune, bool) {
+	p.skipSpace()
+	if p.pos < len(p.s) && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+		return rune(p.s[p.pos-1]), true
+	}
+	return 0, false
+}
+
+func (p *alertParser) expr() (alertNode, error) {
+	n, err := p.term()
+	for err == nil {
+		op, ok := p.next("+-")
+		if !ok {
+			break
+		}
+		var r alertNode
+		r, err = p.term()
+		n = alertBinary{op, n, r}
+	}
+	return n, err
+}
+
+func (p *alertParser) term() (alertNode, error) {
+	n, err := p.factor()
+	for err == nil {
+		op, ok := p.next("*/")
+		if !ok {
+			break
+		}
+		var r alertNode
+		r, err = p.factor()
+		n = alertBinary{op, n, r}
+	}
+	return n, err
+}
+
+func (p *alertParser) factor() (alertNode, error) {
+	if _, ok := p.next("-"); ok {
+		n, err := p.factor()
+		return alertNeg{n}, err
+	}
+	if _, ok := p.next("("); ok {
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.next(")"); !ok {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		return n, nil
+	}
+
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := rune(p.s[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' {
+			break
+		}
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+	switch {
+	case token == "":
+		if start == len(p.s) {
+			return nil, errors.New("unexpected end of expression")
+		}
+		return nil, fmt.Errorf("unexpected %q", p.s[start:])
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		v, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return alertNumber(v), nil
+	default:
+		return alertField(token), nil
+	}
+}
+
+// alertWatch evaluates the --alert expressions against stats as they're
+// received, and either runs the --alert-exec hook or stops the command when
+// one of them fires.
+type alertWatch struct {
+	alerts []*Alert
+	// hook is a command run (through a shell) whenever an alert fires.
+	hook string
+	// quiet suppresses messages that would corrupt machine-readable output.
+	quiet   bool
+	out     io.Writer
+	service string
+	verbose bool
+}
+
+// newAlertWatch parses the --alert expressions.
+func newAlertWatch(exprs []string, hook, service string, quiet, verbose bool, out io.Writer) (*alertWatch, error) {
+	w := alertWatch{
+		hook:    hook,
+		out:     out,
+		quiet:   quiet,
+		service: service,
+		verbose: verbose,
+	}
+	for _, expr := range exprs {
+		a, err := ParseAlert(expr)
+		if err != nil {
+			return nil, err
+		}
+		w.alerts = append(w.alerts, a)
+	}
+	return &w, nil
+}
+
+// check evaluates the alerts against the stats for a window of time. It returns
+// an error if an alert fires and there's no hook to run instead.
+func (w *alertWatch) check(data statsResponseData, start time.Time, span time.Duration) error {
+	for _, a := range w.alerts {
+		value, fire, err := a.Observe(data, start, span)
+		if err != nil {
+			return fmt.Errorf("error evaluating alert %q: %w", a.expr, err)
+		}
+		if !fire {
+			continue
+		}
+
+		if w.hook == "" {
+			return fmt.Errorf("alert %q fired at %s (value: %s)", a.expr, start.UTC().Format(time.RFC3339), formatAlertValue(value))
+		}
+		if !w.quiet {
+			text.Warning(w.out, "Alert %q fired at %s (value: %s)", a.expr, start.UTC().Format(time.RFC3339), formatAlertValue(value))
+		}
+		if err := w.runHook(a, value, start); err != nil && !w.quiet {
+			text.Warning(w.out, "Alert hook failed: %s", err)
+		}
+	}
+	return nil
+}
+
+// runHook runs the --alert-exec command, passing the details of the alert in
+// environment variables.
+func (w *alertWatch) runHook(a *Alert, value float64, start time.Time) error {
+	cmd, args := "sh", []string{"-c", w.hook}
+	if runtime.GOOS == "windows" {
+		cmd, args = "cmd.exe", []string{"/C", w.hook}
+	}
+	s := fstexec.Streaming{
+		Command: cmd,
+		Args:    args,
+		Env: []string{
+			"FASTLY_ALERT=" + a.expr,
+			"FASTLY_ALERT_VALUE=" + formatAlertValue(value),
+			"FASTLY_ALERT_TIME=" + start.UTC().Format(time.RFC3339),
+			"FASTLY_SERVICE_ID=" + w.service,
+		},
+		ForceOutput: true,
+		Output:      w.out,
+		Verbose:     w.verbose,
+	}
+	if w.quiet {
+		s.Output = io.Discard
+	}
+	return s.Exec()
+}
+
+// formatAlertValue formats the value of an alert expression.
+func formatAlertValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,142 @@
+package stats
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlert(t *testing.T) {
+	data := statsResponseData{"requests": 200.0, "status_5xx": 5.0, "hits": 150.0, "miss": 50.0, "errors": 0.0}
+
+	for _, tc := range []struct {
+		expr     string
+		value    float64
+		breached bool
+		duration time.Duration
+	}{
+		{expr: "status_5xx/requests > 0.02", value: 0.025, breached: true},
+		{expr: "status_5xx / requests > 0.02 for 30s", value: 0.025, breached: true, duration: 30 * time.Second},
+		{expr: "hits/(hits+miss) < 0.8", value: 0.75, breached: true},
+		{expr: "requests >= 200", value: 200, breached: true},
+		{expr: "requests*2 - 100 == 300", value: 300, breached: true},
+		{expr: "-errors != 0", value: 0},
+		{expr: "status_5xx/errors > 0", value: math.NaN()},
+		{expr: "requests <= 100 for 1m", value: 200, duration: time.Minute},
+	} {
+		a, err := ParseAlert(tc.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expr, err)
+			continue
+		}
+		if a.Duration != tc.duration {
+			t.Errorf("%s: want duration %s, got %s", tc.expr, tc.duration, a.Duration)
+		}
+		value, err := a.lhs.eval(data)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expr, err)
+			continue
+		}
+		if value != tc.value && !(math.IsNaN(value) && math.IsNaN(tc.value)) {
+			t.Errorf("%s: want value %v, got %v", tc.expr, tc.value, value)
+		}
+		threshold, _ := a.rhs.eval(data)
+		if got := compare(value, a.op, threshold); got != tc.breached {
+			t.Errorf("%s: want breached %t, got %t", tc.expr, tc.breached, got)
+		}
+	}
+
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{expr: "requests", want: "expected a comparison"},
+		{expr: "requests > ", want: "unexpected end of expression"},
+		{expr: "(requests > 1", want: "missing closing parenthesis"},
+		{expr: "requests > 1 for ever", want: `invalid duration "ever"`},
+		{expr: "requests > 1.2.3", want: `invalid number "1.2.3"`},
+		{expr: "requests % 2 > 1", want: `unexpected "% 2 "`},
+	} {
+		_, err := ParseAlert(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: want error containing %q, got: %v", tc.expr, tc.want, err)
+		}
+	}
+}
+
+func TestAlertValidate(t *testing.T) {
+	a, err := ParseAlert("status_5xx/requets > 0.02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := statsResponseData{"requests": 100.0, "status_5xx": 10.0}
+	if err := a.Validate(data); err == nil || !strings.Contains(err.Error(), `unknown stats field "requets"`) {
+		t.Errorf("want unknown field error, got: %v", err)
+	}
+
+	a, err = ParseAlert("status_5xx/requests > 0.02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Validate(data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// Validating doesn't count towards the alert's duration.
+	if _, fire, _ := a.Observe(data, time.Unix(0, 0), time.Second); !fire {
+		t.Error("want the alert to fire on its first observation")
+	}
+}
+
+func TestAlertObserve(t *testing.T) {
+	a, err := ParseAlert("status_5xx/requests > 0.02 for 3s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bad := statsResponseData{"requests": 100.0, "status_5xx": 10.0}
+	good := statsResponseData{"requests": 100.0, "status_5xx": 1.0}
+	start := time.Unix(1700000000, 0)
+
+	// The alert fires once the condition has held for 3s, and only once for
+	// each breach.
+	for i, tc := range []struct {
+		data statsResponseData
+		fire bool
+	}{
+		{data: bad},
+		{data: bad},
+		{data: good},
+		{data: bad},
+		{data: bad},
+		{data: bad, fire: true},
+		{data: bad},
+		{data: good},
+		{data: bad},
+	} {
+		_, fire, err := a.Observe(tc.data, start.Add(time.Duration(i)*time.Second), time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fire != tc.fire {
+			t.Errorf("sample %d: want fire %t, got %t", i, tc.fire, fire)
+		}
+	}
+}
+
+func TestAlertWatch(t *testing.T) {
+	var out bytes.Buffer
+	w, err := newAlertWatch([]string{"requests > 10"}, "", "123", false, false, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.check(statsResponseData{"requests": 5.0}, time.Unix(0, 0), time.Second); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = w.check(statsResponseData{"requests": 50.0}, time.Unix(60, 0), time.Second)
+	want := `alert "requests > 10" fired at 1970-01-01T00:01:00Z (value: 50)`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got: %v", want, err)
+	}
+}
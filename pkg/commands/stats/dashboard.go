@@ -0,0 +1,281 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dashboardHistory is the number of realtime samples (one per second) kept by
+// the dashboard. It bounds the length of the sparklines.
+const dashboardHistory = 300
+
+// dashboardMetric is a series plotted by the dashboard.
+type dashboardMetric struct {
+	name   string
+	value  func(statsResponseData) float64
+	format func(float64) string
+}
+
+// dashboardMetrics are the series the dashboard cycles through, in order.
+var dashboardMetrics = []dashboardMetric{
+	{name: "Requests", value: field("requests"), format: formatCount},
+	{name: "Hit ratio", value: hitRatio, format: formatPercent},
+	{name: "Bandwidth", value: field("bandwidth"), format: formatBytes},
+	{name: "Status 4xx", value: field("status_4xx"), format: formatCount},
+	{name: "Status 5xx", value: field("status_5xx"), format: formatCount},
+}
+
+// sparks are the characters used to draw sparklines, from lowest to highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboard holds the state of the `stats realtime --tui` view.
+//
+// It's kept separate from the terminal handling so that rendering and key
+// handling can be exercised without a terminal.
+type dashboard struct {
+	service string
+
+	// datacenter restricts the plotted series to a single POP ("" for all).
+	datacenter string
+	// err is the last error returned by the realtime API, if any.
+	err error
+	// metric is the index of the selected metric in dashboardMetrics.
+	metric int
+	// paused freezes the view. Samples received while paused are held in
+	// pending until the view is resumed.
+	paused  bool
+	pending []realtimeResponseData
+	samples []realtimeResponseData
+}
+
+// add records the samples from a realtime envelope.
+func (d *dashboard) add(blocks []realtimeResponseData) {
+	d.err = nil
+	if d.paused {
+		d.pending = append(d.pending, blocks...)
+		return
+	}
+	d.samples = append(d.samples, blocks...)
+	if n := len(d.samples) - dashboardHistory; n > 0 {
+		d.samples = d.samples[n:]
+	}
+}
+
+// handleKey updates the view for a key press. It returns true if the user
+// asked to quit.
+func (d *dashboard) handleKey(key string) (quit bool) {
+	switch key {
+	case "q", "Q", "\x03", "\x1b":
+		return true
+	case " ", "p":
+		d.paused = !d.paused
+		if !d.paused {
+			pending := d.pending
+			d.pending = nil
+			d.add(pending)
+		}
+	case "m", "\t", "\x1b[C":
+		d.metric = (d.metric + 1) % len(dashboardMetrics)
+	case "M", "\x1b[Z", "\x1b[D":
+		d.metric = (d.metric + len(dashboardMetrics) - 1) % len(dashboardMetrics)
+	case "d", "\x1b[B":
+		d.datacenter = d.cycleDatacenter(1)
+	case "D", "\x1b[A":
+		d.datacenter = d.cycleDatacenter(-1)
+	case "a":
+		d.datacenter = ""
+	}
+	return false
+}
+
+// cycleDatacenter returns the datacenter after (or before) the selected one,
+// going through "all datacenters" at either end.
+func (d *dashboard) cycleDatacenter(step int) string {
+	options := append([]string{""}, d.datacenters()...)
+	i := 0
+	for j, dc := range options {
+		if dc == d.datacenter {
+			i = j
+			break
+		}
+	}
+	return options[(i+step+len(options))%len(options)]
+}
+
+// datacenters returns the sorted names of the datacenters seen in the samples.
+func (d *dashboard) datacenters() []string {
+	seen := make(map[string]bool)
+	for _, s := range d.samples {
+		for dc := range s.Datacenter {
+			seen[dc] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for dc := range seen {
+		names = append(names, dc)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// series returns the last n values of a metric, either aggregated or for a
+// single datacenter.
+func (d *dashboard) series(m dashboardMetric, datacenter string, n int) []float64 {
+	samples := d.samples
+	if len(samples) > n {
+		samples = samples[len(samples)-n:]
+	}
+	values := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		data := s.Aggregated
+		if datacenter != "" {
+			data = s.Datacenter[datacenter]
+		}
+		values = append(values, m.value(data))
+	}
+	return values
+}
+
+// render draws a frame of the dashboard, sized for a width x height terminal.
+func (d *dashboard) render(out io.Writer, width, height int) {
+	var b bytes.Buffer
+
+	scope := "all"
+	if d.datacenter != "" {
+		scope = d.datacenter
+	}
+	fmt.Fprintf(&b, "Service ID: %s   Datacenter: %s", d.service, scope)
+	if d.paused {
+		fmt.Fprint(&b, "   [PAUSED]")
+	}
+	fmt.Fprintln(&b)
+	if len(d.samples) > 0 {
+		recorded := d.samples[len(d.samples)-1].Recorded
+		fmt.Fprintf(&b, "Recorded:   %s\n", time.Unix(int64(recorded), 0).UTC().Format(time.RFC3339))
+	} else {
+		fmt.Fprintln(&b, "Waiting for data...")
+	}
+	fmt.Fprintln(&b, strings.Repeat("-", max(width, 1)))
+
+	const labelWidth, valueWidth = 14, 12
+	sparkWidth := max(width-labelWidth-valueWidth-4, 1)
+
+	for i, m := range dashboardMetrics {
+		marker := " "
+		if i == d.metric {
+			marker = ">"
+		}
+		values := d.series(m, d.datacenter, sparkWidth)
+		fmt.Fprintf(&b, "%s %-*s %-*s %*s\n", marker, labelWidth, m.name, sparkWidth, sparkline(values), valueWidth, latest(m, values))
+	}
+
+	m := dashboardMetrics[d.metric]
+	fmt.Fprintf(&b, "\n%s by datacenter\n", m.name)
+
+	type row struct {
+		name   string
+		values []float64
+	}
+	var rows []row
+	for _, dc := range d.datacenters() {
+		rows = append(rows, row{dc, d.series(m, dc, sparkWidth)})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return last(rows[i].values) > last(rows[j].values)
+	})
+	// Leave room for the header, metrics, footer and status lines.
+	available := height - len(dashboardMetrics) - 8
+	for i, r := range rows {
+		if i >= available {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(rows)-i)
+			break
+		}
+		marker := " "
+		if r.name == d.datacenter {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %-*s %-*s %*s\n", marker, labelWidth, r.name, sparkWidth, sparkline(r.values), valueWidth, latest(m, r.values))
+	}
+
+	fmt.Fprintln(&b)
+	if d.err != nil {
+		fmt.Fprintf(&b, "Error fetching stats: %v\n", d.err)
+	}
+	fmt.Fprint(&b, "m/M: metric   d/D: datacenter   a: all datacenters   space: pause   q: quit")
+
+	// The terminal is in raw mode, so line feeds don't return the cursor.
+	_, _ = io.WriteString(out, "\x1b[H\x1b[2J"+strings.ReplaceAll(b.String(), "\n", "\r\n"))
+}
+
+// sparkline draws values scaled between zero and the largest value.
+func sparkline(values []float64) string {
+	var top float64
+	for _, v := range values {
+		top = math.Max(top, v)
+	}
+	s := make([]rune, 0, len(values))
+	for _, v := range values {
+		i := 0
+		if top > 0 {
+			i = int(math.Round(v / top * float64(len(sparks)-1)))
+		}
+		s = append(s, sparks[max(0, min(i, len(sparks)-1))])
+	}
+	return string(s)
+}
+
+// latest formats the most recent value of a series.
+func latest(m dashboardMetric, values []float64) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return m.format(last(values))
+}
+
+// last returns the most recent value of a series.
+func last(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+// field returns the value of a numeric field in a stats block.
+func field(name string) func(statsResponseData) float64 {
+	return func(data statsResponseData) float64 {
+		v, _ := data[name].(float64)
+		return v
+	}
+}
+
+// hitRatio computes the ratio of cache hits to cacheable requests.
+func hitRatio(data statsResponseData) float64 {
+	hits, miss := field("hits")(data), field("miss")(data)
+	if hits+miss == 0 {
+		return 0
+	}
+	return hits / (hits + miss)
+}
+
+func formatCount(v float64) string {
+	return fmt.Sprintf("%.0f/s", v)
+}
+
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.2f%%", v*100)
+}
+
+func formatBytes(v float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for v >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+	}
+	return fmt.Sprintf("%.1f %s/s", v, units[i])
+}
@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	for _, tc := range []struct {
+		values []float64
+		want   string
+	}{
+		{values: nil, want: ""},
+		{values: []float64{0, 0, 0}, want: "▁▁▁"},
+		{values: []float64{0, 7, 14}, want: "▁▅█"},
+		{values: []float64{1, 2, 3, 4, 5, 6, 7, 8}, want: "▂▃▄▅▅▆▇█"},
+	} {
+		if got := sparkline(tc.values); got != tc.want {
+			t.Errorf("sparkline(%v): want %q, got %q", tc.values, tc.want, got)
+		}
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	sample := func(recorded, requests, sjc, ams float64) realtimeResponseData {
+		return realtimeResponseData{
+			Recorded:   recorded,
+			Aggregated: statsResponseData{"requests": requests, "hits": requests / 2, "miss": requests / 2},
+			Datacenter: map[string]statsResponseData{
+				"SJC": {"requests": sjc},
+				"AMS": {"requests": ams},
+			},
+		}
+	}
+
+	d := dashboard{service: "123"}
+	d.add([]realtimeResponseData{sample(1700000000, 10, 4, 6), sample(1700000001, 20, 15, 5)})
+
+	var out bytes.Buffer
+	d.render(&out, 60, 24)
+	frame := out.String()
+	for _, want := range []string{
+		"Service ID: 123   Datacenter: all",
+		"Recorded:   2023-11-14T22:13:21Z",
+		"> Requests",
+		"20/s",
+		"50.00%",
+		"Requests by datacenter",
+	} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("frame doesn't contain %q:\n%s", want, frame)
+		}
+	}
+	// Datacenters are ordered by the latest value of the selected metric.
+	if strings.Index(frame, "SJC") > strings.Index(frame, "AMS") {
+		t.Errorf("want SJC listed before AMS:\n%s", frame)
+	}
+
+	d.handleKey("d")
+	if d.datacenter != "AMS" {
+		t.Errorf("want datacenter AMS, got %q", d.datacenter)
+	}
+	d.handleKey("D")
+	d.handleKey("D")
+	if d.datacenter != "SJC" {
+		t.Errorf("want datacenter SJC, got %q", d.datacenter)
+	}
+	if got := d.series(dashboardMetrics[0], d.datacenter, 10); len(got) != 2 || got[1] != 15 {
+		t.Errorf("unexpected SJC series: %v", got)
+	}
+	d.handleKey("a")
+	if d.datacenter != "" {
+		t.Errorf("want all datacenters, got %q", d.datacenter)
+	}
+
+	d.handleKey("m")
+	if name := dashboardMetrics[d.metric].name; name != "Hit ratio" {
+		t.Errorf("want Hit ratio metric, got %s", name)
+	}
+	d.handleKey("M")
+	d.handleKey("M")
+	if name := dashboardMetrics[d.metric].name; name != "Status 5xx" {
+		t.Errorf("want Status 5xx metric, got %s", name)
+	}
+
+	// Samples received while paused are only shown once resumed.
+	d.handleKey(" ")
+	d.add([]realtimeResponseData{sample(1700000002, 30, 10, 20)})
+	if len(d.samples) != 2 {
+		t.Errorf("want 2 samples while paused, got %d", len(d.samples))
+	}
+	d.handleKey(" ")
+	if len(d.samples) != 3 || d.pending != nil {
+		t.Errorf("want 3 samples once resumed, got %d (%d pending)", len(d.samples), len(d.pending))
+	}
+
+	for _, key := range []string{"q", "\x03", "\x1b"} {
+		if !d.handleKey(key) {
+			t.Errorf("want %q to quit", key)
+		}
+	}
+}
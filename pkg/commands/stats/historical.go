@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/fastly/go-fastly/v17/fastly"
 
@@ -18,6 +19,8 @@ const statusSuccess = "success"
 type HistoricalCommand struct {
 	argparser.Base
 
+	alertExec   string
+	alerts      []string
 	by          string
 	field       string
 	formatFlag  string
@@ -49,6 +52,8 @@ func NewHistoricalCommand(parent argparser.Registerer, g *global.Data) *Historic
 		Dst:         &c.serviceName.Value,
 	})
 
+	c.CmdClause.Flag("alert", "Exit with an error when an expression over stats fields holds, e.g. 'status_5xx/requests > 0.02 for 1h' (repeatable)").StringsVar(&c.alerts)
+	c.CmdClause.Flag("alert-exec", "Run a command (with the FASTLY_ALERT* environment variables set) instead of exiting when an --alert fires").StringVar(&c.alertExec)
 	c.CmdClause.Flag("field", "Filter to a single stats field (e.g. bandwidth, requests)").StringVar(&c.field)
 	c.CmdClause.Flag("from", "From time, accepted formats at https://fastly.dev/reference/api/metrics-stats/historical-stats").StringVar(&c.from)
 	c.CmdClause.Flag("to", "To time").StringVar(&c.to)
@@ -75,6 +80,12 @@ func (c *HistoricalCommand) Exec(_ io.Reader, out io.Writer) error {
 		argparser.DisplayServiceID(serviceID, flag, source, out)
 	}
 
+	watch, err := newAlertWatch(c.alerts, c.alertExec, serviceID, c.formatFlag == "json", c.Globals.Verbose(), out)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
 	input := fastly.GetStatsInput{
 		Service: fastly.ToPointer(serviceID),
 	}
@@ -135,6 +146,32 @@ func (c *HistoricalCommand) Exec(_ io.Reader, out io.Writer) error {
 		}
 	}
 
+	return checkBlocks(watch, envelope)
+}
+
+// historicalSpans maps each aggregation period to the window of time covered
+// by a stats block.
+var historicalSpans = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// checkBlocks evaluates the --alert expressions against each window, in order.
+func checkBlocks(watch *alertWatch, envelope statsResponse) error {
+	if len(watch.alerts) == 0 {
+		return nil
+	}
+	span := historicalSpans[envelope.Meta.By]
+	for _, block := range envelope.Data {
+		st, ok := block["start_time"].(float64)
+		if !ok {
+			return fmt.Errorf("failed to type assert '%v' to a float64", block["start_time"])
+		}
+		if err := watch.check(block, time.Unix(int64(st), 0), span); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -57,6 +57,31 @@ func TestHistorical(t *testing.T) {
 			API:        &mock.API{GetStatsJSONFn: getStatsJSONFieldOK},
 			WantOutput: `"bandwidth":123`,
 		},
+		{
+			Name:       "alert not breached",
+			Args:       "--service-id=123 --field=bandwidth --alert=bandwidth>1000",
+			API:        &mock.API{GetStatsJSONFn: getStatsJSONFieldOK},
+			WantOutput: "bandwidth: 123",
+		},
+		{
+			Name:       "alert breached",
+			Args:       "--service-id=123 --field=bandwidth --alert=bandwidth/2>50",
+			API:        &mock.API{GetStatsJSONFn: getStatsJSONFieldOK},
+			WantOutput: "bandwidth: 123",
+			WantError:  `alert "bandwidth/2>50" fired at 1970-01-01T00:00:00Z (value: 61.5)`,
+		},
+		{
+			Name:      "alert over an unknown field",
+			Args:      "--service-id=123 --field=bandwidth --alert=requests>1",
+			API:       &mock.API{GetStatsJSONFn: getStatsJSONFieldOK},
+			WantError: `error evaluating alert "requests>1": unknown stats field "requests"`,
+		},
+		{
+			Name:      "invalid alert",
+			Args:      "--service-id=123 --alert=bandwidth",
+			API:       &mock.API{GetStatsJSONFn: getStatsJSONOK},
+			WantError: `error parsing alert "bandwidth": expected a comparison`,
+		},
 	}
 
 	testutil.RunCLIScenarios(t, []string{root.CommandName, "historical"}, scenarios)
@@ -3,13 +3,18 @@ package stats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"time"
 
 	"github.com/fastly/go-fastly/v17/fastly"
+	"golang.org/x/term"
 
 	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/argparser"
+	fsterr "github.com/fastly/cli/pkg/errors"
 	"github.com/fastly/cli/pkg/global"
 	"github.com/fastly/cli/pkg/text"
 )
@@ -18,9 +23,13 @@ import (
 type RealtimeCommand struct {
 	argparser.Base
 
+	alertExec   string
+	alerts      []string
+	duration    time.Duration
 	formatFlag  string
 	jsonFlag    bool
 	serviceName argparser.OptionalServiceNameID
+	tui         bool
 }
 
 // NewRealtimeCommand is the "stats realtime" subcommand.
@@ -44,17 +53,41 @@ func NewRealtimeCommand(parent argparser.Registerer, g *global.Data) *RealtimeCo
 		Dst:         &c.serviceName.Value,
 	})
 
+	c.CmdClause.Flag("alert", "Exit with an error when an expression over stats fields holds, e.g. 'status_5xx/requests > 0.02 for 30s' (repeatable)").StringsVar(&c.alerts)
+	c.CmdClause.Flag("alert-exec", "Run a command (with the FASTLY_ALERT* environment variables set) instead of exiting when an --alert fires").StringVar(&c.alertExec)
+	c.CmdClause.Flag("duration", "Stop after the given duration (e.g. 5m), exiting successfully unless an --alert fired").DurationVar(&c.duration)
 	c.CmdClause.Flag("format", "Output format (json)").Hidden().EnumVar(&c.formatFlag, "json")
 	c.CmdClause.Flag("json", argparser.FlagJSONDesc).Short('j').BoolVar(&c.jsonFlag)
+	c.CmdClause.Flag("tui", "Show an interactive full-screen dashboard").BoolVar(&c.tui)
 
 	return &c
 }
 
 // Exec implements the command interface.
-func (c *RealtimeCommand) Exec(_ io.Reader, out io.Writer) error {
+func (c *RealtimeCommand) Exec(in io.Reader, out io.Writer) error {
 	if err := resolveJSONFormat(&c.formatFlag, c.jsonFlag, c.Globals); err != nil {
 		return err
 	}
+	if c.tui {
+		if c.formatFlag == "json" {
+			return fsterr.RemediationError{
+				Inner:       errors.New("invalid flag combination, --tui and --json"),
+				Remediation: "Use either --tui or --json, not both.",
+			}
+		}
+		if len(c.alerts) > 0 || c.duration > 0 {
+			return fsterr.RemediationError{
+				Inner:       errors.New("invalid flag combination, --tui can't be used with --alert or --duration"),
+				Remediation: "Use --alert and --duration without --tui, so they can be evaluated unattended.",
+			}
+		}
+		if !text.IsTTY(in) || !text.IsTTY(out) {
+			return fsterr.RemediationError{
+				Inner:       errors.New("--tui requires an interactive terminal"),
+				Remediation: "Run the command in a terminal, or drop --tui to print stats as text.",
+			}
+		}
+	}
 
 	serviceID, source, flag, err := argparser.ServiceID(c.serviceName, *c.Globals.Manifest, c.Globals.APIClient, c.Globals.ErrLog)
 	if err != nil {
@@ -64,9 +97,28 @@ func (c *RealtimeCommand) Exec(_ io.Reader, out io.Writer) error {
 		argparser.DisplayServiceID(serviceID, flag, source, out)
 	}
 
-	switch c.formatFlag {
-	case "json":
-		if err := loopJSON(c.Globals.RTSClient, serviceID, out); err != nil {
+	watch, err := newAlertWatch(c.alerts, c.alertExec, serviceID, c.formatFlag == "json", c.Globals.Verbose(), out)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	var until time.Time
+	if c.duration > 0 {
+		until = time.Now().Add(c.duration)
+	}
+
+	switch {
+	case c.tui:
+		f, _ := in.(*os.File)
+		if err := loopTUI(c.Globals.RTSClient, serviceID, f, out); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]any{
+				"Service ID": serviceID,
+			})
+			return err
+		}
+
+	case c.formatFlag == "json":
+		if err := loopJSON(c.Globals.RTSClient, serviceID, watch, until, out); err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]any{
 				"Service ID": serviceID,
 			})
@@ -74,7 +126,7 @@ func (c *RealtimeCommand) Exec(_ io.Reader, out io.Writer) error {
 		}
 
 	default:
-		if err := loopText(c.Globals.RTSClient, serviceID, out); err != nil {
+		if err := loopText(c.Globals.RTSClient, serviceID, watch, until, out); err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]any{
 				"Service ID": serviceID,
 			})
@@ -85,9 +137,15 @@ func (c *RealtimeCommand) Exec(_ io.Reader, out io.Writer) error {
 	return nil
 }
 
-func loopJSON(client api.RealtimeStatsInterface, service string, out io.Writer) error {
+// realtimeSpan is the window of time covered by each realtime stats block.
+const realtimeSpan = time.Second
+
+func loopJSON(client api.RealtimeStatsInterface, service string, watch *alertWatch, until time.Time, out io.Writer) error {
 	var timestamp uint64
 	for {
+		if !until.IsZero() && time.Now().After(until) {
+			return nil
+		}
 		var envelope struct {
 			Timestamp uint64            `json:"timestamp"`
 			Data      []json.RawMessage `json:"data"`
@@ -109,13 +167,26 @@ func loopJSON(client api.RealtimeStatsInterface, service string, out io.Writer)
 				return fmt.Errorf("error: unable to write data to stdout: %w", err)
 			}
 			text.Break(out)
+
+			if len(watch.alerts) > 0 {
+				var block realtimeResponseData
+				if err := json.Unmarshal(data, &block); err != nil {
+					return fmt.Errorf("error parsing stats: %w", err)
+				}
+				if err := watch.check(block.Aggregated, time.Unix(int64(block.Recorded), 0), realtimeSpan); err != nil {
+					return err
+				}
+			}
 		}
 	}
 }
 
-func loopText(client api.RealtimeStatsInterface, service string, out io.Writer) error {
+func loopText(client api.RealtimeStatsInterface, service string, watch *alertWatch, until time.Time, out io.Writer) error {
 	var timestamp uint64
 	for {
+		if !until.IsZero() && time.Now().After(until) {
+			return nil
+		}
 		var envelope realtimeResponse
 
 		err := client.GetRealtimeStatsJSON(context.TODO(), &fastly.GetRealtimeStatsInput{
@@ -141,6 +212,102 @@ func loopText(client api.RealtimeStatsInterface, service string, out io.Writer)
 				text.Error(out, "formatting stats: %w", err)
 				continue
 			}
+
+			if err := watch.check(agg, time.Unix(int64(block.Recorded), 0), realtimeSpan); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// loopTUI shows the realtime stats in a full-screen dashboard, until the user
+// quits. The terminal is switched to raw mode so that key presses are read
+// as they're typed.
+func loopTUI(client api.RealtimeStatsInterface, service string, in *os.File, out io.Writer) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("error configuring terminal: %w", err)
+	}
+	defer func() {
+		_ = term.Restore(int(in.Fd()), state)
+	}()
+
+	// Switch to the alternate screen and hide the cursor, so that the
+	// dashboard doesn't clobber the scrollback.
+	_, _ = io.WriteString(out, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		_, _ = io.WriteString(out, "\x1b[?25h\x1b[?1049l")
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	envelopes := make(chan realtimeResponse)
+	failures := make(chan error)
+	go func() {
+		var timestamp uint64
+		for {
+			var envelope realtimeResponse
+			err := client.GetRealtimeStatsJSON(ctx, &fastly.GetRealtimeStatsInput{
+				ServiceID: service,
+				Timestamp: timestamp,
+			}, &envelope)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				select {
+				case failures <- err:
+				case <-ctx.Done():
+					return
+				}
+				// Back off rather than hammering the API while it's failing.
+				time.Sleep(time.Second)
+				continue
+			}
+			timestamp = envelope.Timestamp
+			select {
+			case envelopes <- envelope:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			select {
+			case keys <- string(buf[:n]):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	d := dashboard{service: service}
+	for {
+		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		d.render(out, width, height)
+
+		select {
+		case envelope := <-envelopes:
+			d.add(envelope.Data)
+		case err := <-failures:
+			d.err = err
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return nil
+			}
 		}
 	}
 }
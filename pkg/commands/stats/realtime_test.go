@@ -31,6 +31,18 @@ func TestRealtime(t *testing.T) {
 			api:       mock.API{},
 			wantError: "invalid flag combination",
 		},
+		{
+			name:      "tui json combo",
+			args:      args("stats realtime --service-id 123 --tui --json"),
+			api:       mock.API{},
+			wantError: "invalid flag combination, --tui and --json",
+		},
+		{
+			name:      "tui without a terminal",
+			args:      args("stats realtime --service-id 123 --tui"),
+			api:       mock.API{},
+			wantError: "--tui requires an interactive terminal",
+		},
 	}
 	for _, tc := range scenarios {
 		t.Run(tc.name, func(t *testing.T) {
@@ -458,6 +458,9 @@ type Environment struct {
 	APIEndpoint string
 	// APIToken is the env var we look in for the Fastly API token.
 	APIToken string
+	// BuildCacheDir is the directory where `compute build` caches build
+	// artifacts.
+	BuildCacheDir string
 	// DebugMode indicates to the CLI it can display debug information.
 	DebugMode string
 	// UseSSO indicates if user wants to use SSO/OAuth token flow.
@@ -477,6 +480,7 @@ func (e *Environment) Read(state map[string]string) {
 	e.AccountEndpoint = state[env.AccountEndpoint]
 	e.APIEndpoint = state[env.APIEndpoint]
 	e.APIToken = state[env.APIToken]
+	e.BuildCacheDir = state[env.BuildCacheDir]
 	e.DebugMode = state[env.DebugMode]
 	e.UseSSO = state[env.UseSSO]
 	e.UserAgentExtension = state[env.UserAgentExtension]
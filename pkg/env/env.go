@@ -24,6 +24,10 @@ const (
 	// #nosec
 	APIToken = "FASTLY_API_TOKEN"
 
+	// BuildCacheDir is the env var we look in for the directory where
+	// `compute build` caches build artifacts.
+	BuildCacheDir = "FASTLY_BUILD_CACHE_DIR"
+
 	// CustomerID is the env var we look in for a Customer ID.
 	CustomerID = "FASTLY_CUSTOMER_ID"
 
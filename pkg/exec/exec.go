@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -34,6 +35,9 @@ type Streaming struct {
 	// Output is where to write output (e.g. stdout)
 	Output io.Writer
 	// Process is the process to terminal if signal received.
+	//
+	// NOTE: It's set by Exec and read by Signal, which can be called from
+	// other goroutines, so both hold processMu.
 	Process *os.Process
 	// SignalCh is a channel handling signal events.
 	SignalCh chan os.Signal
@@ -45,6 +49,8 @@ type Streaming struct {
 	Timeout time.Duration
 	// Verbose outputs additional information.
 	Verbose bool
+
+	processMu sync.Mutex
 }
 
 // MonitorSignals spawns a goroutine that configures signal handling so that
@@ -127,7 +133,9 @@ func (s *Streaming) Exec() error {
 	// Store off os.Process so it can be killed by signal listener.
 	//
 	// NOTE: argparser.Process is nil until exec.Start() returns successfully.
+	s.processMu.Lock()
 	s.Process = cmd.Process
+	s.processMu.Unlock()
 
 	if err := cmd.Wait(); err != nil {
 		// IMPORTANT: We MUST wrap the original error.
@@ -160,6 +168,8 @@ func (s *Streaming) Exec() error {
 
 // Signal enables spawned subprocess to accept given signal.
 func (s *Streaming) Signal(sig os.Signal) error {
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
 	if s.Process != nil {
 		err := s.Process.Signal(sig)
 		if err != nil {
@@ -0,0 +1,74 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Budget limits the size of the build output. The limits are checked by
+// `compute build` and `compute deploy`, so that a package that has grown too
+// large is caught before it's uploaded.
+type Budget struct {
+	// MaxPackageSize is the maximum size of the package archive.
+	MaxPackageSize ByteSize `toml:"max_package_size,omitempty"`
+	// MaxWasmSize is the maximum size of the (uncompressed) Wasm binary.
+	MaxWasmSize ByteSize `toml:"max_wasm_size,omitempty"`
+}
+
+// ByteSize is a size in bytes. In the manifest it can be an integer number of
+// bytes, or a string with a decimal (KB, MB, GB) or binary (KiB, MiB, GiB)
+// unit suffix, e.g. "8MB" or "512 KiB".
+type ByteSize int64
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GIB", 1 << 30},
+	{"MIB", 1 << 20},
+	{"KIB", 1 << 10},
+	{"GB", 1000 * 1000 * 1000},
+	{"MB", 1000 * 1000},
+	{"KB", 1000},
+	{"B", 1},
+}
+
+// UnmarshalText parses a size with an optional unit suffix.
+func (s *ByteSize) UnmarshalText(txt []byte) error {
+	v := strings.ToUpper(strings.TrimSpace(string(txt)))
+	multiplier := int64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			multiplier = u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid size %q: expected a number of bytes or a size such as \"8MB\"", string(txt))
+	}
+	*s = ByteSize(n * float64(multiplier))
+	return nil
+}
+
+// MarshalText formats the size using the largest unit it's a whole multiple of.
+func (s ByteSize) MarshalText() ([]byte, error) {
+	for _, u := range byteSizeUnits {
+		if s > 0 && int64(s)%u.size == 0 {
+			suffix := u.suffix
+			if strings.HasSuffix(suffix, "IB") {
+				suffix = suffix[:1] + "iB"
+			}
+			return fmt.Appendf(nil, "%d%s", int64(s)/u.size, suffix), nil
+		}
+	}
+	return []byte("0"), nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s ByteSize) String() string {
+	b, _ := s.MarshalText()
+	return string(b)
+}
@@ -0,0 +1,66 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+)
+
+func TestBudget_UnmarshalTOML(t *testing.T) {
+	inputTOML := `
+[scripts.budget]
+max_wasm_size = "8 MiB"
+max_package_size = 2500000
+`
+	var f struct {
+		Scripts Scripts `toml:"scripts"`
+	}
+	if err := toml.NewDecoder(strings.NewReader(inputTOML)).Decode(&f); err != nil {
+		t.Fatalf("Failed to parse TOML: %v", err)
+	}
+	if got, want := f.Scripts.Budget.MaxWasmSize, ByteSize(8<<20); got != want {
+		t.Errorf("want max_wasm_size %d, got %d", want, got)
+	}
+	if got, want := f.Scripts.Budget.MaxPackageSize, ByteSize(2500000); got != want {
+		t.Errorf("want max_package_size %d, got %d", want, got)
+	}
+
+	data, err := toml.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`max_wasm_size = "8MiB"`, `max_package_size = "2500KB"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("want the marshalled budget to contain %s, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestByteSize_UnmarshalText(t *testing.T) {
+	for input, want := range map[string]ByteSize{
+		"512":     512,
+		"512B":    512,
+		"1.5kb":   1500,
+		"2 KiB":   2048,
+		"100MB":   100000000,
+		"1GiB":    1 << 30,
+		" 3 mb  ": 3000000,
+	} {
+		var s ByteSize
+		if err := s.UnmarshalText([]byte(input)); err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if s != want {
+			t.Errorf("%q: want %d, got %d", input, want, s)
+		}
+	}
+
+	for _, input := range []string{"", "MB", "-1", "10 parsecs"} {
+		var s ByteSize
+		if err := s.UnmarshalText([]byte(input)); err == nil {
+			t.Errorf("%q: want an error", input)
+		}
+	}
+}
@@ -29,6 +29,8 @@ type File struct {
 	Description string `toml:"description"`
 	// Language is the programming language used for the project.
 	Language string `toml:"language"`
+	// Languages defines language plugins, keyed by the name used in Language.
+	Languages map[string]LanguagePlugin `toml:"languages,omitempty"`
 	// LocalServer describes the configuration for the local server built into the Fastly CLI.
 	LocalServer LocalServer `toml:"local_server,omitempty"`
 	// ManifestVersion is the manifest schema version number.
@@ -143,22 +145,24 @@ func (f *File) MarshalTOML() ([]byte, error) {
 	}
 
 	out := struct {
-		Authors         []string `toml:"authors"`
-		ClonedFrom      string   `toml:"cloned_from,omitempty"`
-		Description     string   `toml:"description"`
-		Language        string   `toml:"language"`
-		LocalServer     any      `toml:"local_server"` // override this field
-		ManifestVersion Version  `toml:"manifest_version"`
-		Name            string   `toml:"name"`
-		Profile         string   `toml:"profile,omitempty"`
-		Scripts         Scripts  `toml:"scripts,omitempty"`
-		ServiceID       string   `toml:"service_id"`
-		Setup           Setup    `toml:"setup,omitempty"`
+		Authors         []string                  `toml:"authors"`
+		ClonedFrom      string                    `toml:"cloned_from,omitempty"`
+		Description     string                    `toml:"description"`
+		Language        string                    `toml:"language"`
+		Languages       map[string]LanguagePlugin `toml:"languages,omitempty"`
+		LocalServer     any                       `toml:"local_server"` // override this field
+		ManifestVersion Version                   `toml:"manifest_version"`
+		Name            string                    `toml:"name"`
+		Profile         string                    `toml:"profile,omitempty"`
+		Scripts         Scripts                   `toml:"scripts,omitempty"`
+		ServiceID       string                    `toml:"service_id"`
+		Setup           Setup                     `toml:"setup,omitempty"`
 	}{
 		Authors:         f.Authors,
 		ClonedFrom:      f.ClonedFrom,
 		Description:     f.Description,
 		Language:        f.Language,
+		Languages:       f.Languages,
 		LocalServer:     localServer,
 		ManifestVersion: f.ManifestVersion,
 		Name:            f.Name,
@@ -364,6 +368,8 @@ func appendSpecRef(w io.Writer) error {
 
 // Scripts represents build configuration.
 type Scripts struct {
+	// Budget limits the size of the Wasm binary and package.
+	Budget Budget `toml:"budget,omitempty"`
 	// Build is a custom build script.
 	Build string `toml:"build,omitempty"`
 	// EnvFile is a path to a file containing build related environment variables.
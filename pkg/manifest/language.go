@@ -0,0 +1,52 @@
+package manifest
+
+// LanguagePlugin describes a language that isn't built into the CLI, so that
+// `compute init` and `compute build` can treat it like a built-in language.
+//
+// A plugin is defined in a [languages.<name>] table of the manifest, or by a
+// fastly-lang-<name> executable on the PATH that prints the same fields as
+// JSON when called with a `describe` argument. The manifest's `language` field
+// selects the plugin by name.
+//
+// Commands are run with the system shell from the project directory, with the
+// [scripts.env_vars] set.
+type LanguagePlugin struct {
+	// Build is the default build command, used when [scripts.build] isn't set.
+	// It must write the Wasm binary to ./bin/main.wasm.
+	Build string `toml:"build,omitempty" json:"build,omitempty"`
+	// DisplayName is the name of the language shown by `compute init`.
+	DisplayName string `toml:"display_name,omitempty" json:"display_name,omitempty"`
+	// ListDependencies is a command that prints the project dependencies as a
+	// JSON object of name to version, which is recorded in the package metadata.
+	ListDependencies string `toml:"list_dependencies,omitempty" json:"list_dependencies,omitempty"`
+	// PostBuild are commands run in order after the build, before any
+	// [scripts.post_build], e.g. to optimise the Wasm binary.
+	PostBuild []string `toml:"post_build,omitempty" json:"post_build,omitempty"`
+	// SourceDirectory is the directory of the source code, which is added to
+	// the package by `compute build --include-source`.
+	SourceDirectory string `toml:"source_directory,omitempty" json:"source_directory,omitempty"`
+	// StarterKit is the package template used by `compute init` (a Git
+	// repository URL or a URL of a .zip/.tar.gz file).
+	StarterKit string `toml:"starter_kit,omitempty" json:"starter_kit,omitempty"`
+	// Toolchain describes how to check the installed toolchain.
+	Toolchain LanguagePluginToolchain `toml:"toolchain,omitempty" json:"toolchain,omitzero"`
+	// VerifyDependencies is a command that fails (with a message explaining how
+	// to install them) when the project dependencies aren't installed.
+	VerifyDependencies string `toml:"verify_dependencies,omitempty" json:"verify_dependencies,omitempty"`
+}
+
+// LanguagePluginToolchain describes how to check the toolchain of a language
+// plugin is installed and has a supported version.
+type LanguagePluginToolchain struct {
+	// Constraint is the supported toolchain version (a range is expected, e.g.
+	// >= 0.13.0 < 0.15.0).
+	Constraint string `toml:"constraint,omitempty" json:"constraint,omitempty"`
+	// Install explains how to install the toolchain, when it's not found.
+	Install string `toml:"install,omitempty" json:"install,omitempty"`
+	// Version is a command that prints the toolchain version.
+	Version string `toml:"version,omitempty" json:"version,omitempty"`
+	// VersionPattern is a regular expression extracting the version from the
+	// output of the Version command, using a capture group named `version`.
+	// The first semantic version in the output is used by default.
+	VersionPattern string `toml:"version_pattern,omitempty" json:"version_pattern,omitempty"`
+}
@@ -19,10 +19,38 @@ type LocalServer struct {
 
 // LocalBackend represents a backend to be mocked by the local testing server.
 type LocalBackend struct {
-	URL          string `toml:"url"`
-	OverrideHost string `toml:"override_host,omitempty"`
-	CertHost     string `toml:"cert_host,omitempty"`
-	UseSNI       bool   `toml:"use_sni,omitempty"`
+	URL          string            `toml:"url"`
+	OverrideHost string            `toml:"override_host,omitempty"`
+	CertHost     string            `toml:"cert_host,omitempty"`
+	UseSNI       bool              `toml:"use_sni,omitempty"`
+	Mock         *LocalBackendMock `toml:"mock,omitempty"`
+}
+
+// LocalBackendMock represents canned responses that the CLI serves in place of
+// a backend, so the local testing server doesn't need the backend to be
+// reachable.
+type LocalBackendMock struct {
+	Routes []LocalBackendMockRoute `toml:"routes"`
+}
+
+// LocalBackendMockRoute represents a canned response to requests matching a
+// method and path pattern.
+type LocalBackendMockRoute struct {
+	// Method is the request method to match (default: any method).
+	Method string `toml:"method,omitempty"`
+	// Path is a pattern matched against the request path (e.g. /api/*).
+	Path string `toml:"path"`
+	// Status is the response status code (default: 200).
+	Status int `toml:"status,omitempty"`
+	// Headers are the response headers.
+	Headers map[string]string `toml:"headers,omitempty"`
+	// Body is the response body.
+	Body string `toml:"body,omitempty"`
+	// BodyFile is a file containing the response body, relative to the
+	// manifest.
+	BodyFile string `toml:"body_file,omitempty"`
+	// Latency is how long to wait before responding (e.g. 250ms).
+	Latency string `toml:"latency,omitempty"`
 }
 
 // LocalConfigStore represents a config store to be mocked by the local testing server.
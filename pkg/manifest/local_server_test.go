@@ -165,3 +165,48 @@ my-secret-store = "not-a-valid-entry"
 		})
 	}
 }
+
+func TestLocalBackendMock_UnmarshalTOML(t *testing.T) {
+	inputTOML := `
+[backends.origin]
+url = "https://example.com"
+
+[[backends.origin.mock.routes]]
+method = "GET"
+path = "/api/*"
+headers = { content-type = "application/json" }
+body_file = "fixtures/api.json"
+latency = "250ms"
+
+[[backends.origin.mock.routes]]
+path = "/missing"
+status = 404
+body = "not found"
+`
+	var m struct {
+		Backends map[string]LocalBackend `toml:"backends"`
+	}
+	if err := toml.NewDecoder(strings.NewReader(inputTOML)).Decode(&m); err != nil {
+		t.Fatalf("Failed to parse TOML: %v", err)
+	}
+
+	expected := &LocalBackendMock{
+		Routes: []LocalBackendMockRoute{
+			{
+				Method:   "GET",
+				Path:     "/api/*",
+				Headers:  map[string]string{"content-type": "application/json"},
+				BodyFile: "fixtures/api.json",
+				Latency:  "250ms",
+			},
+			{
+				Path:   "/missing",
+				Status: 404,
+				Body:   "not found",
+			},
+		},
+	}
+	if got := m.Backends["origin"].Mock; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Mismatch!\nGot:  %+v\nWant: %+v", got, expected)
+	}
+}
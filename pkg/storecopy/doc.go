@@ -0,0 +1,3 @@
+// Package storecopy contains the logic shared by the commands that copy the
+// keys of one store to another.
+package storecopy
@@ -0,0 +1,191 @@
+package storecopy
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/fastly/kingpin"
+
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// DefaultConcurrency is the default number of keys copied concurrently.
+const DefaultConcurrency int = 50
+
+// Options are the flags shared by the copy commands.
+type Options struct {
+	Concurrency int
+	DryRun      bool
+	From        string
+	Prefix      string
+	Rename      string
+	To          string
+}
+
+// RegisterFlags registers the flags of the options on a command.
+func (o *Options) RegisterFlags(cmd *kingpin.CmdClause) {
+	// Required.
+	cmd.Flag("from", "ID of the store to copy the keys from").Required().StringVar(&o.From)
+	cmd.Flag("to", "ID of the store to copy the keys to (existing keys are overwritten)").Required().StringVar(&o.To)
+
+	// Optional.
+	cmd.Flag("concurrency", "The number of keys to copy concurrently").Default(strconv.Itoa(DefaultConcurrency)).Short('r').IntVar(&o.Concurrency)
+	cmd.Flag("dry-run", "Print the keys that would be copied without changing the destination store").BoolVar(&o.DryRun)
+	cmd.Flag("prefix", "Only copy the keys that match this prefix").StringVar(&o.Prefix)
+	cmd.Flag("rename", "A Go template for the destination key, where {{.Key}} is the source key and {{.Name}} is the source key without --prefix (e.g. 'prod_{{.Name}}'). The functions lower, upper, replace, trimPrefix and trimSuffix are available").StringVar(&o.Rename)
+}
+
+// Pair is a source key and the destination key it's copied to.
+type Pair struct {
+	From string
+	To   string
+}
+
+// Plan returns the keys (sorted) that match the prefix, paired with their
+// destination key.
+func (o *Options) Plan(keys []string) ([]Pair, error) {
+	if o.Concurrency < 1 {
+		return nil, fmt.Errorf("invalid --concurrency value: %d", o.Concurrency)
+	}
+	if o.From == o.To && o.Rename == "" {
+		return nil, fsterr.RemediationError{
+			Inner:       fmt.Errorf("the source and destination stores are the same"),
+			Remediation: "Set --rename to copy the keys to new keys within the same store.",
+		}
+	}
+
+	var tmpl *template.Template
+	if o.Rename != "" {
+		var err error
+		tmpl, err = template.New("rename").Funcs(template.FuncMap{
+			"lower":      strings.ToLower,
+			"replace":    strings.ReplaceAll,
+			"trimPrefix": strings.TrimPrefix,
+			"trimSuffix": strings.TrimSuffix,
+			"upper":      strings.ToUpper,
+		}).Option("missingkey=error").Parse(o.Rename)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing --rename: %w", err)
+		}
+	}
+
+	var (
+		pairs []Pair
+		seen  = make(map[string]string)
+	)
+	for _, key := range slices.Sorted(slices.Values(keys)) {
+		name, ok := strings.CutPrefix(key, o.Prefix)
+		if !ok {
+			continue
+		}
+		to := key
+		if tmpl != nil {
+			var b strings.Builder
+			err := tmpl.Execute(&b, struct{ Key, Name string }{key, name})
+			if err != nil {
+				return nil, fmt.Errorf("error renaming the key '%s': %w", key, err)
+			}
+			to = b.String()
+		}
+		if to == "" {
+			return nil, fmt.Errorf("the key '%s' is renamed to an empty key", key)
+		}
+		if other, ok := seen[to]; ok {
+			return nil, fmt.Errorf("the keys '%s' and '%s' are both renamed to '%s'", other, key, to)
+		}
+		seen[to] = key
+		pairs = append(pairs, Pair{From: key, To: to})
+	}
+
+	return pairs, nil
+}
+
+// PrintPlan prints the keys that would be copied.
+func PrintPlan(out io.Writer, pairs []Pair) {
+	for _, p := range pairs {
+		if p.From == p.To {
+			text.Output(out, "%s", p.From)
+		} else {
+			text.Output(out, "%s -> %s", p.From, p.To)
+		}
+	}
+	text.Break(out)
+	text.Output(out, "%d keys would be copied", len(pairs))
+}
+
+// Copy calls fn for each pair, using a pool of goroutines, and reports the
+// progress and any failures.
+func Copy(out io.Writer, o Options, pairs []Pair, fn func(Pair) error) error {
+	spinner, err := text.NewSpinner(out)
+	if err != nil {
+		return err
+	}
+	if err := spinner.Start(); err != nil {
+		return err
+	}
+	msg := "%s %d of %d keys"
+	spinner.Message(fmt.Sprintf(msg, "Copying", 0, len(pairs)) + "...")
+
+	type failure struct {
+		key string
+		err error
+	}
+
+	var (
+		copied   atomic.Uint64
+		failures []failure
+		// NOTE: mu protects access to the 'failures' shared resource.
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	pairsCh := make(chan Pair)
+	for range min(o.Concurrency, len(pairs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range pairsCh {
+				if err := fn(p); err != nil {
+					mu.Lock()
+					failures = append(failures, failure{p.From, err})
+					mu.Unlock()
+					continue
+				}
+				spinner.Message(fmt.Sprintf(msg, "Copying", copied.Add(1), len(pairs)) + "...")
+			}
+		}()
+	}
+	for _, p := range pairs {
+		pairsCh <- p
+	}
+	close(pairsCh)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		spinner.StopFailMessage(fmt.Sprintf(msg, "Copied", copied.Load(), len(pairs)))
+		if err := spinner.StopFail(); err != nil {
+			return fmt.Errorf("failed to stop spinner: %w", err)
+		}
+		slices.SortFunc(failures, func(a, b failure) int {
+			return strings.Compare(a.key, b.key)
+		})
+		text.Break(out)
+		for _, f := range failures {
+			text.Output(out, "Key: %s\nError: %s\n", f.key, f.err.Error())
+		}
+		return fmt.Errorf("failed to copy %d keys (see error log above ⬆️)", len(failures))
+	}
+	spinner.StopMessage(fmt.Sprintf(msg, "Copied", copied.Load(), len(pairs)))
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("failed to stop spinner: %w", err)
+	}
+
+	text.Success(out, "\nCopied %d keys from store '%s' to '%s'", len(pairs), o.From, o.To)
+	return nil
+}
@@ -0,0 +1,85 @@
+package storecopy_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/storecopy"
+)
+
+func TestPlan(t *testing.T) {
+	keys := []string{"staging/b", "staging/a", "other", "staging/C"}
+
+	for _, tc := range []struct {
+		name    string
+		opts    storecopy.Options
+		want    []storecopy.Pair
+		wantErr string
+	}{
+		{
+			name: "all keys",
+			opts: storecopy.Options{From: "a", To: "b"},
+			want: []storecopy.Pair{{"other", "other"}, {"staging/C", "staging/C"}, {"staging/a", "staging/a"}, {"staging/b", "staging/b"}},
+		},
+		{
+			name: "prefix",
+			opts: storecopy.Options{From: "a", To: "b", Prefix: "staging/"},
+			want: []storecopy.Pair{{"staging/C", "staging/C"}, {"staging/a", "staging/a"}, {"staging/b", "staging/b"}},
+		},
+		{
+			name: "rename",
+			opts: storecopy.Options{From: "a", To: "b", Prefix: "staging/", Rename: "prod/{{.Name | lower}}"},
+			want: []storecopy.Pair{{"staging/C", "prod/c"}, {"staging/a", "prod/a"}, {"staging/b", "prod/b"}},
+		},
+		{
+			name: "rename within the same store",
+			opts: storecopy.Options{From: "a", To: "a", Rename: `{{replace .Key "staging" "prod"}}`},
+			want: []storecopy.Pair{{"other", "other"}, {"staging/C", "prod/C"}, {"staging/a", "prod/a"}, {"staging/b", "prod/b"}},
+		},
+		{
+			name:    "same store",
+			opts:    storecopy.Options{From: "a", To: "a"},
+			wantErr: "the source and destination stores are the same",
+		},
+		{
+			name:    "conflicting keys",
+			opts:    storecopy.Options{From: "a", To: "b", Prefix: "staging/", Rename: "prod"},
+			wantErr: "the keys 'staging/C' and 'staging/a' are both renamed to 'prod'",
+		},
+		{
+			name:    "invalid template",
+			opts:    storecopy.Options{From: "a", To: "b", Rename: "{{.Name"},
+			wantErr: "error parsing --rename",
+		},
+		{
+			name:    "unknown field",
+			opts:    storecopy.Options{From: "a", To: "b", Rename: "{{.Value}}"},
+			wantErr: "error renaming the key",
+		},
+		{
+			name:    "empty key",
+			opts:    storecopy.Options{From: "a", To: "b", Prefix: "other", Rename: "{{.Name}}"},
+			wantErr: "the key 'other' is renamed to an empty key",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.opts.Concurrency == 0 {
+				tc.opts.Concurrency = 1
+			}
+			pairs, err := tc.opts.Plan(keys)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want error %q, have %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(pairs, tc.want) {
+				t.Errorf("want %v, have %v", tc.want, pairs)
+			}
+		})
+	}
+}
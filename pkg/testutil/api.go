@@ -92,6 +92,7 @@ import (
 
 	authcmd "github.com/fastly/cli/pkg/commands/auth"
 	"github.com/fastly/cli/pkg/commands/whoami"
+	"github.com/fastly/cli/pkg/mock"
 )
 
 // Err represents a generic error.
@@ -345,3 +346,198 @@ func GetServiceDetails(_ context.Context, i *fastly.GetServiceDetailsInput) (*fa
 
 	return detail, nil
 }
+
+// EmptyServiceResources sets every versioned resource list function that the
+// given mock doesn't already define to one that returns no resources.
+//
+// It's useful when testing commands that read a whole service version (such
+// as `service export`), as the test then only needs to mock the resources it
+// cares about.
+func EmptyServiceResources(m *mock.API) *mock.API {
+	if m.ListConditionsFn == nil {
+		m.ListConditionsFn = func(context.Context, *fastly.ListConditionsInput) ([]*fastly.Condition, error) {
+			return nil, nil
+		}
+	}
+	if m.ListHealthChecksFn == nil {
+		m.ListHealthChecksFn = func(context.Context, *fastly.ListHealthChecksInput) ([]*fastly.HealthCheck, error) {
+			return nil, nil
+		}
+	}
+	if m.ListBackendsFn == nil {
+		m.ListBackendsFn = func(context.Context, *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+			return nil, nil
+		}
+	}
+	if m.ListDomainsFn == nil {
+		m.ListDomainsFn = func(context.Context, *fastly.ListDomainsInput) ([]*fastly.Domain, error) {
+			return nil, nil
+		}
+	}
+	if m.ListDictionariesFn == nil {
+		m.ListDictionariesFn = func(context.Context, *fastly.ListDictionariesInput) ([]*fastly.Dictionary, error) {
+			return nil, nil
+		}
+	}
+	if m.ListACLsFn == nil {
+		m.ListACLsFn = func(context.Context, *fastly.ListACLsInput) ([]*fastly.ACL, error) {
+			return nil, nil
+		}
+	}
+	if m.ListVCLsFn == nil {
+		m.ListVCLsFn = func(context.Context, *fastly.ListVCLsInput) ([]*fastly.VCL, error) {
+			return nil, nil
+		}
+	}
+	if m.ListSnippetsFn == nil {
+		m.ListSnippetsFn = func(context.Context, *fastly.ListSnippetsInput) ([]*fastly.Snippet, error) {
+			return nil, nil
+		}
+	}
+	if m.ListERLsFn == nil {
+		m.ListERLsFn = func(context.Context, *fastly.ListERLsInput) ([]*fastly.ERL, error) {
+			return nil, nil
+		}
+	}
+	if m.ListResourcesFn == nil {
+		m.ListResourcesFn = func(context.Context, *fastly.ListResourcesInput) ([]*fastly.Resource, error) {
+			return nil, nil
+		}
+	}
+	if m.ListBlobStoragesFn == nil {
+		m.ListBlobStoragesFn = func(context.Context, *fastly.ListBlobStoragesInput) ([]*fastly.BlobStorage, error) {
+			return nil, nil
+		}
+	}
+	if m.ListBigQueriesFn == nil {
+		m.ListBigQueriesFn = func(context.Context, *fastly.ListBigQueriesInput) ([]*fastly.BigQuery, error) {
+			return nil, nil
+		}
+	}
+	if m.ListCloudfilesFn == nil {
+		m.ListCloudfilesFn = func(context.Context, *fastly.ListCloudfilesInput) ([]*fastly.Cloudfiles, error) {
+			return nil, nil
+		}
+	}
+	if m.ListDatadogFn == nil {
+		m.ListDatadogFn = func(context.Context, *fastly.ListDatadogInput) ([]*fastly.Datadog, error) {
+			return nil, nil
+		}
+	}
+	if m.ListDigitalOceansFn == nil {
+		m.ListDigitalOceansFn = func(context.Context, *fastly.ListDigitalOceansInput) ([]*fastly.DigitalOcean, error) {
+			return nil, nil
+		}
+	}
+	if m.ListElasticsearchFn == nil {
+		m.ListElasticsearchFn = func(context.Context, *fastly.ListElasticsearchInput) ([]*fastly.Elasticsearch, error) {
+			return nil, nil
+		}
+	}
+	if m.ListFTPsFn == nil {
+		m.ListFTPsFn = func(context.Context, *fastly.ListFTPsInput) ([]*fastly.FTP, error) {
+			return nil, nil
+		}
+	}
+	if m.ListGCSsFn == nil {
+		m.ListGCSsFn = func(context.Context, *fastly.ListGCSsInput) ([]*fastly.GCS, error) {
+			return nil, nil
+		}
+	}
+	if m.ListPubsubsFn == nil {
+		m.ListPubsubsFn = func(context.Context, *fastly.ListPubsubsInput) ([]*fastly.Pubsub, error) {
+			return nil, nil
+		}
+	}
+	if m.ListGrafanaCloudLogsFn == nil {
+		m.ListGrafanaCloudLogsFn = func(context.Context, *fastly.ListGrafanaCloudLogsInput) ([]*fastly.GrafanaCloudLogs, error) {
+			return nil, nil
+		}
+	}
+	if m.ListHerokusFn == nil {
+		m.ListHerokusFn = func(context.Context, *fastly.ListHerokusInput) ([]*fastly.Heroku, error) {
+			return nil, nil
+		}
+	}
+	if m.ListHoneycombsFn == nil {
+		m.ListHoneycombsFn = func(context.Context, *fastly.ListHoneycombsInput) ([]*fastly.Honeycomb, error) {
+			return nil, nil
+		}
+	}
+	if m.ListHTTPSFn == nil {
+		m.ListHTTPSFn = func(context.Context, *fastly.ListHTTPSInput) ([]*fastly.HTTPS, error) {
+			return nil, nil
+		}
+	}
+	if m.ListKafkasFn == nil {
+		m.ListKafkasFn = func(context.Context, *fastly.ListKafkasInput) ([]*fastly.Kafka, error) {
+			return nil, nil
+		}
+	}
+	if m.ListKinesisFn == nil {
+		m.ListKinesisFn = func(context.Context, *fastly.ListKinesisInput) ([]*fastly.Kinesis, error) {
+			return nil, nil
+		}
+	}
+	if m.ListLogglyFn == nil {
+		m.ListLogglyFn = func(context.Context, *fastly.ListLogglyInput) ([]*fastly.Loggly, error) {
+			return nil, nil
+		}
+	}
+	if m.ListLogshuttlesFn == nil {
+		m.ListLogshuttlesFn = func(context.Context, *fastly.ListLogshuttlesInput) ([]*fastly.Logshuttle, error) {
+			return nil, nil
+		}
+	}
+	if m.ListNewRelicFn == nil {
+		m.ListNewRelicFn = func(context.Context, *fastly.ListNewRelicInput) ([]*fastly.NewRelic, error) {
+			return nil, nil
+		}
+	}
+	if m.ListNewRelicOTLPFn == nil {
+		m.ListNewRelicOTLPFn = func(context.Context, *fastly.ListNewRelicOTLPInput) ([]*fastly.NewRelicOTLP, error) {
+			return nil, nil
+		}
+	}
+	if m.ListOpenstacksFn == nil {
+		m.ListOpenstacksFn = func(context.Context, *fastly.ListOpenstackInput) ([]*fastly.Openstack, error) {
+			return nil, nil
+		}
+	}
+	if m.ListPapertrailsFn == nil {
+		m.ListPapertrailsFn = func(context.Context, *fastly.ListPapertrailsInput) ([]*fastly.Papertrail, error) {
+			return nil, nil
+		}
+	}
+	if m.ListS3sFn == nil {
+		m.ListS3sFn = func(context.Context, *fastly.ListS3sInput) ([]*fastly.S3, error) {
+			return nil, nil
+		}
+	}
+	if m.ListScalyrsFn == nil {
+		m.ListScalyrsFn = func(context.Context, *fastly.ListScalyrsInput) ([]*fastly.Scalyr, error) {
+			return nil, nil
+		}
+	}
+	if m.ListSFTPsFn == nil {
+		m.ListSFTPsFn = func(context.Context, *fastly.ListSFTPsInput) ([]*fastly.SFTP, error) {
+			return nil, nil
+		}
+	}
+	if m.ListSplunksFn == nil {
+		m.ListSplunksFn = func(context.Context, *fastly.ListSplunksInput) ([]*fastly.Splunk, error) {
+			return nil, nil
+		}
+	}
+	if m.ListSumologicsFn == nil {
+		m.ListSumologicsFn = func(context.Context, *fastly.ListSumologicsInput) ([]*fastly.Sumologic, error) {
+			return nil, nil
+		}
+	}
+	if m.ListSyslogsFn == nil {
+		m.ListSyslogsFn = func(context.Context, *fastly.ListSyslogsInput) ([]*fastly.Syslog, error) {
+			return nil, nil
+		}
+	}
+	return m
+}
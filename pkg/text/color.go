@@ -19,6 +19,17 @@ var BoldYellow = color.New(color.Bold, color.FgYellow).SprintFunc()
 // BoldGreen is a Sprint-class function that makes the arguments bold and green.
 var BoldGreen = color.New(color.Bold, color.FgGreen).SprintFunc()
 
+// ColorCycle is a list of Sprint-class functions used to tell apart related
+// output (e.g. logs from different services), picked in turn.
+var ColorCycle = []ColorFn{
+	color.New(color.FgCyan).SprintFunc(),
+	color.New(color.FgGreen).SprintFunc(),
+	color.New(color.FgMagenta).SprintFunc(),
+	color.New(color.FgYellow).SprintFunc(),
+	color.New(color.FgBlue).SprintFunc(),
+	color.New(color.FgRed).SprintFunc(),
+}
+
 // Reset is a Sprint-class function that resets the color for the arguments.
 var Reset = color.New(color.Reset).SprintFunc()
 
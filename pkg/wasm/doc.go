@@ -0,0 +1,2 @@
+// Package wasm contains a parser for the structure of WebAssembly binaries.
+package wasm
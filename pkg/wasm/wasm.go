@@ -0,0 +1,624 @@
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Magic is the first four bytes of every WebAssembly binary.
+var Magic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+// Kind describes whether a binary is a core module or a component.
+type Kind int
+
+const (
+	// KindModule is a core WebAssembly module (e.g. targeting WASI preview 1).
+	KindModule Kind = iota
+	// KindComponent is a component (e.g. targeting WASI preview 2).
+	KindComponent
+)
+
+func (k Kind) String() string {
+	if k == KindComponent {
+		return "component"
+	}
+	return "core module"
+}
+
+// Section IDs of a component that are decoded.
+const (
+	componentSectionCoreModule byte = 1
+	componentSectionImport     byte = 10
+	componentSectionExport     byte = 11
+)
+
+// Section IDs of a core module.
+const (
+	SectionCustom    byte = 0
+	SectionType      byte = 1
+	SectionImport    byte = 2
+	SectionFunction  byte = 3
+	SectionTable     byte = 4
+	SectionMemory    byte = 5
+	SectionGlobal    byte = 6
+	SectionExport    byte = 7
+	SectionStart     byte = 8
+	SectionElement   byte = 9
+	SectionCode      byte = 10
+	SectionData      byte = 11
+	SectionDataCount byte = 12
+	SectionTag       byte = 13
+)
+
+var sectionNames = map[byte]string{
+	SectionCustom:    "custom",
+	SectionType:      "type",
+	SectionImport:    "import",
+	SectionFunction:  "function",
+	SectionTable:     "table",
+	SectionMemory:    "memory",
+	SectionGlobal:    "global",
+	SectionExport:    "export",
+	SectionStart:     "start",
+	SectionElement:   "element",
+	SectionCode:      "code",
+	SectionData:      "data",
+	SectionDataCount: "datacount",
+	SectionTag:       "tag",
+}
+
+var componentSectionNames = map[byte]string{
+	SectionCustom:              "custom",
+	componentSectionCoreModule: "core module",
+	2:                          "core instance",
+	3:                          "core type",
+	4:                          "component",
+	5:                          "instance",
+	6:                          "alias",
+	7:                          "type",
+	8:                          "canon",
+	9:                          "start",
+	componentSectionImport:     "import",
+	componentSectionExport:     "export",
+	12:                         "value",
+}
+
+// ExternalKind is the kind of an import or export.
+type ExternalKind byte
+
+// External kinds.
+const (
+	ExternalFunc   ExternalKind = 0
+	ExternalTable  ExternalKind = 1
+	ExternalMemory ExternalKind = 2
+	ExternalGlobal ExternalKind = 3
+	ExternalTag    ExternalKind = 4
+)
+
+func (k ExternalKind) String() string {
+	switch k {
+	case ExternalFunc:
+		return "func"
+	case ExternalTable:
+		return "table"
+	case ExternalMemory:
+		return "memory"
+	case ExternalGlobal:
+		return "global"
+	case ExternalTag:
+		return "tag"
+	}
+	return fmt.Sprintf("unknown (%d)", byte(k))
+}
+
+// Section is a section of the binary.
+type Section struct {
+	ID byte
+	// Name is the name of a custom section.
+	Name string
+	// Size is the size of the section content, in bytes.
+	Size int
+
+	component bool
+}
+
+// String returns a description of the section, e.g. `code` or `custom "name"`.
+func (s Section) String() string {
+	if s.ID == SectionCustom {
+		return fmt.Sprintf("custom %q", s.Name)
+	}
+	names := sectionNames
+	if s.component {
+		names = componentSectionNames
+	}
+	if name, ok := names[s.ID]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", s.ID)
+}
+
+// Import is an item imported from the host.
+type Import struct {
+	Module string
+	Name   string
+	Kind   ExternalKind
+}
+
+// Export is an item exported to the host.
+type Export struct {
+	Name  string
+	Kind  ExternalKind
+	Index uint32
+}
+
+// Function is a function defined by the module.
+type Function struct {
+	Index uint32
+	// Name is read from the "name" custom section, if there is one.
+	Name string
+	// Size is the size of the function body, in bytes.
+	Size int
+}
+
+// Binary is the structure of a WebAssembly binary.
+type Binary struct {
+	Kind    Kind
+	Version uint16
+	// Size is the size of the binary, in bytes.
+	Size     int
+	Sections []Section
+	// Imports and Exports are only populated for core modules.
+	Imports []Import
+	Exports []Export
+	// ComponentImports and ComponentExports are the names of the items
+	// imported and exported by a component (e.g. "wasi:http/types@0.2.0").
+	ComponentImports []string
+	ComponentExports []string
+	// Warnings describe the parts of a component that couldn't be decoded.
+	Warnings []string
+	// Functions are the functions defined by a core module, or by the core
+	// modules embedded in a component.
+	Functions []Function
+}
+
+// Parse parses the structure of a WebAssembly binary.
+//
+// Only the sections describing the interface of the binary (imports and
+// exports) and the size of its functions are decoded.
+func Parse(data []byte) (*Binary, error) {
+	if len(data) < 8 || !bytes.Equal(data[:4], Magic) {
+		return nil, errors.New("not a WebAssembly binary (unexpected magic number)")
+	}
+	b := &Binary{
+		Version: uint16(data[4]) | uint16(data[5])<<8,
+		Size:    len(data),
+	}
+	// The version field is split into a version and a layer. Core modules are
+	// layer 0 and components are layer 1.
+	switch layer := uint16(data[6]) | uint16(data[7])<<8; layer {
+	case 0:
+		if b.Version != 1 {
+			return nil, fmt.Errorf("unsupported WebAssembly version %d", b.Version)
+		}
+	case 1:
+		b.Kind = KindComponent
+	default:
+		return nil, fmt.Errorf("unsupported WebAssembly layer %d", layer)
+	}
+
+	r := &reader{data: data, pos: 8}
+	var (
+		importedFuncs uint32
+		names         map[uint32]string
+	)
+	for !r.done() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, fmt.Errorf("error reading size of section %d: %w", id, err)
+		}
+		content, err := r.bytes(int(size))
+		if err != nil {
+			return nil, fmt.Errorf("error reading section %d: %w", id, err)
+		}
+		s := Section{ID: id, Size: int(size), component: b.Kind == KindComponent}
+		sr := &reader{data: content}
+		if id == SectionCustom {
+			if s.Name, err = sr.name(); err != nil {
+				return nil, fmt.Errorf("error reading custom section name: %w", err)
+			}
+		}
+		b.Sections = append(b.Sections, s)
+		if b.Kind == KindComponent {
+			if err := b.readComponentSection(s, content); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch {
+		case id == SectionImport:
+			if b.Imports, err = readImports(sr); err != nil {
+				return nil, fmt.Errorf("error reading import section: %w", err)
+			}
+			for _, imp := range b.Imports {
+				if imp.Kind == ExternalFunc {
+					importedFuncs++
+				}
+			}
+		case id == SectionExport:
+			if b.Exports, err = readExports(sr); err != nil {
+				return nil, fmt.Errorf("error reading export section: %w", err)
+			}
+		case id == SectionCode:
+			if b.Functions, err = readCode(sr, importedFuncs); err != nil {
+				return nil, fmt.Errorf("error reading code section: %w", err)
+			}
+		case id == SectionCustom && s.Name == "name":
+			// The name section is only for debugging, so a malformed section
+			// isn't an error.
+			names, _ = readFunctionNames(sr)
+		}
+	}
+
+	for i, f := range b.Functions {
+		b.Functions[i].Name = names[f.Index]
+	}
+	return b, nil
+}
+
+// ImportsFrom returns the names of the items imported from a module.
+func (b *Binary) ImportsFrom(module string) []string {
+	var names []string
+	for _, imp := range b.Imports {
+		if imp.Module == module {
+			names = append(names, imp.Name)
+		}
+	}
+	return names
+}
+
+// Exported reports whether the binary exports an item with the name and kind.
+func (b *Binary) Exported(name string, kind ExternalKind) bool {
+	for _, e := range b.Exports {
+		if e.Name == name && e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// readComponentSection decodes the imports, exports and embedded core
+// modules of a component.
+func (b *Binary) readComponentSection(s Section, content []byte) error {
+	var err error
+	switch s.ID {
+	case componentSectionCoreModule:
+		m, err := Parse(content)
+		if err != nil {
+			return fmt.Errorf("error reading embedded core module: %w", err)
+		}
+		b.Functions = append(b.Functions, m.Functions...)
+	case componentSectionImport:
+		// The names are decoded on a best effort basis, as the encoding of the
+		// component types that follow them is still evolving.
+		b.ComponentImports, err = readComponentNames(&reader{data: content}, true)
+		if err != nil {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("unable to decode all the imports: %s", err))
+		}
+	case componentSectionExport:
+		b.ComponentExports, err = readComponentNames(&reader{data: content}, false)
+		if err != nil {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("unable to decode all the exports: %s", err))
+		}
+	}
+	return nil
+}
+
+// readComponentNames reads the names in a component import or export section.
+func readComponentNames(r *reader, imports bool) ([]string, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, min(n, 1024))
+	for range n {
+		prefix, err := r.byte()
+		if err != nil {
+			return names, err
+		}
+		if prefix != 0x00 && prefix != 0x01 {
+			return names, fmt.Errorf("unknown name encoding %#x", prefix)
+		}
+		name, err := r.name()
+		if err != nil {
+			return names, err
+		}
+		names = append(names, name)
+		if imports {
+			err = r.skipComponentExternDesc()
+		} else {
+			err = r.skipComponentExport()
+		}
+		if err != nil {
+			return names, err
+		}
+	}
+	return names, nil
+}
+
+func readImports(r *reader) ([]Import, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]Import, 0, min(n, 1024))
+	for range n {
+		var imp Import
+		if imp.Module, err = r.name(); err != nil {
+			return nil, err
+		}
+		if imp.Name, err = r.name(); err != nil {
+			return nil, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		imp.Kind = ExternalKind(kind)
+		if err := r.skipImportDesc(imp.Kind); err != nil {
+			return nil, fmt.Errorf("import %s.%s: %w", imp.Module, imp.Name, err)
+		}
+		imports = append(imports, imp)
+	}
+	return imports, nil
+}
+
+func readExports(r *reader) ([]Export, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	exports := make([]Export, 0, min(n, 1024))
+	for range n {
+		var e Export
+		if e.Name, err = r.name(); err != nil {
+			return nil, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		e.Kind = ExternalKind(kind)
+		if e.Index, err = r.u32(); err != nil {
+			return nil, err
+		}
+		exports = append(exports, e)
+	}
+	return exports, nil
+}
+
+// readCode reads the size of each function body. Defined functions are
+// indexed after the imported functions.
+func readCode(r *reader, importedFuncs uint32) ([]Function, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	funcs := make([]Function, 0, min(n, 1<<16))
+	for i := range n {
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.bytes(int(size)); err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, Function{Index: importedFuncs + i, Size: int(size)})
+	}
+	return funcs, nil
+}
+
+// readFunctionNames reads the function names subsection of the name section.
+func readFunctionNames(r *reader) (map[uint32]string, error) {
+	for !r.done() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.bytes(int(size))
+		if err != nil {
+			return nil, err
+		}
+		if id != 1 {
+			continue
+		}
+		sr := &reader{data: content}
+		n, err := sr.u32()
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[uint32]string, min(n, 1<<16))
+		for range n {
+			idx, err := sr.u32()
+			if err != nil {
+				return names, err
+			}
+			name, err := sr.name()
+			if err != nil {
+				return names, err
+			}
+			names[idx] = name
+		}
+		return names, nil
+	}
+	return nil, nil
+}
+
+// reader decodes the primitive values of the binary format.
+type reader struct {
+	data []byte
+	pos  int
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of data")
+
+func (r *reader) done() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.done() {
+		return 0, errUnexpectedEnd
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data)-r.pos {
+		return nil, errUnexpectedEnd
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// uleb reads an unsigned LEB128 integer of at most bits bits.
+func (r *reader) uleb(bits uint) (uint64, error) {
+	var (
+		result uint64
+		shift  uint
+	)
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result, nil
+		}
+		shift += 7
+		if shift >= (bits+6)/7*7 {
+			return 0, errors.New("integer representation too long")
+		}
+	}
+}
+
+func (r *reader) u32() (uint32, error) {
+	v, err := r.uleb(32)
+	return uint32(v), err // #nosec G115
+}
+
+func (r *reader) name() (string, error) {
+	n, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.bytes(int(n))
+	return string(b), err
+}
+
+// limits skips the limits of a table or memory.
+func (r *reader) limits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if _, err := r.uleb(64); err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		if _, err := r.uleb(64); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipComponentExternDesc skips the type of a component import or export.
+func (r *reader) skipComponentExternDesc() error {
+	kind, err := r.byte()
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case 0x00: // core module
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+	case 0x02, 0x03: // value or type bound
+		bound, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if kind == 0x03 && bound == 0x01 {
+			return nil // a resource type has no index
+		}
+	case 0x01, 0x04, 0x05: // func, component or instance
+	default:
+		return fmt.Errorf("unknown extern kind %#x", kind)
+	}
+	_, err = r.uleb(33)
+	return err
+}
+
+// skipComponentExport skips the item and optional type of a component export.
+func (r *reader) skipComponentExport() error {
+	sort, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if sort == 0x00 { // core sort
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+	}
+	if _, err := r.u32(); err != nil {
+		return err
+	}
+	hasType, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if hasType == 0x01 {
+		return r.skipComponentExternDesc()
+	}
+	return nil
+}
+
+// skipImportDesc skips the type of an import.
+func (r *reader) skipImportDesc(kind ExternalKind) error {
+	switch kind {
+	case ExternalFunc:
+		_, err := r.u32()
+		return err
+	case ExternalTable:
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		return r.limits()
+	case ExternalMemory:
+		return r.limits()
+	case ExternalGlobal:
+		_, err := r.bytes(2) // value type and mutability
+		return err
+	case ExternalTag:
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		_, err := r.u32()
+		return err
+	}
+	return fmt.Errorf("unknown import kind %d", kind)
+}
@@ -0,0 +1,140 @@
+package wasm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/wasm"
+)
+
+// module builds a binary from sections (each given as an ID and content).
+func module(version []byte, sections ...[]byte) []byte {
+	b := append([]byte{}, wasm.Magic...)
+	b = append(b, version...)
+	for _, s := range sections {
+		b = append(b, s[0], byte(len(s)-1))
+		b = append(b, s[1:]...)
+	}
+	return b
+}
+
+func name(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func section(id byte, parts ...[]byte) []byte {
+	b := []byte{id}
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func TestParse(t *testing.T) {
+	data := module([]byte{1, 0, 0, 0},
+		section(wasm.SectionType, []byte{1, 0x60, 0, 0}),
+		section(wasm.SectionImport, []byte{3},
+			name("fastly_http_req"), name("body_downstream_get"), []byte{0, 0},
+			name("wasi_snapshot_preview1"), name("fd_write"), []byte{0, 0},
+			name("env"), name("memory"), []byte{2, 1, 1, 2},
+		),
+		section(wasm.SectionFunction, []byte{2, 0, 0}),
+		section(wasm.SectionExport, []byte{2},
+			name("_start"), []byte{0, 2},
+			name("memory"), []byte{2, 0},
+		),
+		section(wasm.SectionCode, []byte{2},
+			[]byte{2, 0, 0x0b},
+			[]byte{5, 0, 0x01, 0x01, 0x01, 0x0b},
+		),
+		section(wasm.SectionCustom, name("name"), []byte{1, 12, 2, 2}, name("main"), []byte{3}, name("foo")),
+	)
+
+	b, err := wasm.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Kind != wasm.KindModule || b.Version != 1 || b.Size != len(data) {
+		t.Errorf("unexpected header: %s version %d, %d bytes", b.Kind, b.Version, b.Size)
+	}
+
+	var sections []string
+	for _, s := range b.Sections {
+		sections = append(sections, s.String())
+	}
+	if got, want := strings.Join(sections, ", "), `type, import, function, export, code, custom "name"`; got != want {
+		t.Errorf("want sections %s, got %s", want, got)
+	}
+
+	if len(b.Imports) != 3 || b.Imports[2].Kind != wasm.ExternalMemory {
+		t.Fatalf("unexpected imports: %+v", b.Imports)
+	}
+	if got := b.ImportsFrom("wasi_snapshot_preview1"); len(got) != 1 || got[0] != "fd_write" {
+		t.Errorf("unexpected WASI imports: %q", got)
+	}
+	if !b.Exported("_start", wasm.ExternalFunc) || !b.Exported("memory", wasm.ExternalMemory) || b.Exported("memory", wasm.ExternalFunc) {
+		t.Errorf("unexpected exports: %+v", b.Exports)
+	}
+
+	want := []wasm.Function{{Index: 2, Name: "main", Size: 2}, {Index: 3, Name: "foo", Size: 5}}
+	if len(b.Functions) != len(want) {
+		t.Fatalf("want functions %+v, got %+v", want, b.Functions)
+	}
+	for i := range want {
+		if b.Functions[i] != want[i] {
+			t.Errorf("want function %+v, got %+v", want[i], b.Functions[i])
+		}
+	}
+}
+
+func TestParse_Component(t *testing.T) {
+	core := module([]byte{1, 0, 0, 0}, section(wasm.SectionCode, []byte{1}, []byte{2, 0, 0x0b}))
+	b, err := wasm.Parse(module([]byte{0x0d, 0, 1, 0},
+		append([]byte{1}, core...),
+		section(10, []byte{1, 0}, name("wasi:http/types@0.2.0"), []byte{0x05, 0}),
+		section(11, []byte{1, 0}, name("wasi:http/incoming-handler@0.2.0"), []byte{0x05, 1, 0}),
+		section(wasm.SectionCustom, name("producers")),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Kind != wasm.KindComponent || len(b.Sections) != 4 || b.Sections[0].String() != "core module" {
+		t.Errorf("unexpected component: %+v", b)
+	}
+	if len(b.ComponentImports) != 1 || b.ComponentImports[0] != "wasi:http/types@0.2.0" {
+		t.Errorf("unexpected imports: %q", b.ComponentImports)
+	}
+	if len(b.ComponentExports) != 1 || b.ComponentExports[0] != "wasi:http/incoming-handler@0.2.0" {
+		t.Errorf("unexpected exports: %q", b.ComponentExports)
+	}
+	if len(b.Functions) != 1 || b.Functions[0].Size != 2 {
+		t.Errorf("want the functions of the embedded core module, got %+v", b.Functions)
+	}
+}
+
+func TestParse_ComponentUndecodedNames(t *testing.T) {
+	b, err := wasm.Parse(module([]byte{0x0d, 0, 1, 0},
+		section(10, []byte{2, 0}, name("wasi:http/types@0.2.0"), []byte{0x05, 0}, []byte{0x02}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.ComponentImports) != 1 || b.ComponentImports[0] != "wasi:http/types@0.2.0" {
+		t.Errorf("want only the decoded imports, got %q", b.ComponentImports)
+	}
+	if len(b.Warnings) != 1 || !strings.Contains(b.Warnings[0], "unknown name encoding") {
+		t.Errorf("unexpected warnings: %q", b.Warnings)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"not wasm":          []byte("#!/bin/sh\necho hello\n"),
+		"truncated section": append(module([]byte{1, 0, 0, 0}), wasm.SectionType, 10, 1),
+		"unknown version":   module([]byte{2, 0, 0, 0}),
+	} {
+		if _, err := wasm.Parse(data); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}